@@ -38,6 +38,14 @@ const (
 	DDInfraInitOnly                         = "initOnly"
 	DDInfraDialErrorLimit                   = "dialErrorLimit"
 	DDInfraPerDialTimeoutSeconds            = "perDialTimeoutSeconds"
+	DDInfraHostFactsCheck                   = "hostFactsCheck" // hostFactsCheck is expected to be empty (disabled), `warn` or `fail`
+	DDInfraBastion                          = "bastion"        // bastion is expected in the format: [user@]host[:port]
+	DDInfraBastionPrivateKeyPath            = "bastionPrivateKeyPath"
+	DDInfraWindowsTransport                 = "windowsTransport" // windowsTransport is expected to be `ssh` (default) or `winrm-https`
+	DDInfraWinRMClientPath                  = "winrmClientPath"  // winrmClientPath defaults to the `winrm` binary in the PATH
+	DDInfraWinRMInsecure                    = "winrmInsecure"
+	DDInfraDiagnostics                      = "diagnostics" // diagnostics is expected to be empty (disabled), `failure` or `always`
+	DDInfraDiagnosticsDir                   = "diagnosticsDir"
 
 	// Agent Namespace
 	DDAgentDeployParamName               = "deploy"
@@ -111,6 +119,14 @@ type Env interface {
 	InfraEnvironmentNames() []string
 	InfraOSDescriptor() string
 	InfraOSImageID() string
+	InfraHostFactsCheck() string
+	InfraBastion() string
+	InfraBastionPrivateKeyPath() string
+	InfraWindowsTransport() string
+	InfraWinRMClientPath() string
+	InfraWinRMInsecure() bool
+	InfraDiagnostics() string
+	InfraDiagnosticsDir() string
 	KubernetesVersion() string
 	KubeNodeURL() string
 	KindVersion() string
@@ -266,6 +282,44 @@ func (e *CommonEnvironment) InfraPerDialTimeoutSeconds() int {
 	return e.GetIntWithDefault(e.InfraConfig, DDInfraPerDialTimeoutSeconds, 0)
 }
 
+func (e *CommonEnvironment) InfraHostFactsCheck() string {
+	return e.GetStringWithDefault(e.InfraConfig, DDInfraHostFactsCheck, "")
+}
+
+// InfraBastion returns the host all the remote hosts of the stack are reached through, if any
+func (e *CommonEnvironment) InfraBastion() string {
+	return e.GetStringWithDefault(e.InfraConfig, DDInfraBastion, "")
+}
+
+func (e *CommonEnvironment) InfraBastionPrivateKeyPath() string {
+	return e.GetStringWithDefault(e.InfraConfig, DDInfraBastionPrivateKeyPath, "")
+}
+
+// InfraWindowsTransport returns how commands are run on Windows hosts
+func (e *CommonEnvironment) InfraWindowsTransport() string {
+	return e.GetStringWithDefault(e.InfraConfig, DDInfraWindowsTransport, "ssh")
+}
+
+// InfraWinRMClientPath returns the path of the client running WinRM commands, built from components/command/winrm/cmd/winrm
+func (e *CommonEnvironment) InfraWinRMClientPath() string {
+	return e.GetStringWithDefault(e.InfraConfig, DDInfraWinRMClientPath, "winrm")
+}
+
+// InfraWinRMInsecure returns true if the certificate of the WinRM HTTPS listener is not verified
+func (e *CommonEnvironment) InfraWinRMInsecure() bool {
+	return e.GetBoolWithDefault(e.InfraConfig, DDInfraWinRMInsecure, false)
+}
+
+// InfraDiagnostics returns when diagnostics are collected from hosts before they are destroyed, see the diagnostics package
+func (e *CommonEnvironment) InfraDiagnostics() string {
+	return e.GetStringWithDefault(e.InfraConfig, DDInfraDiagnostics, "")
+}
+
+// InfraDiagnosticsDir returns the local directory diagnostics are written to, in a sub-directory named after the stack
+func (e *CommonEnvironment) InfraDiagnosticsDir() string {
+	return e.GetStringWithDefault(e.InfraConfig, DDInfraDiagnosticsDir, "diagnostics")
+}
+
 func EnvVariableResourceTags() map[string]string {
 	tags := map[string]string{}
 	lookupVars := []string{"TEAM", "PIPELINE_ID", "CI_PIPELINE_ID"}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/common/namer"
+	"github.com/pulumi/pulumi-tls/sdk/v4/go/tls"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// certificateValidityHours is long enough for any test stack
+const certificateValidityHours = 24 * 365
+
+// CertificateAuthority is a self-signed CA used to sign leaf certificates for test services.
+// Install CertPEM with os.TrustStore().EnsureCA on the hosts that should trust them.
+type CertificateAuthority struct {
+	e       config.Env
+	namer   namer.Namer
+	key     *tls.PrivateKey
+	CertPEM pulumi.StringOutput
+}
+
+// Certificate is a leaf certificate signed by a CertificateAuthority
+type Certificate struct {
+	CertPEM       pulumi.StringOutput
+	PrivateKeyPEM pulumi.StringOutput
+}
+
+func newPrivateKey(e config.Env, name string, opts ...pulumi.ResourceOption) (*tls.PrivateKey, error) {
+	return tls.NewPrivateKey(e.Ctx(), name, &tls.PrivateKeyArgs{
+		Algorithm:  pulumi.String("ECDSA"),
+		EcdsaCurve: pulumi.StringPtr("P256"),
+	}, MergeOptions(opts, e.WithProviders(config.ProviderTLS))...)
+}
+
+func NewCertificateAuthority(e config.Env, name string, opts ...pulumi.ResourceOption) (*CertificateAuthority, error) {
+	ca := &CertificateAuthority{
+		e:     e,
+		namer: namer.NewNamer(e.Ctx(), "tls-"+name),
+	}
+
+	var err error
+	ca.key, err = newPrivateKey(e, ca.namer.ResourceName("ca-key"), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := tls.NewSelfSignedCert(e.Ctx(), ca.namer.ResourceName("ca-cert"), &tls.SelfSignedCertArgs{
+		PrivateKeyPem: ca.key.PrivateKeyPem,
+		Subject: &tls.SelfSignedCertSubjectArgs{
+			CommonName:   pulumi.StringPtr(name),
+			Organization: pulumi.StringPtr("Datadog test-infra-definitions"),
+		},
+		IsCaCertificate:     pulumi.BoolPtr(true),
+		ValidityPeriodHours: pulumi.Int(certificateValidityHours),
+		AllowedUses:         pulumi.ToStringArray([]string{"cert_signing", "crl_signing", "digital_signature"}),
+	}, MergeOptions(opts, e.WithProviders(config.ProviderTLS))...)
+	if err != nil {
+		return nil, err
+	}
+	ca.CertPEM = cert.CertPem
+
+	return ca, nil
+}
+
+// NewCertificate creates a server and client certificate for the given DNS names and IP addresses, either can be nil
+func (ca *CertificateAuthority) NewCertificate(name string, dnsNames pulumi.StringArrayInput, ipAddresses pulumi.StringArrayInput, opts ...pulumi.ResourceOption) (*Certificate, error) {
+	key, err := newPrivateKey(ca.e, ca.namer.ResourceName("key", name), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := tls.NewCertRequest(ca.e.Ctx(), ca.namer.ResourceName("cert-request", name), &tls.CertRequestArgs{
+		PrivateKeyPem: key.PrivateKeyPem,
+		Subject: &tls.CertRequestSubjectArgs{
+			CommonName: pulumi.StringPtr(name),
+		},
+		DnsNames:    dnsNames,
+		IpAddresses: ipAddresses,
+	}, MergeOptions(opts, ca.e.WithProviders(config.ProviderTLS))...)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := tls.NewLocallySignedCert(ca.e.Ctx(), ca.namer.ResourceName("cert", name), &tls.LocallySignedCertArgs{
+		CertRequestPem:      request.CertRequestPem,
+		CaPrivateKeyPem:     ca.key.PrivateKeyPem,
+		CaCertPem:           ca.CertPEM,
+		ValidityPeriodHours: pulumi.Int(certificateValidityHours),
+		AllowedUses:         pulumi.ToStringArray([]string{"digital_signature", "key_encipherment", "server_auth", "client_auth"}),
+	}, MergeOptions(opts, ca.e.WithProviders(config.ProviderTLS))...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Certificate{
+		CertPEM:       cert.CertPem,
+		PrivateKeyPEM: key.PrivateKeyPem,
+	}, nil
+}
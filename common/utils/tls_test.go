@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+)
+
+type tlsEnv struct {
+	config.Env
+	ctx *pulumi.Context
+}
+
+func (e tlsEnv) Ctx() *pulumi.Context {
+	return e.ctx
+}
+
+func (tlsEnv) WithProviders(...config.ProviderID) pulumi.ResourceOption {
+	return pulumi.Providers()
+}
+
+// tlsMocks records the inputs of the created resources by name, certificates are their resource name
+type tlsMocks struct {
+	lock      sync.Mutex
+	resources map[string]resource.PropertyMap
+}
+
+func (m *tlsMocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.resources[args.Name] = args.Inputs
+
+	outputs := args.Inputs.Copy()
+	switch args.TypeToken {
+	case "tls:index/privateKey:PrivateKey":
+		outputs["privateKeyPem"] = resource.NewStringProperty(args.Name + "-pem")
+	case "tls:index/certRequest:CertRequest":
+		outputs["certRequestPem"] = resource.NewStringProperty(args.Name + "-pem")
+	case "tls:index/selfSignedCert:SelfSignedCert", "tls:index/locallySignedCert:LocallySignedCert":
+		outputs["certPem"] = resource.NewStringProperty(args.Name + "-pem")
+	}
+	return args.Name + "-id", outputs, nil
+}
+
+func (m *tlsMocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	return args.Args, nil
+}
+
+// stringValue returns the string of the property, private keys are secrets
+func stringValue(value resource.PropertyValue) string {
+	if value.IsSecret() {
+		value = value.SecretValue().Element
+	}
+	return value.StringValue()
+}
+
+func TestCertificateAuthority(t *testing.T) {
+	mocks := &tlsMocks{resources: map[string]resource.PropertyMap{}}
+	certPEMs := make(chan string, 2)
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		ca, err := NewCertificateAuthority(tlsEnv{ctx: ctx}, "proxy")
+		require.NoError(t, err)
+		cert, err := ca.NewCertificate("squid", pulumi.ToStringArray([]string{"proxy.local"}), pulumi.ToStringArray([]string{"10.0.0.1"}))
+		require.NoError(t, err)
+
+		pulumi.All(ca.CertPEM, cert.CertPEM).ApplyT(func(args []interface{}) error {
+			certPEMs <- args[0].(string)
+			certPEMs <- args[1].(string)
+			return nil
+		})
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	require.NoError(t, err)
+
+	assert.Equal(t, "tls-proxy-ca-cert-pem", <-certPEMs)
+	assert.Equal(t, "tls-proxy-cert-squid-pem", <-certPEMs)
+
+	caCert := mocks.resources["tls-proxy-ca-cert"]
+	require.NotNil(t, caCert)
+	assert.True(t, caCert["isCaCertificate"].BoolValue())
+	assert.Equal(t, "tls-proxy-ca-key-pem", stringValue(caCert["privateKeyPem"]))
+	assert.Contains(t, caCert["allowedUses"].ArrayValue(), resource.NewStringProperty("cert_signing"))
+
+	request := mocks.resources["tls-proxy-cert-request-squid"]
+	require.NotNil(t, request)
+	assert.Equal(t, "tls-proxy-key-squid-pem", stringValue(request["privateKeyPem"]))
+	assert.Equal(t, []resource.PropertyValue{resource.NewStringProperty("proxy.local")}, request["dnsNames"].ArrayValue())
+	assert.Equal(t, []resource.PropertyValue{resource.NewStringProperty("10.0.0.1")}, request["ipAddresses"].ArrayValue())
+
+	// The leaf certificate is signed by the CA key
+	cert := mocks.resources["tls-proxy-cert-squid"]
+	require.NotNil(t, cert)
+	assert.Equal(t, "tls-proxy-cert-request-squid-pem", stringValue(cert["certRequestPem"]))
+	assert.Equal(t, "tls-proxy-ca-key-pem", stringValue(cert["caPrivateKeyPem"]))
+	assert.Equal(t, "tls-proxy-ca-cert-pem", stringValue(cert["caCertPem"]))
+	assert.Contains(t, cert["allowedUses"].ArrayValue(), resource.NewStringProperty("server_auth"))
+}
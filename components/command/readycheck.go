@@ -0,0 +1,174 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alessio/shellescape"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// ReadyCheck is a condition polled on a host until it is true, see WaitFor.
+// Checks are composed with All and Any.
+type ReadyCheck struct {
+	name string
+	// unix is a shell command exiting with 0 when the condition is true, empty if not supported
+	unix string
+	// windows is a PowerShell expression evaluating to $true when the condition is true, empty if not supported
+	windows string
+}
+
+// TCPPortCheck is true when a TCP connection to address:port succeeds, address defaults to the host itself
+func TCPPortCheck(address string, port int) ReadyCheck {
+	if address == "" {
+		address = "127.0.0.1"
+	}
+	return ReadyCheck{
+		name:    fmt.Sprintf("tcp-%s-%d", address, port),
+		unix:    fmt.Sprintf("bash -c 'echo > /dev/tcp/%s/%d' 2> /dev/null", address, port),
+		windows: fmt.Sprintf("(Test-NetConnection -ComputerName '%s' -Port %d -InformationLevel Quiet -WarningAction SilentlyContinue)", address, port),
+	}
+}
+
+// FileCheck is true when the file or directory exists
+func FileCheck(path string) ReadyCheck {
+	return ReadyCheck{
+		name:    "file-" + path,
+		unix:    "test -e " + shellescape.Quote(path),
+		windows: fmt.Sprintf("(Test-Path -Path '%s')", path),
+	}
+}
+
+// SystemdUnitCheck is true when the systemd unit is active, it is only supported on Linux
+func SystemdUnitCheck(unit string) ReadyCheck {
+	return ReadyCheck{
+		name: "systemd-" + unit,
+		unix: "systemctl is-active --quiet " + shellescape.Quote(unit),
+	}
+}
+
+// WindowsBootCompleteCheck is true once the event log service logged its start (event 6005) for the current boot
+func WindowsBootCompleteCheck() ReadyCheck {
+	return ReadyCheck{
+		name:    "windows-boot-complete",
+		windows: `(@(Get-WinEvent -FilterHashtable @{LogName = 'System'; Id = 6005; StartTime = (Get-CimInstance Win32_OperatingSystem).LastBootUpTime} -ErrorAction SilentlyContinue).Count -gt 0)`,
+	}
+}
+
+// PackageManagerIdleCheck is true when no package manager is running, for instance unattended upgrades at first boot on Ubuntu.
+// On Windows it checks that no MSI installation is in progress.
+func PackageManagerIdleCheck() ReadyCheck {
+	return ReadyCheck{
+		name: "package-manager-idle",
+		unix: `! pgrep -x 'apt|apt-get|dpkg|unattended-upgr|yum|dnf|zypper|apk|pacman' > /dev/null && ! (command -v fuser > /dev/null && fuser /var/lib/dpkg/lock-frontend /var/lib/dpkg/lock /var/lib/apt/lists/lock > /dev/null 2>&1)`,
+		// Windows Installer holds this mutex while an installation is running
+		windows: `(-not [System.Threading.Mutex]::TryOpenExisting('Global\_MSIExecute', [ref]$null))`,
+	}
+}
+
+// All is true when all the checks are true
+func All(checks ...ReadyCheck) ReadyCheck {
+	return combineChecks("and", "&&", "-and", checks)
+}
+
+// Any is true when at least one of the checks is true
+func Any(checks ...ReadyCheck) ReadyCheck {
+	return combineChecks("or", "||", "-or", checks)
+}
+
+func combineChecks(name, unixOperator, windowsOperator string, checks []ReadyCheck) ReadyCheck {
+	names := make([]string, 0, len(checks))
+	unix := make([]string, 0, len(checks))
+	windows := make([]string, 0, len(checks))
+	for _, check := range checks {
+		names = append(names, check.name)
+		unix = append(unix, "("+check.unix+")")
+		windows = append(windows, "("+check.windows+")")
+	}
+
+	combined := ReadyCheck{name: strings.Join(names, "-"+name+"-")}
+	// The combination is only supported on an OS if all the checks are
+	if !containsEmpty(checks, func(c ReadyCheck) string { return c.unix }) {
+		combined.unix = strings.Join(unix, " "+unixOperator+" ")
+	}
+	if !containsEmpty(checks, func(c ReadyCheck) string { return c.windows }) {
+		combined.windows = strings.Join(windows, " "+windowsOperator+" ")
+	}
+	return combined
+}
+
+func containsEmpty(checks []ReadyCheck, condition func(ReadyCheck) string) bool {
+	for _, check := range checks {
+		if condition(check) == "" {
+			return true
+		}
+	}
+	return len(checks) == 0
+}
+
+// WaitFor returns a ReadyFunc polling the check until it is true, it fails after timeout
+func WaitFor(check ReadyCheck, timeout time.Duration) ReadyFunc {
+	return func(runner Runner) (Command, error) {
+		seconds := int(timeout.Seconds())
+
+		if _, isWindows := runner.OsCommand().(windowsOSCommand); isWindows {
+			if check.windows == "" {
+				return nil, fmt.Errorf("ready check %s is not supported on Windows", check.name)
+			}
+			return runner.Command("wait-"+check.name, &Args{
+				Create: pulumi.String(fmt.Sprintf(`$deadline = (Get-Date).AddSeconds(%[1]d); while (-not %[2]s) { if ((Get-Date) -gt $deadline) { Write-Error "timed out after %[1]ds waiting for %[3]s"; exit 1 }; Start-Sleep -Seconds 2 }`,
+					seconds, check.windows, check.name)),
+			})
+		}
+
+		if check.unix == "" {
+			return nil, fmt.Errorf("ready check %s is not supported on this OS", check.name)
+		}
+		script := fmt.Sprintf(`deadline=$(($(date +%%s) + %[1]d)); until %[2]s; do if [ "$(date +%%s)" -ge "$deadline" ]; then echo "timed out after %[1]ds waiting for %[3]s" >&2; exit 1; fi; sleep 2; done`,
+			seconds, check.unix, check.name)
+		return runner.Command("wait-"+check.name, &Args{
+			// Some checks, like the package manager locks, need root
+			Create: pulumi.String("sh -c " + shellescape.Quote(script)),
+			Sudo:   true,
+		})
+	}
+}
+
+// ChainReadyFuncs returns a ReadyFunc running the ready functions one after the other, nil functions are skipped
+func ChainReadyFuncs(readyFuncs ...ReadyFunc) ReadyFunc {
+	return func(runner Runner) (Command, error) {
+		var last Command
+		for _, readyFunc := range readyFuncs {
+			if readyFunc == nil {
+				continue
+			}
+
+			r := runner
+			if last != nil {
+				r = &dependentRunner{Runner: runner, dependsOn: last}
+			}
+			cmd, err := readyFunc(r)
+			if err != nil {
+				return nil, err
+			}
+			last = cmd
+		}
+		return last, nil
+	}
+}
+
+// dependentRunner is a Runner whose commands depend on another command
+type dependentRunner struct {
+	Runner
+	dependsOn Command
+}
+
+func (r *dependentRunner) Command(name string, args RunnerCommandArgs, opts ...pulumi.ResourceOption) (Command, error) {
+	return r.Runner.Command(name, args, append(opts, pulumi.DependsOn([]pulumi.Resource{r.dependsOn}))...)
+}
+
+// ExtendReadyFunc returns a ReadyFunc running the default ready function of a host provider, then the ones added with its options
+func ExtendReadyFunc(defaultReadyFunc ReadyFunc, readyFuncs ...ReadyFunc) ReadyFunc {
+	return ChainReadyFuncs(append([]ReadyFunc{defaultReadyFunc}, readyFuncs...)...)
+}
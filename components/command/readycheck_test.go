@@ -0,0 +1,42 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCombineChecks(t *testing.T) {
+	check := Any(All(FileCheck("/tmp/ready"), TCPPortCheck("", 22)), FileCheck("/tmp/done"))
+
+	assert.Equal(t, "file-/tmp/ready-and-tcp-127.0.0.1-22-or-file-/tmp/done", check.name)
+	assert.Equal(t, "((test -e /tmp/ready) && (bash -c 'echo > /dev/tcp/127.0.0.1/22' 2> /dev/null)) || (test -e /tmp/done)", check.unix)
+	assert.Equal(t, "(((Test-Path -Path '/tmp/ready')) -and ((Test-NetConnection -ComputerName '127.0.0.1' -Port 22 -InformationLevel Quiet -WarningAction SilentlyContinue))) -or ((Test-Path -Path '/tmp/done'))", check.windows)
+}
+
+func TestCombineChecksUnsupported(t *testing.T) {
+	check := All(SystemdUnitCheck("datadog-agent"), PackageManagerIdleCheck())
+	assert.NotEmpty(t, check.unix)
+	assert.Empty(t, check.windows)
+
+	check = Any(WindowsBootCompleteCheck(), FileCheck("C:\\ready"))
+	assert.Empty(t, check.unix)
+	assert.NotEmpty(t, check.windows)
+
+	assert.Empty(t, All().unix)
+}
+
+func TestExtendReadyFunc(t *testing.T) {
+	readyFunc := func(name string) ReadyFunc {
+		return func(r Runner) (Command, error) {
+			return r.Command(name, &Args{Create: pulumi.String("true")})
+		}
+	}
+	runner := &fakeRunner{}
+
+	_, err := ExtendReadyFunc(readyFunc("wait-for-ssh"), readyFunc("wait-for-disk"), nil)(runner)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"wait-for-ssh", "wait-for-disk"}, runner.names)
+}
@@ -0,0 +1,62 @@
+package command
+
+import (
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/DataDog/test-infra-definitions/common/namer"
+	"github.com/DataDog/test-infra-definitions/common/utils"
+)
+
+// readyRunner runs the commands of a runner under a prefix, after the commands it depends on
+type readyRunner struct {
+	Runner
+	prefix  string
+	options []pulumi.ResourceOption
+}
+
+// WithReadyFunc returns a runner sharing the connection of runner whose commands are named with the given prefix.
+// Commands of the returned runner depend on dependsOn and on readyFunc, which is run again on the returned runner.
+// It is used to wait for the host to be ready again after a disruptive operation, such as a reboot.
+// The returned command is the one of readyFunc, it is nil if readyFunc is nil.
+func WithReadyFunc(runner Runner, prefix string, readyFunc ReadyFunc, dependsOn ...pulumi.Resource) (Runner, Command, error) {
+	r := &readyRunner{
+		Runner:  runner,
+		prefix:  prefix,
+		options: []pulumi.ResourceOption{utils.PulumiDependsOn(dependsOn...)},
+	}
+	if readyFunc == nil {
+		return r, nil, nil
+	}
+
+	readyCommand, err := readyFunc(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.options = append(r.options, utils.PulumiDependsOn(readyCommand))
+
+	return r, readyCommand, nil
+}
+
+func (r *readyRunner) Namer() namer.Namer {
+	return r.Runner.Namer().WithPrefix(r.prefix)
+}
+
+func (r *readyRunner) PulumiOptions() []pulumi.ResourceOption {
+	return utils.MergeOptions(r.Runner.PulumiOptions(), r.options...)
+}
+
+func (r *readyRunner) Command(name string, args RunnerCommandArgs, opts ...pulumi.ResourceOption) (Command, error) {
+	return r.Runner.Command(r.name(name), args, utils.MergeOptions(r.options, opts...)...)
+}
+
+func (r *readyRunner) newCopyFile(name string, localPath, remotePath pulumi.StringInput, opts ...pulumi.ResourceOption) (pulumi.Resource, error) {
+	return r.Runner.newCopyFile(r.name(name), localPath, remotePath, utils.MergeOptions(r.options, opts...)...)
+}
+
+func (r *readyRunner) newCopyToRemoteFile(name string, localPath, remotePath pulumi.StringInput, opts ...pulumi.ResourceOption) (pulumi.Resource, error) {
+	return r.Runner.newCopyToRemoteFile(r.name(name), localPath, remotePath, utils.MergeOptions(r.options, opts...)...)
+}
+
+func (r *readyRunner) name(name string) string {
+	return r.prefix + "-" + name
+}
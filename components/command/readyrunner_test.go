@@ -0,0 +1,34 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner records the names of the commands instead of creating them
+type fakeRunner struct {
+	Runner
+	names []string
+}
+
+func (r *fakeRunner) Command(name string, _ RunnerCommandArgs, _ ...pulumi.ResourceOption) (Command, error) {
+	r.names = append(r.names, name)
+	return nil, nil
+}
+
+func TestWithReadyFunc(t *testing.T) {
+	runner := &fakeRunner{}
+	readyFunc := func(r Runner) (Command, error) {
+		return r.Command("wait-for-ssh", &Args{Create: pulumi.String("true")})
+	}
+
+	readyRunner, _, err := WithReadyFunc(runner, "reboot", readyFunc)
+	require.NoError(t, err)
+	_, err = readyRunner.Command("install", &Args{Create: pulumi.String("true")})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"reboot-wait-for-ssh", "reboot-install"}, runner.names)
+}
@@ -92,6 +92,13 @@ func toRemoteCommandArgs(cmdArgs RunnerCommandArgs, config RunnerConfiguration,
 // Examples: swapping `args.Delete` with `args.Create`, or adding `args.Triggers`, or editing the name
 type Transformer func(name string, args RunnerCommandArgs) (string, RunnerCommandArgs)
 
+// WithNameSuffix returns a Transformer suffixing the command name, to run the same operation more than once on a resource
+func WithNameSuffix(suffix string) Transformer {
+	return func(name string, args RunnerCommandArgs) (string, RunnerCommandArgs) {
+		return name + "-" + suffix, args
+	}
+}
+
 type RunnerConfiguration struct {
 	user       string
 	connection remote.ConnectionInput
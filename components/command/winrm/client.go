@@ -0,0 +1,187 @@
+// Package winrm is a minimal WinRM client running PowerShell scripts and copying files to Windows hosts.
+// It is used by command.WinRMRunner through the winrm command in cmd/winrm.
+//
+// Message encryption is not implemented: NTLM authentication requires HTTPS, basic authentication over plain HTTP
+// requires the `AllowUnencrypted` WinRM service setting and is only meant for tests.
+package winrm
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	DefaultHTTPPort  = 5985
+	DefaultHTTPSPort = 5986
+
+	// stdinChunkSize keeps Send messages below the maximum envelope size once base64 encoded
+	stdinChunkSize = 64 * 1024
+)
+
+// Endpoint describes how to reach the WinRM service
+type Endpoint struct {
+	Host     string
+	Port     int
+	HTTPS    bool
+	Insecure bool
+}
+
+// URL returns the WS-Management URL of the endpoint, the port defaults to the WinRM default for the scheme
+func (e Endpoint) URL() string {
+	scheme, port := "http", e.Port
+	if e.HTTPS {
+		scheme = "https"
+	}
+	if port == 0 {
+		port = DefaultHTTPPort
+		if e.HTTPS {
+			port = DefaultHTTPSPort
+		}
+	}
+	return fmt.Sprintf("%s://%s:%d/wsman", scheme, e.Host, port)
+}
+
+type Client struct {
+	url       string
+	transport *transport
+}
+
+// NewClient creates a client, user can be `DOMAIN\user` or `user@domain` with NTLM authentication
+func NewClient(endpoint Endpoint, user, password string, auth AuthMethod) (*Client, error) {
+	if auth == NTLMAuth && !endpoint.HTTPS {
+		return nil, errors.New("NTLM authentication requires HTTPS, message sealing over plain HTTP is not implemented")
+	}
+	t, err := newTransport(endpoint.URL(), user, password, auth, endpoint.Insecure)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{url: endpoint.URL(), transport: t}, nil
+}
+
+// RunPowerShell runs the script in a new PowerShell process and returns its exit code.
+// The script is sent through stdin so that its size is not limited by the command line, on the first line so that
+// the script reads the content of stdin, if any, from [Console]::In.
+func (c *Client) RunPowerShell(ctx context.Context, script string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	bootstrap := `$script = [Text.Encoding]::UTF8.GetString([Convert]::FromBase64String([Console]::In.ReadLine())); . ([ScriptBlock]::Create($script))`
+	input := strings.NewReader(base64.StdEncoding.EncodeToString([]byte(script)) + "\n")
+	if stdin == nil {
+		return c.Run(ctx, "powershell.exe", powerShellArgs(bootstrap), input, stdout, stderr)
+	}
+	return c.Run(ctx, "powershell.exe", powerShellArgs(bootstrap), io.MultiReader(input, stdin), stdout, stderr)
+}
+
+// Upload writes the content of src to the remote path, creating the parent directory if needed
+func (c *Client) Upload(ctx context.Context, src io.Reader, remotePath string) error {
+	write := fmt.Sprintf(`$ErrorActionPreference = "Stop"; $path = '%s'; New-Item -ItemType Directory -Force -Path (Split-Path -Parent $path) | Out-Null; `+
+		`$out = [IO.File]::Create($path); try { [Console]::OpenStandardInput().CopyTo($out) } finally { $out.Close() }`, strings.ReplaceAll(remotePath, "'", "''"))
+
+	var stderr strings.Builder
+	exitCode, err := c.Run(ctx, "powershell.exe", powerShellArgs(write), src, io.Discard, &stderr)
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("failed to upload %s, exit code %d: %s", remotePath, exitCode, stderr.String())
+	}
+	return nil
+}
+
+// Run runs a command in a new remote shell, stdin may be nil
+func (c *Client) Run(ctx context.Context, command string, args []string, stdin io.Reader, stdout, stderr io.Writer) (exitCode int, err error) {
+	resp, err := c.call(ctx, createShellMessage(c.url))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create WinRM shell: %w", err)
+	}
+	shellID := resp.shellID()
+	if shellID == "" {
+		return 0, errors.New("no shell ID in the WinRM response")
+	}
+	defer func() {
+		// Always release the shell, the context may be cancelled already
+		if _, deleteErr := c.call(context.Background(), deleteShellMessage(c.url, shellID)); deleteErr != nil && err == nil {
+			err = fmt.Errorf("failed to delete WinRM shell: %w", deleteErr)
+		}
+	}()
+
+	resp, err = c.call(ctx, commandMessage(c.url, shellID, command, args))
+	if err != nil {
+		return 0, fmt.Errorf("failed to start command: %w", err)
+	}
+	commandID := resp.Body.CommandID
+	defer func() {
+		_, _ = c.call(context.Background(), signalTerminateMessage(c.url, shellID, commandID))
+	}()
+
+	if err = c.sendStdin(ctx, shellID, commandID, stdin); err != nil {
+		return 0, err
+	}
+
+	for {
+		resp, err = c.call(ctx, receiveMessage(c.url, shellID, commandID))
+		var f *fault
+		if errors.As(err, &f) && f.WSManFault.Code == operationTimeoutFaultCode {
+			// No output during the operation timeout, the command is still running
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to receive command output: %w", err)
+		}
+
+		for _, s := range resp.Body.Streams {
+			data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s.Data))
+			if err != nil {
+				return 0, fmt.Errorf("invalid %s stream data: %w", s.Name, err)
+			}
+			out := stdout
+			if s.Name == "stderr" {
+				out = stderr
+			}
+			if _, err = out.Write(data); err != nil {
+				return 0, err
+			}
+		}
+
+		if resp.Body.State.State == commandStateDone {
+			return resp.Body.State.ExitCode, nil
+		}
+	}
+}
+
+func (c *Client) sendStdin(ctx context.Context, shellID, commandID string, stdin io.Reader) error {
+	if stdin == nil {
+		_, err := c.call(ctx, sendMessage(c.url, shellID, commandID, "", true))
+		return err
+	}
+
+	buf := make([]byte, stdinChunkSize)
+	for {
+		n, readErr := io.ReadFull(stdin, buf)
+		end := errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF)
+		if readErr != nil && !end {
+			return readErr
+		}
+		if _, err := c.call(ctx, sendMessage(c.url, shellID, commandID, base64.StdEncoding.EncodeToString(buf[:n]), end)); err != nil {
+			return fmt.Errorf("failed to send stdin: %w", err)
+		}
+		if end {
+			return nil
+		}
+	}
+}
+
+func (c *Client) call(ctx context.Context, message []byte) (*response, error) {
+	body, err := c.transport.post(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+	return parseResponse(body)
+}
+
+// powerShellArgs returns the arguments running the command with -EncodedCommand, avoiding any quoting issue with cmd.exe
+func powerShellArgs(command string) []string {
+	return []string{"-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-EncodedCommand", base64.StdEncoding.EncodeToString(utf16LE(command))}
+}
@@ -0,0 +1,211 @@
+package winrm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	actionRegexp = regexp.MustCompile(`<a:Action mustUnderstand="true">([^<]+)</a:Action>`)
+	stdinRegexp  = regexp.MustCompile(`<rsp:Stream Name="stdin"[^>]*>([^<]*)</rsp:Stream>`)
+)
+
+// stubServer is a local WinRM protocol stub echoing stdin to stdout
+type stubServer struct {
+	t        *testing.T
+	exitCode int
+	stdin    bytes.Buffer
+	receives int
+	deleted  bool
+	// authorize returns false if the request must be answered with a 401
+	authorize func(w http.ResponseWriter, r *http.Request) bool
+}
+
+func (s *stubServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.authorize != nil && !s.authorize(w, r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	require.NoError(s.t, err)
+	action := actionRegexp.FindStringSubmatch(string(body))
+	require.Len(s.t, action, 2, "missing action in %s", body)
+
+	w.Header().Set("Content-Type", soapContentType)
+	switch action[1] {
+	case actionCreate:
+		writeEnvelope(w, `<rsp:Shell><rsp:ShellId>shell-1</rsp:ShellId></rsp:Shell>`)
+	case actionCommand:
+		assert.Contains(s.t, string(body), `<w:Selector Name="ShellId">shell-1</w:Selector>`)
+		assert.Contains(s.t, string(body), `<rsp:Command>powershell.exe</rsp:Command>`)
+		writeEnvelope(w, `<rsp:CommandResponse><rsp:CommandId>command-1</rsp:CommandId></rsp:CommandResponse>`)
+	case actionSend:
+		data, err := base64.StdEncoding.DecodeString(stdinRegexp.FindStringSubmatch(string(body))[1])
+		require.NoError(s.t, err)
+		s.stdin.Write(data)
+		writeEnvelope(w, `<rsp:SendResponse/>`)
+	case actionReceive:
+		s.receives++
+		if s.receives == 1 {
+			// The first receive times out as if the command was still running
+			w.WriteHeader(http.StatusInternalServerError)
+			writeEnvelope(w, fmt.Sprintf(`<s:Fault><s:Code><s:Value>s:Receiver</s:Value><s:Subcode><s:Value>w:TimedOut</s:Value></s:Subcode></s:Code>`+
+				`<s:Reason><s:Text xml:lang="">timed out</s:Text></s:Reason><s:Detail><f:WSManFault xmlns:f="http://schemas.microsoft.com/wbem/wsman/1/wsmanfault" Code="%s"/></s:Detail></s:Fault>`, operationTimeoutFaultCode))
+			return
+		}
+		writeEnvelope(w, fmt.Sprintf(`<rsp:ReceiveResponse>`+
+			`<rsp:Stream Name="stdout" CommandId="command-1">%s</rsp:Stream>`+
+			`<rsp:Stream Name="stderr" CommandId="command-1">%s</rsp:Stream>`+
+			`<rsp:CommandState CommandId="command-1" State="%s"><rsp:ExitCode>%d</rsp:ExitCode></rsp:CommandState>`+
+			`</rsp:ReceiveResponse>`,
+			base64.StdEncoding.EncodeToString(s.stdin.Bytes()), base64.StdEncoding.EncodeToString([]byte("warning")), commandStateDone, s.exitCode))
+	case actionSignal:
+		writeEnvelope(w, `<rsp:SignalResponse/>`)
+	case actionDelete:
+		s.deleted = true
+		writeEnvelope(w, "")
+	default:
+		s.t.Errorf("unexpected action %s", action[1])
+	}
+}
+
+func writeEnvelope(w io.Writer, body string) {
+	fmt.Fprintf(w, `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" xmlns:rsp="http://schemas.microsoft.com/wbem/wsman/1/windows/shell"><s:Header/><s:Body>%s</s:Body></s:Envelope>`, body)
+}
+
+func newStubClient(t *testing.T, stub *stubServer, auth AuthMethod) *Client {
+	// NTLM authentication requires HTTPS
+	https := auth == NTLMAuth
+	server := httptest.NewUnstartedServer(stub)
+	if https {
+		server.StartTLS()
+	} else {
+		server.Start()
+	}
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+
+	client, err := NewClient(Endpoint{Host: serverURL.Hostname(), Port: port, HTTPS: https, Insecure: https}, "Administrator", "secret", auth)
+	require.NoError(t, err)
+	return client
+}
+
+func TestRunPowerShell(t *testing.T) {
+	stub := &stubServer{t: t, exitCode: 3}
+	stub.authorize = func(_ http.ResponseWriter, r *http.Request) bool {
+		user, password, ok := r.BasicAuth()
+		return ok && user == "Administrator" && password == "secret"
+	}
+	client := newStubClient(t, stub, BasicAuth)
+
+	script := "Write-Output 'hello'\nexit 3"
+	var stdout, stderr bytes.Buffer
+	exitCode, err := client.RunPowerShell(context.Background(), script, nil, &stdout, &stderr)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, exitCode)
+	// The stub echoes stdin, which holds the encoded script
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(script))+"\n", stdout.String())
+	assert.Equal(t, "warning", stderr.String())
+	assert.Equal(t, 2, stub.receives)
+	assert.True(t, stub.deleted)
+}
+
+func TestRunPowerShellStdin(t *testing.T) {
+	stub := &stubServer{t: t}
+	client := newStubClient(t, stub, BasicAuth)
+
+	script := "$password = [Console]::In.ReadLine()"
+	var stdout bytes.Buffer
+	_, err := client.RunPowerShell(context.Background(), script, strings.NewReader("p@ssw0rd\n"), &stdout, io.Discard)
+	require.NoError(t, err)
+
+	// The script is read from the first line, the script reads the following ones
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(script))+"\np@ssw0rd\n", stdout.String())
+}
+
+func TestNTLMRequiresHTTPS(t *testing.T) {
+	_, err := NewClient(Endpoint{Host: "10.0.0.1"}, "Administrator", "secret", NTLMAuth)
+	assert.ErrorContains(t, err, "NTLM authentication requires HTTPS")
+
+	_, err = NewClient(Endpoint{Host: "10.0.0.1", HTTPS: true}, "Administrator", "secret", NTLMAuth)
+	assert.NoError(t, err)
+}
+
+func TestUpload(t *testing.T) {
+	stub := &stubServer{t: t}
+	client := newStubClient(t, stub, BasicAuth)
+
+	content := bytes.Repeat([]byte("0123456789"), stdinChunkSize/4)
+	require.NoError(t, client.Upload(context.Background(), bytes.NewReader(content), `C:\temp\file.txt`))
+	assert.Equal(t, content, stub.stdin.Bytes())
+}
+
+func TestUploadFailure(t *testing.T) {
+	stub := &stubServer{t: t, exitCode: 1}
+	client := newStubClient(t, stub, BasicAuth)
+
+	err := client.Upload(context.Background(), strings.NewReader("content"), `C:\temp\file.txt`)
+	assert.ErrorContains(t, err, "exit code 1")
+}
+
+func TestNTLMAuthentication(t *testing.T) {
+	serverChallenge := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	challengeMsg := make([]byte, 48)
+	copy(challengeMsg, ntlmSignature)
+	binary.LittleEndian.PutUint32(challengeMsg[8:], 2)
+	binary.LittleEndian.PutUint32(challengeMsg[20:], ntlmNegotiateFlags)
+	copy(challengeMsg[24:], serverChallenge)
+	targetInfo := append(avPair(ntlmAvTimestamp, make([]byte, 8)), avPair(ntlmAvEOL, nil)...)
+	binary.LittleEndian.PutUint16(challengeMsg[40:], uint16(len(targetInfo)))
+	binary.LittleEndian.PutUint16(challengeMsg[42:], uint16(len(targetInfo)))
+	binary.LittleEndian.PutUint32(challengeMsg[44:], 48)
+	challengeMsg = append(challengeMsg, targetInfo...)
+
+	stub := &stubServer{t: t}
+	stub.authorize = func(w http.ResponseWriter, r *http.Request) bool {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Negotiate ")
+		require.True(t, ok)
+		msg, err := base64.StdEncoding.DecodeString(token)
+		require.NoError(t, err)
+
+		switch binary.LittleEndian.Uint32(msg[8:]) {
+		case 1:
+			w.Header().Set("WWW-Authenticate", "Negotiate "+base64.StdEncoding.EncodeToString(challengeMsg))
+			return false
+		case 3:
+			// Check the NTProofStr of the NtChallengeResponse against the password
+			length := binary.LittleEndian.Uint16(msg[20:])
+			offset := binary.LittleEndian.Uint32(msg[24:])
+			ntResponse := msg[offset : offset+uint32(length)]
+			expected := hmacMD5(ntowfV2("", "Administrator", "secret"), append(append([]byte{}, serverChallenge...), ntResponse[16:]...))
+			return bytes.Equal(expected, ntResponse[:16])
+		}
+		return false
+	}
+	client := newStubClient(t, stub, NTLMAuth)
+
+	var stdout bytes.Buffer
+	_, err := client.RunPowerShell(context.Background(), "exit 0", nil, &stdout, io.Discard)
+	require.NoError(t, err)
+	assert.True(t, stub.deleted)
+}
@@ -0,0 +1,94 @@
+// Command winrm runs PowerShell scripts and copies files to a Windows host over WinRM.
+// It is run by command.WinRMRunner through local commands, the connection is configured with environment variables:
+// WINRM_HOST, WINRM_PORT, WINRM_HTTPS, WINRM_INSECURE, WINRM_USER, WINRM_PASSWORD and WINRM_AUTH (basic or ntlm).
+// It must be built before deploying, with `inv setup.winrm-client`,
+// the runner uses the `winrm` binary in the PATH unless `ddinfra:winrmClientPath` is set.
+//
+// Usage:
+//
+//	winrm run <environment variable holding the script> [<environment variable holding stdin>]
+//	winrm copy <local path> <remote path>
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/test-infra-definitions/components/command/winrm"
+)
+
+func main() {
+	exitCode, err := run(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(exitCode)
+}
+
+func run(args []string) (int, error) {
+	client, err := clientFromEnv()
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	switch {
+	case (len(args) == 2 || len(args) == 3) && args[0] == "run":
+		script, ok := os.LookupEnv(args[1])
+		if !ok {
+			return 0, fmt.Errorf("environment variable %s is not set", args[1])
+		}
+		var stdin io.Reader
+		if len(args) == 3 {
+			content, ok := os.LookupEnv(args[2])
+			if !ok {
+				return 0, fmt.Errorf("environment variable %s is not set", args[2])
+			}
+			stdin = strings.NewReader(content)
+		}
+		return client.RunPowerShell(ctx, script, stdin, os.Stdout, os.Stderr)
+
+	case len(args) == 3 && args[0] == "copy":
+		src, err := os.Open(args[1])
+		if err != nil {
+			return 0, err
+		}
+		defer src.Close()
+		return 0, client.Upload(ctx, src, args[2])
+
+	default:
+		return 0, fmt.Errorf("usage: winrm run <script environment variable> [<stdin environment variable>] | winrm copy <local path> <remote path>")
+	}
+}
+
+func clientFromEnv() (*winrm.Client, error) {
+	endpoint := winrm.Endpoint{
+		Host:     os.Getenv("WINRM_HOST"),
+		HTTPS:    os.Getenv("WINRM_HTTPS") == "true",
+		Insecure: os.Getenv("WINRM_INSECURE") == "true",
+	}
+	if endpoint.Host == "" {
+		return nil, fmt.Errorf("WINRM_HOST is not set")
+	}
+	if port := os.Getenv("WINRM_PORT"); port != "" {
+		var err error
+		if endpoint.Port, err = strconv.Atoi(port); err != nil {
+			return nil, fmt.Errorf("invalid WINRM_PORT %q: %w", port, err)
+		}
+	}
+
+	auth := winrm.AuthMethod(os.Getenv("WINRM_AUTH"))
+	if auth == "" {
+		auth = winrm.NTLMAuth
+	}
+
+	return winrm.NewClient(endpoint, os.Getenv("WINRM_USER"), os.Getenv("WINRM_PASSWORD"), auth)
+}
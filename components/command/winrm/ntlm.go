@@ -0,0 +1,183 @@
+package winrm
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4"
+)
+
+// Minimal NTLMv2 implementation, see [MS-NLMP].
+// Only authentication is implemented, message signing and sealing are not supported.
+//
+// [MS-NLMP]: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-nlmp/b38c36ed-2804-4868-a9ff-8dd3182128e4
+
+const (
+	ntlmNegotiateUnicode               = 0x00000001
+	ntlmRequestTarget                  = 0x00000004
+	ntlmNegotiateNTLM                  = 0x00000200
+	ntlmNegotiateAlwaysSign            = 0x00008000
+	ntlmNegotiateExtendedSessionSecure = 0x00080000
+	ntlmNegotiateTargetInfo            = 0x00800000
+	ntlmNegotiate128                   = 0x20000000
+	ntlmNegotiate56                    = 0x80000000
+
+	ntlmNegotiateFlags = ntlmNegotiateUnicode | ntlmRequestTarget | ntlmNegotiateNTLM | ntlmNegotiateAlwaysSign |
+		ntlmNegotiateExtendedSessionSecure | ntlmNegotiateTargetInfo | ntlmNegotiate128 | ntlmNegotiate56
+
+	ntlmAvEOL       = 0x0000
+	ntlmAvTimestamp = 0x0007
+)
+
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+// ntlmNegotiateMessage returns the NEGOTIATE_MESSAGE starting the handshake
+func ntlmNegotiateMessage() []byte {
+	msg := make([]byte, 32)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 1)
+	binary.LittleEndian.PutUint32(msg[12:], ntlmNegotiateFlags)
+	// Domain and workstation fields are left empty
+	return msg
+}
+
+type ntlmChallenge struct {
+	flags           uint32
+	serverChallenge []byte
+	targetInfo      []byte
+}
+
+func parseNTLMChallenge(msg []byte) (*ntlmChallenge, error) {
+	if len(msg) < 32 || !bytes.Equal(msg[:8], ntlmSignature) || binary.LittleEndian.Uint32(msg[8:]) != 2 {
+		return nil, errors.New("invalid NTLM challenge message")
+	}
+
+	challenge := &ntlmChallenge{
+		flags:           binary.LittleEndian.Uint32(msg[20:]),
+		serverChallenge: msg[24:32],
+	}
+	if len(msg) >= 48 {
+		length := int(binary.LittleEndian.Uint16(msg[40:]))
+		offset := int(binary.LittleEndian.Uint32(msg[44:]))
+		if offset+length > len(msg) {
+			return nil, errors.New("invalid target info in NTLM challenge message")
+		}
+		challenge.targetInfo = msg[offset : offset+length]
+	}
+
+	return challenge, nil
+}
+
+// ntlmAuthenticateMessage returns the AUTHENTICATE_MESSAGE answering the challenge
+func ntlmAuthenticateMessage(challenge *ntlmChallenge, domain, user, password string) ([]byte, error) {
+	clientChallenge := make([]byte, 8)
+	if _, err := rand.Read(clientChallenge); err != nil {
+		return nil, err
+	}
+
+	timestamp := ntlmTimestamp(challenge.targetInfo)
+	if timestamp == nil {
+		timestamp = fileTime(time.Now())
+	}
+
+	ntResponse := ntlmV2Response(ntowfV2(domain, user, password), challenge.serverChallenge, clientChallenge, timestamp, challenge.targetInfo)
+	// The LMv2 response must be zeroed when the server sends a timestamp, it is ignored by servers otherwise
+	lmResponse := make([]byte, 24)
+
+	payloads := [][]byte{lmResponse, ntResponse, utf16LE(domain), utf16LE(user), nil, nil}
+	const headerLength = 64
+	msg := make([]byte, headerLength)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 3)
+	offset := headerLength
+	for i, payload := range payloads {
+		field := msg[12+8*i:]
+		binary.LittleEndian.PutUint16(field, uint16(len(payload)))
+		binary.LittleEndian.PutUint16(field[2:], uint16(len(payload)))
+		binary.LittleEndian.PutUint32(field[4:], uint32(offset))
+		offset += len(payload)
+	}
+	binary.LittleEndian.PutUint32(msg[60:], challenge.flags&ntlmNegotiateFlags)
+	for _, payload := range payloads {
+		msg = append(msg, payload...)
+	}
+
+	return msg, nil
+}
+
+// ntowfV2 computes the NTLMv2 response key
+func ntowfV2(domain, user, password string) []byte {
+	ntHash := md4.New()
+	ntHash.Write(utf16LE(password))
+	return hmacMD5(ntHash.Sum(nil), utf16LE(strings.ToUpper(user)+domain))
+}
+
+// ntlmV2Response computes the NtChallengeResponse: NTProofStr followed by the client blob
+func ntlmV2Response(responseKey, serverChallenge, clientChallenge, timestamp, targetInfo []byte) []byte {
+	blob := []byte{0x01, 0x01, 0, 0, 0, 0, 0, 0}
+	blob = append(blob, timestamp...)
+	blob = append(blob, clientChallenge...)
+	blob = append(blob, 0, 0, 0, 0)
+	blob = append(blob, targetInfo...)
+	blob = append(blob, 0, 0, 0, 0)
+
+	ntProofStr := hmacMD5(responseKey, append(append([]byte{}, serverChallenge...), blob...))
+	return append(ntProofStr, blob...)
+}
+
+// ntlmTimestamp returns the MsvAvTimestamp AV pair value of the target info, if any
+func ntlmTimestamp(targetInfo []byte) []byte {
+	for len(targetInfo) >= 4 {
+		id := binary.LittleEndian.Uint16(targetInfo)
+		length := int(binary.LittleEndian.Uint16(targetInfo[2:]))
+		if id == ntlmAvEOL || len(targetInfo) < 4+length {
+			return nil
+		}
+		if id == ntlmAvTimestamp && length == 8 {
+			return targetInfo[4:12]
+		}
+		targetInfo = targetInfo[4+length:]
+	}
+	return nil
+}
+
+// fileTime returns t as a little endian Windows FILETIME, the number of 100ns intervals since January 1, 1601
+func fileTime(t time.Time) []byte {
+	const epochOffset = 116444736000000000
+	ft := make([]byte, 8)
+	binary.LittleEndian.PutUint64(ft, uint64(t.UnixNano()/100+epochOffset))
+	return ft
+}
+
+func hmacMD5(key, data []byte) []byte {
+	mac := hmac.New(md5.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func utf16LE(s string) []byte {
+	encoded := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(encoded))
+	for i, r := range encoded {
+		binary.LittleEndian.PutUint16(b[2*i:], r)
+	}
+	return b
+}
+
+// splitDomainUser splits `DOMAIN\user` and `user@domain` user names
+func splitDomainUser(user string) (domain string, name string) {
+	if i := strings.Index(user, `\`); i >= 0 {
+		return user[:i], user[i+1:]
+	}
+	if i := strings.LastIndex(user, "@"); i >= 0 {
+		return user[i+1:], user[:i]
+	}
+	return "", user
+}
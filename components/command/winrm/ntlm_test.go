@@ -0,0 +1,41 @@
+package winrm
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test vectors from [MS-NLMP] section 4.2.4
+func TestNTLMv2Response(t *testing.T) {
+	responseKey := ntowfV2("Domain", "User", "Password")
+	assert.Equal(t, "0c868a403bfd7a93a3001ef22ef02e3f", hex.EncodeToString(responseKey))
+
+	targetInfo := append(avPair(2, utf16LE("Domain")), avPair(1, utf16LE("Server"))...)
+	targetInfo = append(targetInfo, avPair(ntlmAvEOL, nil)...)
+	serverChallenge, _ := hex.DecodeString("0123456789abcdef")
+	clientChallenge, _ := hex.DecodeString("aaaaaaaaaaaaaaaa")
+
+	response := ntlmV2Response(responseKey, serverChallenge, clientChallenge, make([]byte, 8), targetInfo)
+	assert.Equal(t, "68cd0ab851e51c96aabc927bebef6a1c", hex.EncodeToString(response[:16]))
+}
+
+func TestSplitDomainUser(t *testing.T) {
+	for user, want := range map[string][2]string{
+		"Administrator":         {"", "Administrator"},
+		`CORP\Administrator`:    {"CORP", "Administrator"},
+		"Administrator@corp.io": {"corp.io", "Administrator"},
+	} {
+		domain, name := splitDomainUser(user)
+		assert.Equal(t, want, [2]string{domain, name}, user)
+	}
+}
+
+func avPair(id uint16, value []byte) []byte {
+	b := make([]byte, 4, 4+len(value))
+	binary.LittleEndian.PutUint16(b, id)
+	binary.LittleEndian.PutUint16(b[2:], uint16(len(value)))
+	return append(b, value...)
+}
@@ -0,0 +1,187 @@
+package winrm
+
+import (
+	"crypto/rand"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// WS-Management actions and URIs used by the Windows remote shell, see [MS-WSMV]
+//
+// [MS-WSMV]: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-wsmv/055dc36b-db0c-41ac-9b3b-5a1d3b5e7c0e
+const (
+	shellResourceURI = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/cmd"
+
+	actionCreate  = "http://schemas.xmlsoap.org/ws/2004/09/transfer/Create"
+	actionDelete  = "http://schemas.xmlsoap.org/ws/2004/09/transfer/Delete"
+	actionCommand = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/Command"
+	actionSend    = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/Send"
+	actionReceive = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/Receive"
+	actionSignal  = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/Signal"
+
+	signalTerminate  = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/signal/terminate"
+	commandStateDone = "http://schemas.microsoft.com/wbem/wsman/1/windows/shell/CommandState/Done"
+
+	// operationTimeoutFaultCode is returned by Receive when the command produced no output during the operation timeout
+	operationTimeoutFaultCode = "2150858793"
+
+	maxEnvelopeSize  = 153600
+	operationTimeout = "PT60S"
+)
+
+type option struct {
+	name  string
+	value string
+}
+
+// envelope builds a SOAP request, shellID is empty for the shell creation
+func envelope(url, action, shellID string, options []option, body string) []byte {
+	var header strings.Builder
+	fmt.Fprintf(&header, `<a:To>%s</a:To>`, xmlEscape(url))
+	header.WriteString(`<a:ReplyTo><a:Address mustUnderstand="true">http://schemas.xmlsoap.org/ws/2004/08/addressing/role/anonymous</a:Address></a:ReplyTo>`)
+	fmt.Fprintf(&header, `<w:MaxEnvelopeSize mustUnderstand="true">%d</w:MaxEnvelopeSize>`, maxEnvelopeSize)
+	fmt.Fprintf(&header, `<a:MessageID>uuid:%s</a:MessageID>`, newUUID())
+	header.WriteString(`<w:Locale mustUnderstand="false" xml:lang="en-US"/>`)
+	fmt.Fprintf(&header, `<w:OperationTimeout>%s</w:OperationTimeout>`, operationTimeout)
+	fmt.Fprintf(&header, `<w:ResourceURI mustUnderstand="true">%s</w:ResourceURI>`, shellResourceURI)
+	fmt.Fprintf(&header, `<a:Action mustUnderstand="true">%s</a:Action>`, action)
+	if shellID != "" {
+		fmt.Fprintf(&header, `<w:SelectorSet><w:Selector Name="ShellId">%s</w:Selector></w:SelectorSet>`, xmlEscape(shellID))
+	}
+	if len(options) > 0 {
+		header.WriteString(`<w:OptionSet>`)
+		for _, o := range options {
+			fmt.Fprintf(&header, `<w:Option Name="%s">%s</w:Option>`, o.name, o.value)
+		}
+		header.WriteString(`</w:OptionSet>`)
+	}
+
+	return []byte(`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"` +
+		` xmlns:a="http://schemas.xmlsoap.org/ws/2004/08/addressing"` +
+		` xmlns:w="http://schemas.dmtf.org/wbem/wsman/1/wsman.xsd"` +
+		` xmlns:rsp="http://schemas.microsoft.com/wbem/wsman/1/windows/shell">` +
+		`<env:Header>` + header.String() + `</env:Header>` +
+		`<env:Body>` + body + `</env:Body>` +
+		`</env:Envelope>`)
+}
+
+func createShellMessage(url string) []byte {
+	return envelope(url, actionCreate, "", []option{{"WINRS_NOPROFILE", "FALSE"}, {"WINRS_CODEPAGE", "65001"}},
+		`<rsp:Shell><rsp:InputStreams>stdin</rsp:InputStreams><rsp:OutputStreams>stdout stderr</rsp:OutputStreams></rsp:Shell>`)
+}
+
+func deleteShellMessage(url, shellID string) []byte {
+	return envelope(url, actionDelete, shellID, nil, "")
+}
+
+func commandMessage(url, shellID, command string, args []string) []byte {
+	var body strings.Builder
+	fmt.Fprintf(&body, `<rsp:CommandLine><rsp:Command>%s</rsp:Command>`, xmlEscape(command))
+	for _, arg := range args {
+		fmt.Fprintf(&body, `<rsp:Arguments>%s</rsp:Arguments>`, xmlEscape(arg))
+	}
+	body.WriteString(`</rsp:CommandLine>`)
+
+	// stdin is a pipe to keep binary data untouched
+	return envelope(url, actionCommand, shellID, []option{{"WINRS_CONSOLEMODE_STDIN", "FALSE"}, {"WINRS_SKIP_CMD_SHELL", "FALSE"}}, body.String())
+}
+
+func sendMessage(url, shellID, commandID, data string, end bool) []byte {
+	endAttr := ""
+	if end {
+		endAttr = ` End="true"`
+	}
+	return envelope(url, actionSend, shellID, nil,
+		fmt.Sprintf(`<rsp:Send><rsp:Stream Name="stdin" CommandId="%s"%s>%s</rsp:Stream></rsp:Send>`, xmlEscape(commandID), endAttr, data))
+}
+
+func receiveMessage(url, shellID, commandID string) []byte {
+	return envelope(url, actionReceive, shellID, nil,
+		fmt.Sprintf(`<rsp:Receive><rsp:DesiredStream CommandId="%s">stdout stderr</rsp:DesiredStream></rsp:Receive>`, xmlEscape(commandID)))
+}
+
+func signalTerminateMessage(url, shellID, commandID string) []byte {
+	return envelope(url, actionSignal, shellID, nil,
+		fmt.Sprintf(`<rsp:Signal CommandId="%s"><rsp:Code>%s</rsp:Code></rsp:Signal>`, xmlEscape(commandID), signalTerminate))
+}
+
+// response holds the parts of WinRM responses used by the client, elements are matched by local name
+type response struct {
+	Body struct {
+		ShellID   string     `xml:"Shell>ShellId"`
+		Selectors []selector `xml:"ResourceCreated>ReferenceParameters>SelectorSet>Selector"`
+		CommandID string     `xml:"CommandResponse>CommandId"`
+		Streams   []stream   `xml:"ReceiveResponse>Stream"`
+		State     struct {
+			State    string `xml:"State,attr"`
+			ExitCode int    `xml:"ExitCode"`
+		} `xml:"ReceiveResponse>CommandState"`
+		Fault *fault `xml:"Fault"`
+	} `xml:"Body"`
+}
+
+type selector struct {
+	Name  string `xml:"Name,attr"`
+	Value string `xml:",chardata"`
+}
+
+type stream struct {
+	Name string `xml:"Name,attr"`
+	End  bool   `xml:"End,attr"`
+	Data string `xml:",chardata"`
+}
+
+type fault struct {
+	Subcode    string `xml:"Code>Subcode>Value"`
+	Reason     string `xml:"Reason>Text"`
+	WSManFault struct {
+		Code    string `xml:"Code,attr"`
+		Message string `xml:"Message"`
+	} `xml:"Detail>WSManFault"`
+}
+
+func (f *fault) Error() string {
+	message := strings.TrimSpace(f.WSManFault.Message)
+	if message == "" {
+		message = strings.TrimSpace(f.Reason)
+	}
+	return fmt.Sprintf("WinRM fault %s (code %s): %s", f.Subcode, f.WSManFault.Code, message)
+}
+
+func parseResponse(body []byte) (*response, error) {
+	resp := &response{}
+	if err := xml.Unmarshal(body, resp); err != nil {
+		return nil, fmt.Errorf("invalid WinRM response: %w", err)
+	}
+	if resp.Body.Fault != nil {
+		return resp, resp.Body.Fault
+	}
+	return resp, nil
+}
+
+func (r *response) shellID() string {
+	if r.Body.ShellID != "" {
+		return r.Body.ShellID
+	}
+	for _, s := range r.Body.Selectors {
+		if s.Name == "ShellId" {
+			return s.Value
+		}
+	}
+	return ""
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func newUUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%X-%X-%X-%X-%X", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
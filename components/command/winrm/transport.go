@@ -0,0 +1,147 @@
+package winrm
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type AuthMethod string
+
+const (
+	BasicAuth AuthMethod = "basic"
+	NTLMAuth  AuthMethod = "ntlm"
+)
+
+const soapContentType = "application/soap+xml;charset=UTF-8"
+
+// transport posts SOAP messages to the WinRM endpoint
+type transport struct {
+	url      string
+	user     string
+	password string
+	auth     AuthMethod
+	client   *http.Client
+}
+
+func newTransport(url, user, password string, auth AuthMethod, insecure bool) (*transport, error) {
+	switch auth {
+	case BasicAuth, NTLMAuth:
+	default:
+		return nil, fmt.Errorf("unsupported WinRM authentication method %q", auth)
+	}
+
+	return &transport{
+		url:      url,
+		user:     user,
+		password: password,
+		auth:     auth,
+		client: &http.Client{
+			Transport: &http.Transport{
+				// NTLM authenticates the connection, the handshake must happen on a single connection
+				MaxConnsPerHost: 1,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure}, //nolint:gosec // test hosts use self-signed certificates
+			},
+		},
+	}, nil
+}
+
+// post sends the message and returns the response body, SOAP faults are returned with a 500 status code and parsed by the caller
+func (t *transport) post(ctx context.Context, message []byte) ([]byte, error) {
+	var resp *http.Response
+	var err error
+	if t.auth == NTLMAuth {
+		resp, err = t.postNTLM(ctx, message)
+	} else {
+		resp, err = t.do(ctx, message, func(req *http.Request) { req.SetBasicAuth(t.user, t.password) })
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusInternalServerError {
+		return nil, fmt.Errorf("unexpected WinRM response status %s: %s", resp.Status, body)
+	}
+
+	return body, nil
+}
+
+func (t *transport) postNTLM(ctx context.Context, message []byte) (*http.Response, error) {
+	resp, err := t.do(ctx, nil, func(req *http.Request) {
+		req.Header.Set("Authorization", "Negotiate "+base64.StdEncoding.EncodeToString(ntlmNegotiateMessage()))
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The body must be drained for the connection to be reused for the rest of the handshake
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		return nil, fmt.Errorf("unexpected WinRM response status %s to the NTLM negotiate message", resp.Status)
+	}
+
+	var challengeMsg []byte
+	for _, header := range resp.Header.Values("Www-Authenticate") {
+		if token, ok := strings.CutPrefix(header, "Negotiate "); ok {
+			if challengeMsg, err = base64.StdEncoding.DecodeString(token); err != nil {
+				return nil, fmt.Errorf("invalid NTLM challenge: %w", err)
+			}
+		}
+	}
+	if challengeMsg == nil {
+		return nil, fmt.Errorf("no NTLM challenge in the WinRM response, is Negotiate authentication enabled?")
+	}
+	challenge, err := parseNTLMChallenge(challengeMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	domain, user := splitDomainUser(t.user)
+	authenticateMsg, err := ntlmAuthenticateMessage(challenge, domain, user, t.password)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err = t.do(ctx, message, func(req *http.Request) {
+		req.Header.Set("Authorization", "Negotiate "+base64.StdEncoding.EncodeToString(authenticateMsg))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		return nil, fmt.Errorf("WinRM NTLM authentication failed for user %s", t.user)
+	}
+
+	return resp, nil
+}
+
+func (t *transport) do(ctx context.Context, message []byte, authorize func(*http.Request)) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(message))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", soapContentType)
+	authorize(req)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && t.auth == BasicAuth {
+		resp.Body.Close()
+		return nil, fmt.Errorf("WinRM basic authentication failed for user %s, is basic authentication enabled?", t.user)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,202 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/alessio/shellescape"
+	"github.com/pulumi/pulumi-command/sdk/go/command/local"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/common/namer"
+	"github.com/DataDog/test-infra-definitions/common/utils"
+	"github.com/DataDog/test-infra-definitions/components/command/winrm"
+)
+
+// defaultWinRMClientPath is the WinRM client installed with `inv setup.winrm-client`
+const defaultWinRMClientPath = "winrm"
+
+// Environment variables holding the scripts of WinRM commands
+const (
+	winrmCreateScriptEnvVar = "WINRM_CREATE_SCRIPT"
+	winrmUpdateScriptEnvVar = "WINRM_UPDATE_SCRIPT"
+	winrmDeleteScriptEnvVar = "WINRM_DELETE_SCRIPT"
+	winrmStdinEnvVar        = "WINRM_STDIN"
+)
+
+var _ Runner = &WinRMRunner{}
+
+// WinRMRunner runs PowerShell commands on a Windows host over WinRM instead of SSH.
+// Commands are local commands running the prebuilt WinRM client of the winrm package.
+// The WinRM listener must be enabled on the host and reachable, it is not set up by the runner.
+type WinRMRunner struct {
+	e           config.Env
+	namer       namer.Namer
+	clientPath  string
+	waitCommand Command
+	config      RunnerConfiguration
+	osCommand   OSCommand
+	environment pulumi.StringMap
+	options     []pulumi.ResourceOption
+}
+
+type WinRMRunnerArgs struct {
+	ParentResource pulumi.Resource
+	ConnectionName string
+	Host           pulumi.StringInput
+	// Port defaults to 5985 for HTTP and 5986 for HTTPS
+	Port  int
+	HTTPS bool
+	// Insecure skips the verification of the HTTPS listener certificate
+	Insecure bool
+	// Auth defaults to NTLM, which requires HTTPS
+	Auth      winrm.AuthMethod
+	User      string
+	Password  pulumi.StringInput
+	ReadyFunc ReadyFunc
+	// ClientPath is the path of the WinRM client, it defaults to the `winrm` binary in the PATH
+	ClientPath string
+}
+
+func NewWinRMRunner(e config.Env, args WinRMRunnerArgs) (*WinRMRunner, error) {
+	if args.Auth == "" {
+		args.Auth = winrm.NTLMAuth
+	}
+	if args.Password == nil {
+		args.Password = pulumi.String("")
+	}
+	if args.ClientPath == "" {
+		args.ClientPath = defaultWinRMClientPath
+	}
+	if args.Auth == winrm.NTLMAuth && !args.HTTPS {
+		return nil, errors.New("NTLM authentication requires HTTPS for WinRM commands")
+	}
+
+	runner := &WinRMRunner{
+		e:          e,
+		namer:      namer.NewNamer(e.Ctx(), "winrm").WithPrefix(args.ConnectionName),
+		clientPath: args.ClientPath,
+		config:     RunnerConfiguration{user: args.User},
+		osCommand:  NewWindowsOSCommand(),
+		environment: pulumi.StringMap{
+			"WINRM_HOST":     args.Host,
+			"WINRM_PORT":     pulumi.String(strconv.Itoa(args.Port)),
+			"WINRM_HTTPS":    pulumi.String(strconv.FormatBool(args.HTTPS)),
+			"WINRM_INSECURE": pulumi.String(strconv.FormatBool(args.Insecure)),
+			"WINRM_AUTH":     pulumi.String(string(args.Auth)),
+			"WINRM_USER":     pulumi.String(args.User),
+			"WINRM_PASSWORD": pulumi.ToSecret(args.Password).(pulumi.StringOutput),
+		},
+		options: []pulumi.ResourceOption{
+			e.WithProviders(config.ProviderCommand),
+		},
+	}
+	if args.Port == 0 {
+		delete(runner.environment, "WINRM_PORT")
+	}
+
+	if args.ParentResource != nil {
+		runner.options = append(runner.options, pulumi.Parent(args.ParentResource), pulumi.DeletedWith(args.ParentResource))
+	}
+
+	if args.ReadyFunc != nil {
+		var err error
+		runner.waitCommand, err = args.ReadyFunc(runner)
+		if err != nil {
+			return nil, err
+		}
+		runner.options = append(runner.options, utils.PulumiDependsOn(runner.waitCommand))
+	}
+
+	return runner, nil
+}
+
+func (r *WinRMRunner) Environment() config.Env {
+	return r.e
+}
+
+func (r *WinRMRunner) Namer() namer.Namer {
+	return r.namer
+}
+
+func (r *WinRMRunner) Config() RunnerConfiguration {
+	return r.config
+}
+
+func (r *WinRMRunner) OsCommand() OSCommand {
+	return r.osCommand
+}
+
+func (r *WinRMRunner) Command(name string, args RunnerCommandArgs, opts ...pulumi.ResourceOption) (Command, error) {
+	if _, ok := args.(*LocalArgs); ok {
+		return nil, errors.New("local arguments are not allowed for WinRM commands")
+	}
+	cmdArgs := args.Arguments()
+
+	// Scripts and stdin are passed through the environment as the command line length is limited
+	environment := r.commandEnvironment(nil)
+	runArgs := ""
+	if cmdArgs.Stdin != nil {
+		environment[winrmStdinEnvVar] = cmdArgs.Stdin.ToStringPtrOutput().Elem()
+		runArgs = " " + winrmStdinEnvVar
+	}
+	localArgs := &local.CommandArgs{
+		Triggers:    cmdArgs.Triggers,
+		Environment: environment,
+	}
+	for _, script := range []struct {
+		command pulumi.StringInput
+		envVar  string
+		target  *pulumi.StringPtrInput
+	}{
+		{cmdArgs.Create, winrmCreateScriptEnvVar, &localArgs.Create},
+		{cmdArgs.Update, winrmUpdateScriptEnvVar, &localArgs.Update},
+		{cmdArgs.Delete, winrmDeleteScriptEnvVar, &localArgs.Delete},
+	} {
+		if script.command == nil {
+			continue
+		}
+		environment[script.envVar] = r.osCommand.BuildCommandString(script.command, cmdArgs.Environment, cmdArgs.Sudo, cmdArgs.RequirePasswordFromStdin, r.config.user)
+		*script.target = pulumi.String(fmt.Sprintf("%s run %s%s", shellescape.Quote(r.clientPath), script.envVar, runArgs))
+	}
+
+	cmd, err := local.NewCommand(r.e.Ctx(), r.namer.ResourceName("cmd", name), localArgs, utils.MergeOptions(r.options, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalCommand{cmd}, nil
+}
+
+func (r *WinRMRunner) newCopyFile(name string, localPath, remotePath pulumi.StringInput, opts ...pulumi.ResourceOption) (pulumi.Resource, error) {
+	return local.NewCommand(r.e.Ctx(), r.namer.ResourceName("copy", name), &local.CommandArgs{
+		Create: pulumi.Sprintf("%s copy '%v' '%v'", shellescape.Quote(r.clientPath), localPath, remotePath),
+		Delete: pulumi.Sprintf("%s run %s", shellescape.Quote(r.clientPath), winrmDeleteScriptEnvVar),
+		Environment: r.commandEnvironment(pulumi.StringMap{
+			winrmDeleteScriptEnvVar: pulumi.Sprintf("Remove-Item -Force -Path '%v' -ErrorAction SilentlyContinue", remotePath),
+		}),
+		Triggers: pulumi.Array{localPath, remotePath},
+	}, utils.MergeOptions(r.options, opts...)...)
+}
+
+// commandEnvironment returns the environment configuring the WinRM client, merged with extra
+func (r *WinRMRunner) commandEnvironment(extra pulumi.StringMap) pulumi.StringMap {
+	environment := make(pulumi.StringMap, len(r.environment)+len(extra))
+	for k, v := range r.environment {
+		environment[k] = v
+	}
+	for k, v := range extra {
+		environment[k] = v
+	}
+	return environment
+}
+
+func (r *WinRMRunner) newCopyToRemoteFile(name string, localPath, remotePath pulumi.StringInput, opts ...pulumi.ResourceOption) (pulumi.Resource, error) {
+	return r.newCopyFile(name, localPath, remotePath, opts...)
+}
+
+func (r *WinRMRunner) PulumiOptions() []pulumi.ResourceOption {
+	return r.options
+}
@@ -99,6 +99,20 @@ func (c *Component) registerOutputs(ctx *pulumi.Context, self pulumi.ComponentRe
 				continue
 			}
 
+			// if field is a slice of components, let's export their outputs as an array
+			if field.Type.Kind() == reflect.Slice && field.Type.Elem().Implements(reflect.TypeOf((*component)(nil)).Elem()) {
+				outputs := pulumi.Array{}
+				slice := reflect.ValueOf(fieldValue)
+				for i := 0; i < slice.Len(); i++ {
+					if slice.Index(i).IsNil() {
+						return fmt.Errorf("cannot export field: %s as its element %d is nil", field.Name, i)
+					}
+					outputs = append(outputs, slice.Index(i).Interface().(component).getOutputs().ToMapOutput())
+				}
+				c.outputs[exportFieldName] = outputs
+				continue
+			}
+
 			// if field is a component, let's export its outputs
 			if field.Type.Implements(reflect.TypeOf((*component)(nil)).Elem()) {
 				if reflect.ValueOf(fieldValue).IsNil() {
@@ -160,7 +174,11 @@ func NewComponent[C component](e config.Env, name string, builder func(comp C) e
 }
 
 // isExportable checks if a field is exportable
-// a field is exportable if it is a pulumi.Input or a component
+// a field is exportable if it is a pulumi.Input, a component or a slice of components
 func isExportable(fieldType reflect.Type) bool { //nolint:unused, used through the `component` interface
-	return fieldType.Implements(reflect.TypeOf((*pulumi.Input)(nil)).Elem()) || fieldType.Implements(reflect.TypeOf((*component)(nil)).Elem())
+	componentType := reflect.TypeOf((*component)(nil)).Elem()
+	if fieldType.Kind() == reflect.Slice && fieldType.Elem().Implements(componentType) {
+		return true
+	}
+	return fieldType.Implements(reflect.TypeOf((*pulumi.Input)(nil)).Elem()) || fieldType.Implements(componentType)
 }
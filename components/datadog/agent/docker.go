@@ -3,12 +3,16 @@ package agent
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/alessio/shellescape"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 	"gopkg.in/yaml.v3"
 
 	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/common/utils"
 	"github.com/DataDog/test-infra-definitions/components"
+	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/DataDog/test-infra-definitions/components/datadog/dockeragentparams"
 	"github.com/DataDog/test-infra-definitions/components/docker"
 	remoteComp "github.com/DataDog/test-infra-definitions/components/remote"
@@ -24,6 +28,7 @@ type DockerAgentOutput struct {
 	DockerManager docker.ManagerOutput `json:"dockerManager"`
 	ContainerName string               `json:"containerName"`
 	FIPSEnabled   bool                 `json:"fipsEnabled"`
+	Status        AgentStatus          `json:"status"`
 }
 
 // DockerAgent is a Docker installer on a remote Host
@@ -34,6 +39,8 @@ type DockerAgent struct {
 	DockerManager *docker.Manager     `pulumi:"dockerManager"`
 	ContainerName pulumi.StringOutput `pulumi:"containerName"`
 	FIPSEnabled   pulumi.BoolOutput   `pulumi:"fipsEnabled"`
+	// Status is the AgentStatus once the Agent is healthy, empty without health check
+	Status pulumi.MapOutput `pulumi:"status"`
 }
 
 func (h *DockerAgent) Export(ctx *pulumi.Context, out *DockerAgentOutput) error {
@@ -69,11 +76,19 @@ func NewDockerAgent(e config.Env, vm *remoteComp.Host, manager *docker.Manager,
 		opts := make([]pulumi.ResourceOption, 0, len(params.PulumiDependsOn)+1)
 		opts = append(opts, params.PulumiDependsOn...)
 		opts = append(opts, pulumi.Parent(comp))
-		_, err = manager.ComposeStrUp("agent", composeContents, params.EnvironmentVariables, opts...)
+		upCmd, err := manager.ComposeStrUp("agent", composeContents, params.EnvironmentVariables, opts...)
 		if err != nil {
 			return err
 		}
 
+		comp.Status = pulumi.Map{}.ToMapOutput()
+		if params.HealthTimeout > 0 {
+			comp.Status, err = checkDockerAgentHealth(e, vm, params.HealthTimeout, pulumi.Array{composeContents[0].Content}, pulumi.Parent(comp), utils.PulumiDependsOn(upCmd))
+			if err != nil {
+				return err
+			}
+		}
+
 		// Fill component
 		comp.FIPSEnabled = pulumi.Bool(params.FIPS).ToBoolOutput()
 		comp.DockerManager = manager
@@ -131,3 +146,27 @@ func dockerAgentComposeManifest(agentImagePath string, apiKey pulumi.StringInput
 		Content: agentManifestContent,
 	}
 }
+
+// checkDockerAgentHealth waits for the Agent container to be healthy and returns its status, it is run again when triggers change
+func checkDockerAgentHealth(e config.Env, vm *remoteComp.Host, timeout time.Duration, triggers pulumi.Array, opts ...pulumi.ResourceOption) (pulumi.MapOutput, error) {
+	agent := "docker exec " + agentContainerName + " agent"
+	script := unixWaitForHealthyScript(agent, "docker logs --tail 100 "+agentContainerName, timeout)
+	healthCmd, err := vm.OS.Runner().Command(e.CommonNamer().ResourceName(vm.Name(), "docker-agent-healthy"), &command.Args{
+		Create:   pulumi.String("sh -c " + shellescape.Quote(script)),
+		Sudo:     true,
+		Triggers: triggers,
+	}, opts...)
+	if err != nil {
+		return pulumi.MapOutput{}, err
+	}
+
+	statusCmd, err := vm.OS.Runner().Command(e.CommonNamer().ResourceName(vm.Name(), "docker-agent-status"), &command.Args{
+		Create:   pulumi.String(agent + " status --json"),
+		Sudo:     true,
+		Triggers: triggers,
+	}, utils.MergeOptions(opts, utils.PulumiDependsOn(healthCmd))...)
+	if err != nil {
+		return pulumi.MapOutput{}, err
+	}
+	return statusCmd.StdoutOutput().ApplyT(parseAgentStatus).(pulumi.MapOutput), nil
+}
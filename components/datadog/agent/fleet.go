@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/components"
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams"
+	remoteComp "github.com/DataDog/test-infra-definitions/components/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+type HostAgentFleetOutput struct {
+	components.JSONImporter
+
+	Agents []HostAgentOutput `json:"agents"`
+}
+
+// HostAgentFleet is a group of on-host Agents, exported in the order of their hosts
+type HostAgentFleet struct {
+	pulumi.ResourceState
+	components.Component
+
+	Agents []*HostAgent `pulumi:"agents"`
+}
+
+// FleetHost is a host of a HostAgentFleet, Options are applied after the options shared by the fleet, for instance to set tags or the hostname
+type FleetHost struct {
+	Host    *remoteComp.Host
+	Options []agentparams.Option
+}
+
+func (f *HostAgentFleet) Export(ctx *pulumi.Context, out *HostAgentFleetOutput) error {
+	return components.Export(ctx, f, out)
+}
+
+// NewHostAgentFleet installs the Agent on each of the hosts with the shared options followed by the options of the host.
+// The installations do not depend on each other so they run in parallel.
+func NewHostAgentFleet(e config.Env, name string, hosts []FleetHost, options ...agentparams.Option) (*HostAgentFleet, error) {
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("the fleet %s has no host", name)
+	}
+
+	return components.NewComponent(e, name, func(comp *HostAgentFleet) error {
+		for _, host := range hosts {
+			// Copy the shared options, as they are appended to for each host
+			hostOptions := append(append([]agentparams.Option{}, options...), host.Options...)
+			hostAgent, err := NewHostAgent(e, host.Host, hostOptions...)
+			if err != nil {
+				return err
+			}
+			comp.Agents = append(comp.Agents, hostAgent)
+		}
+		return nil
+	})
+}
@@ -3,6 +3,11 @@ package agent
 import (
 	"fmt"
 	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/util/option"
 	"github.com/DataDog/test-infra-definitions/common/config"
@@ -12,6 +17,10 @@ import (
 	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams"
 	perms "github.com/DataDog/test-infra-definitions/components/datadog/agentparams/filepermissions"
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams/msi"
+	"github.com/DataDog/test-infra-definitions/components/datadog/configschema"
+	"github.com/DataDog/test-infra-definitions/components/diagnostics"
+	"github.com/DataDog/test-infra-definitions/components/os"
 	remoteComp "github.com/DataDog/test-infra-definitions/components/remote"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
@@ -19,8 +28,10 @@ import (
 type HostAgentOutput struct {
 	components.JSONImporter
 
-	Host        remoteComp.HostOutput `json:"host"`
-	FIPSEnabled bool                  `json:"fipsEnabled"`
+	Host              remoteComp.HostOutput `json:"host"`
+	FIPSEnabled       bool                  `json:"fipsEnabled"`
+	InstalledVersions []string              `json:"installedVersions"`
+	Status            AgentStatus           `json:"status"`
 }
 
 // HostAgent is an installer for the Agent on a remote host
@@ -33,6 +44,10 @@ type HostAgent struct {
 
 	Host        *remoteComp.Host  `pulumi:"host"`
 	FIPSEnabled pulumi.BoolOutput `pulumi:"fipsEnabled"`
+	// InstalledVersions are the versions of the Agent installed at each stage of the upgrade path, empty without upgrade path
+	InstalledVersions pulumi.StringArrayOutput `pulumi:"installedVersions"`
+	// Status is the AgentStatus once the Agent is healthy, empty without health check
+	Status pulumi.MapOutput `pulumi:"status"`
 }
 
 func (h *HostAgent) Export(ctx *pulumi.Context, out *HostAgentOutput) error {
@@ -68,20 +83,14 @@ func NewHostAgent(e config.Env, host *remoteComp.Host, options ...agentparams.Op
 	return hostInstallComp, nil
 }
 
-func (h *HostAgent) installScriptInstallation(env config.Env, params *agentparams.Params, baseOpts ...pulumi.ResourceOption) (command.Command, error) {
-	installCmdStr, err := h.manager.getInstallCommand(params.Version, env.AgentAPIKey(), params.AdditionalInstallParameters)
+func (h *HostAgent) installScriptInstallation(env config.Env, params *agentparams.Params, version agentparams.PackageVersion, stage int, baseOpts ...pulumi.ResourceOption) (command.Command, error) {
+	installCmdStr, err := h.manager.getInstallCommand(version, env.AgentAPIKey(), params.AdditionalInstallParameters, params.AdditionalSecretInstallParameters)
 	if err != nil {
 		return nil, err
 	}
 
-	uninstallCmd, err := h.manager.ensureAgentUninstalled(params.Version, baseOpts...)
-	if err != nil {
-		return nil, err
-	}
-	baseOpts = utils.MergeOptions(baseOpts, utils.PulumiDependsOn(uninstallCmd))
-
 	installCmd, err := h.Host.OS.Runner().Command(
-		h.namer.ResourceName("install-agent"),
+		upgradeStageName(h.namer.ResourceName("install-agent"), stage),
 		&command.Args{
 			Create: installCmdStr,
 		}, baseOpts...)
@@ -91,68 +100,154 @@ func (h *HostAgent) installScriptInstallation(env config.Env, params *agentparam
 	return installCmd, nil
 }
 
-func (h *HostAgent) directInstallInstallation(env config.Env, params *agentparams.Params, baseOpts ...pulumi.ResourceOption) (command.Command, error) {
-	packagePath, err := GetPackagePath(params.Version.LocalPath, h.Host.OS.Descriptor().Flavor, params.Version.Flavor, h.Host.OS.Descriptor().Architecture, env.PipelineID())
+func (h *HostAgent) repositoryInstallation(params *agentparams.Params, version agentparams.PackageVersion, stage int, baseOpts ...pulumi.ResourceOption) (command.Command, error) {
+	return h.manager.repositoryInstallCommand(version, *params.PackageRepository, upgradeStageTransformer(stage), baseOpts...)
+}
+
+// ensureUninstalledBeforeInstall uninstalls the Agent before the first installation, upgrades are installed over the previous version.
+// Local packages are installed over the installed Agent.
+func (h *HostAgent) ensureUninstalledBeforeInstall(version agentparams.PackageVersion, baseOpts ...pulumi.ResourceOption) ([]pulumi.ResourceOption, error) {
+	if version.LocalPath != "" {
+		return baseOpts, nil
+	}
+	uninstallCmd, err := h.manager.ensureAgentUninstalled(version, baseOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return utils.MergeOptions(baseOpts, utils.PulumiDependsOn(uninstallCmd)), nil
+}
+
+func (h *HostAgent) directInstallInstallation(env config.Env, params *agentparams.Params, version agentparams.PackageVersion, stage int, baseOpts ...pulumi.ResourceOption) (command.Command, error) {
+	packagePath, err := GetPackagePath(version.LocalPath, h.Host.OS.Descriptor().Flavor, version.Flavor, h.Host.OS.Descriptor().Architecture, env.PipelineID())
 	if err != nil {
 		return nil, err
 	}
 
 	env.Ctx().Log.Info(fmt.Sprintf("Found local package to install %s", packagePath), nil)
-	uploadCmd, err := h.Host.OS.FileManager().CopyToRemoteFile("copy-agent-package", pulumi.String(packagePath), pulumi.String("./"), baseOpts...)
+	uploadCmd, err := h.Host.OS.FileManager().CopyToRemoteFile(upgradeStageName("copy-agent-package", stage), pulumi.String(packagePath), pulumi.String("./"), baseOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	installCmd, err := h.manager.directInstallCommand(env, path.Base(packagePath), params.Version, params.AdditionalInstallParameters, utils.MergeOptions(baseOpts, utils.PulumiDependsOn(uploadCmd))...)
+	installCmd, err := h.manager.directInstallCommand(env, path.Base(packagePath), version, params.AdditionalInstallParameters, params.AdditionalSecretInstallParameters, upgradeStageTransformer(stage), utils.MergeOptions(baseOpts, utils.PulumiDependsOn(uploadCmd))...)
 	if err != nil {
 		return nil, err
 	}
 	return installCmd, nil
 }
 
+// installVersion installs the given version of the Agent, stage is its index in the upgrade path, 0 for the first installation
+func (h *HostAgent) installVersion(env config.Env, params *agentparams.Params, version agentparams.PackageVersion, stage int, baseOpts ...pulumi.ResourceOption) (command.Command, error) {
+	if version.LocalPath != "" {
+		return h.directInstallInstallation(env, params, version, stage, baseOpts...)
+	}
+	if params.PackageRepository != nil {
+		return h.repositoryInstallation(params, version, stage, baseOpts...)
+	}
+	return h.installScriptInstallation(env, params, version, stage, baseOpts...)
+}
+
 func (h *HostAgent) installAgent(env config.Env, params *agentparams.Params, baseOpts ...pulumi.ResourceOption) error {
-	var installCmd pulumi.Resource
-	var err error
-	if params.Version.LocalPath != "" {
-		installCmd, err = h.directInstallInstallation(env, params, baseOpts...)
+	if len(params.MSITransforms) > 0 {
+		uploadCmds, err := h.uploadMSITransforms(params, baseOpts...)
 		if err != nil {
 			return err
 		}
-	} else {
-		installCmd, err = h.installScriptInstallation(env, params, baseOpts...)
+		baseOpts = utils.MergeOptions(baseOpts, utils.PulumiDependsOn(uploadCmds...))
+	}
+
+	baseOpts, err := h.ensureUninstalledBeforeInstall(params.Version, baseOpts...)
+	if err != nil {
+		return err
+	}
+
+	// Collect the agent logs before it is uninstalled, the collector is created before the installation so that the logs
+	// of a failed installation are collected too
+	collector, err := h.Host.NewDiagnosticsCollector(env, diagnostics.AgentBundle, baseOpts...)
+	if err != nil {
+		return err
+	}
+	if collector != nil {
+		baseOpts = utils.MergeOptions(baseOpts, utils.PulumiDependsOn(collector))
+	}
+
+	installCmd, err := h.installVersion(env, params, params.Version, 0, baseOpts...)
+	if err != nil {
+		return err
+	}
+
+	var installedCmd pulumi.Resource = installCmd
+	if len(params.MSIMaintenance) > 0 {
+		installedCmd, err = h.runMSIMaintenance(params, installCmd, baseOpts...)
 		if err != nil {
 			return err
 		}
 	}
 
-	afterInstallOpts := utils.MergeOptions(baseOpts, utils.PulumiDependsOn(installCmd))
+	afterInstallOpts := utils.MergeOptions(baseOpts, utils.PulumiDependsOn(installedCmd))
+
 	configFiles := make(map[string]pulumi.StringInput)
+	configured := []pulumi.Resource{installedCmd}
+
+	if params.SecretsBackend != nil {
+		buildCmd, err := h.installSecretBackend(params, afterInstallOpts...)
+		if err != nil {
+			return err
+		}
+		if buildCmd != nil {
+			configured = append(configured, buildCmd)
+		}
+	}
 
 	// Update core Agent
-	_, content, err := h.updateCoreAgentConfig(env, "datadog.yaml", pulumi.String(params.AgentConfig), params.ExtraAgentConfig, params.SkipAPIKeyInConfig, afterInstallOpts...)
+	var validate func(configPath, content string) error
+	if params.CheckConfigTypos {
+		validate = func(configPath, content string) error {
+			return configschema.Validate(configSchemaVersion(params.Version), configPath, content)
+		}
+	}
+
+	coreConfigCmd, content, err := h.updateCoreAgentConfig(env, "datadog.yaml", pulumi.String(params.AgentConfig), params.ExtraAgentConfig, params.SkipAPIKeyInConfig, validate, afterInstallOpts...)
 	if err != nil {
 		return err
 	}
 	configFiles["datadog.yaml"] = content
+	configured = append(configured, coreConfigCmd)
 
 	// Update other Agents
 	for _, input := range []struct{ path, content string }{
 		{"system-probe.yaml", params.SystemProbeConfig},
 		{"security-agent.yaml", params.SecurityAgentConfig},
 	} {
+		if validate != nil {
+			if err := validate(input.path, input.content); err != nil {
+				return err
+			}
+		}
 		contentPulumiStr := pulumi.String(input.content)
-		_, err := h.updateConfig(input.path, contentPulumiStr, afterInstallOpts...)
+		configCmd, err := h.updateConfig(input.path, contentPulumiStr, afterInstallOpts...)
 		if err != nil {
 			return err
 		}
 
 		configFiles[input.path] = contentPulumiStr
+		configured = append(configured, configCmd)
 	}
 
-	_, intgHash, err := h.installIntegrationConfigsAndFiles(params.Integrations, params.Files, afterInstallOpts...)
+	if validate != nil {
+		for configPath, integration := range params.Integrations {
+			if err := validate(configPath, integration.Content); err != nil {
+				return err
+			}
+		}
+	}
+
+	intgCmds, intgHash, err := h.installIntegrationConfigsAndFiles(params.Integrations, params.Files, afterInstallOpts...)
 	if err != nil {
 		return err
 	}
+	configured = append(configured, intgCmds...)
+	configTriggers := pulumi.Array{configFiles["datadog.yaml"], configFiles["system-probe.yaml"], configFiles["security-agent.yaml"], pulumi.String(intgHash)}
 
 	// Restart the agent when the HostInstall itself is done, which is normally when all children are done
 	// Behind the scene `DependOn(h)` is transformed into `DependOn(<children>)`, the ComponentResource is skipped in the process.
@@ -163,16 +258,156 @@ func (h *HostAgent) installAgent(env config.Env, params *agentparams.Params, bas
 	// Then the `Delete` of `restartAgentServices` is done, which is not waiting for the `Delete` of the integration as the dependency on `Delete` is in reverse order.
 	//
 	// For this reason we have another `restartAgentServices` in `installIntegrationConfigsAndFiles` that is triggered when an integration is deleted.
-	_, err = h.manager.restartAgentServices(
+	//
+	// The children of `h` are only known once they are all created, the upgrade path depends on the restart so it lists the resources to wait for instead.
+	restartDeps := utils.PulumiDependsOn(h)
+	if len(params.UpgradePath) > 0 || params.HealthTimeout > 0 {
+		restartDeps = utils.PulumiDependsOn(configured...)
+	}
+	restartCmd, err := h.restartAgent(versionTriggers(configTriggers, params.Version), 0, restartDeps)
+	if err != nil {
+		return err
+	}
+
+	var lastCmd pulumi.Resource = restartCmd
+	lastTriggers := versionTriggers(configTriggers, params.Version)
+	if len(params.UpgradePath) == 0 {
+		h.InstalledVersions = pulumi.StringArray{}.ToStringArrayOutput()
+	} else {
+		lastCmd, err = h.upgradeAgent(env, params, configTriggers, restartCmd, baseOpts...)
+		if err != nil {
+			return err
+		}
+		lastTriggers = versionTriggers(configTriggers, params.UpgradePath[len(params.UpgradePath)-1])
+	}
+
+	return h.checkAgentHealth(params.HealthTimeout, lastTriggers, lastCmd, baseOpts...)
+}
+
+// uploadMSITransforms uploads the MSI transforms to the host and adds them to the install parameters
+func (h *HostAgent) uploadMSITransforms(params *agentparams.Params, opts ...pulumi.ResourceOption) ([]pulumi.Resource, error) {
+	if h.Host.OS.Descriptor().Family() != os.WindowsFamily {
+		return nil, fmt.Errorf("MSI transforms are only supported on Windows")
+	}
+
+	uploadCmds := make([]pulumi.Resource, 0, len(params.MSITransforms))
+	remotePaths := make([]string, 0, len(params.MSITransforms))
+	for _, localPath := range params.MSITransforms {
+		remotePath := `C:\` + filepath.Base(localPath)
+		uploadCmd, err := h.Host.OS.FileManager().CopyFile(h.namer.ResourceName("copy-msi-transform", filepath.Base(localPath)), pulumi.String(localPath), pulumi.String(remotePath), opts...)
+		if err != nil {
+			return nil, err
+		}
+		uploadCmds = append(uploadCmds, uploadCmd)
+		remotePaths = append(remotePaths, remotePath)
+	}
+	transforms, err := msi.NewInstallParams(msi.WithTransforms(remotePaths...))
+	if err != nil {
+		return nil, err
+	}
+	params.AdditionalInstallParameters = append(params.AdditionalInstallParameters, transforms...)
+	return uploadCmds, nil
+}
+
+// runMSIMaintenance runs the MSI maintenance operations in order after the installation, they are run again when the installed version changes.
+// The last command is returned.
+func (h *HostAgent) runMSIMaintenance(params *agentparams.Params, installCmd command.Command, opts ...pulumi.ResourceOption) (pulumi.Resource, error) {
+	var previous pulumi.Resource = installCmd
+	for i, operation := range params.MSIMaintenance {
+		triggers := versionTriggers(pulumi.Array{pulumi.String(strings.Join(operation.Parameters, " ")), operation.SecretParameters}, params.Version)
+		cmd, err := h.manager.maintenanceCommand(h.namer.ResourceName("msi-"+string(operation.Kind), strconv.Itoa(i)), operation, withTriggers(triggers), utils.MergeOptions(opts, utils.PulumiDependsOn(previous))...)
+		if err != nil {
+			return nil, err
+		}
+		previous = cmd
+	}
+	return previous, nil
+}
+
+// upgradeAgent installs the versions of the upgrade path in sequence after the first installation, and records the version installed at each stage.
+// The configuration written after the first installation is kept by the upgrades. The last command of the upgrade path is returned.
+func (h *HostAgent) upgradeAgent(env config.Env, params *agentparams.Params, configTriggers pulumi.Array, restartCmd command.Command, baseOpts ...pulumi.ResourceOption) (pulumi.Resource, error) {
+	var previous pulumi.Resource = restartCmd
+	installedVersions := pulumi.StringArray{}
+	for stage, version := range append([]agentparams.PackageVersion{params.Version}, params.UpgradePath...) {
+		triggers := versionTriggers(configTriggers, version)
+		if stage > 0 {
+			installCmd, err := h.installVersion(env, params, version, stage, utils.MergeOptions(baseOpts, utils.PulumiDependsOn(previous))...)
+			if err != nil {
+				return nil, err
+			}
+			previous, err = h.restartAgent(triggers, stage, utils.PulumiDependsOn(installCmd))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// The health of the last version is checked with the status, see checkAgentHealth
+		if params.HealthTimeout > 0 && stage < len(params.UpgradePath) {
+			healthCmd, err := h.manager.waitForHealthy(upgradeStageName(h.namer.ResourceName("wait-agent-healthy"), stage), params.HealthTimeout, withTriggers(triggers), utils.MergeOptions(baseOpts, utils.PulumiDependsOn(previous))...)
+			if err != nil {
+				return nil, err
+			}
+			previous = healthCmd
+		}
+
+		versionCmd, err := h.manager.runAgentCommand(upgradeStageName(h.namer.ResourceName("agent-version"), stage), "version --no-color", withTriggers(triggers), utils.MergeOptions(baseOpts, utils.PulumiDependsOn(previous))...)
+		if err != nil {
+			return nil, err
+		}
+		installedVersions = append(installedVersions, versionCmd.StdoutOutput().ApplyT(parseAgentVersion).(pulumi.StringOutput))
+		previous = versionCmd
+	}
+
+	h.InstalledVersions = installedVersions.ToStringArrayOutput()
+	return previous, nil
+}
+
+// versionTriggers returns the triggers of the commands run again when the configuration or the installed version change
+func versionTriggers(configTriggers pulumi.Array, version agentparams.PackageVersion) pulumi.Array {
+	triggers := append(pulumi.Array{}, configTriggers...)
+	return append(triggers, pulumi.String(version.Major), pulumi.String(version.Minor), pulumi.String(version.PipelineID), pulumi.String(version.Flavor), pulumi.String(version.Channel), pulumi.String(version.LocalPath))
+}
+
+// restartAgent restarts the agent when the triggers change
+func (h *HostAgent) restartAgent(triggers pulumi.Array, stage int, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return h.manager.restartAgentServices(
 		// Transformer used to add triggers to the restart command
 		func(name string, cmdArgs command.RunnerCommandArgs) (string, command.RunnerCommandArgs) {
 			args := *cmdArgs.Arguments()
-			args.Triggers = pulumi.Array{configFiles["datadog.yaml"], configFiles["system-probe.yaml"], configFiles["security-agent.yaml"], pulumi.String(intgHash), pulumi.String(params.Version.Major), pulumi.String(params.Version.Minor), pulumi.String(params.Version.PipelineID), pulumi.String(params.Version.Flavor), pulumi.String(params.Version.Channel)}
-			return name, &args
+			args.Triggers = triggers
+			return upgradeStageName(name, stage), &args
 		},
-		utils.PulumiDependsOn(h),
+		opts...,
 	)
-	return err
+}
+
+// agentVersionRegex matches the output of `agent version`, for example `Agent 7.50.0 - Commit: 1234567 - Serialization version: v5.0.100 - Go version: go1.21.5`
+var agentVersionRegex = regexp.MustCompile(`Agent ([^ ]+)`)
+
+func parseAgentVersion(output string) (string, error) {
+	match := agentVersionRegex.FindStringSubmatch(output)
+	if match == nil {
+		return "", fmt.Errorf("cannot parse the version of the Agent from %q", output)
+	}
+	return match[1], nil
+}
+
+// upgradeStageName suffixes the name of the resources of the upgrade path, the first installation keeps the usual names
+func upgradeStageName(name string, stage int) string {
+	if stage == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s-upgrade-%d", name, stage)
+}
+
+func upgradeStageTransformer(stage int) command.Transformer {
+	if stage == 0 {
+		return nil
+	}
+	return func(name string, cmdArgs command.RunnerCommandArgs) (string, command.RunnerCommandArgs) {
+		return upgradeStageName(name, stage), cmdArgs
+	}
 }
 
 func (h *HostAgent) updateCoreAgentConfig(
@@ -181,6 +416,7 @@ func (h *HostAgent) updateCoreAgentConfig(
 	configContent pulumi.StringInput,
 	extraAgentConfig []pulumi.StringInput,
 	skipAPIKeyInConfig bool,
+	validate func(configPath, content string) error,
 	opts ...pulumi.ResourceOption,
 ) (pulumi.Resource, pulumi.StringInput, error) {
 	var convertedArgs []interface{}
@@ -214,6 +450,9 @@ func (h *HostAgent) updateCoreAgentConfig(
 			}
 		}
 
+		if validate != nil {
+			err = validate(configPath, baseConfig)
+		}
 		return baseConfig, err
 	}).(pulumi.StringOutput)
 
@@ -221,6 +460,14 @@ func (h *HostAgent) updateCoreAgentConfig(
 	return cmd, configContent, err
 }
 
+// configSchemaVersion returns the version of the configuration schema of the agent, see configschema.Validate
+func configSchemaVersion(version agentparams.PackageVersion) string {
+	if version.Minor == "" || version.PipelineID != "" || version.LocalPath != "" {
+		return version.Major
+	}
+	return version.Major + "." + version.Minor
+}
+
 func (h *HostAgent) updateConfig(
 	configPath string,
 	configContent pulumi.StringInput,
@@ -238,22 +485,31 @@ func (h *HostAgent) updateConfig(
 	return copyCmd, nil
 }
 
+// integrationsHash returns a hash of the integration configs and files, independent of the iteration order of the maps
+func integrationsHash(integrations map[string]*agentparams.FileDefinition, files map[string]*agentparams.FileDefinition) string {
+	var parts []string
+	for _, definitions := range []map[string]*agentparams.FileDefinition{integrations, files} {
+		paths := make([]string, 0, len(definitions))
+		for filePath := range definitions {
+			paths = append(paths, filePath)
+		}
+		sort.Strings(paths)
+		for _, filePath := range paths {
+			parts = append(parts, filePath, definitions[filePath].Content)
+		}
+	}
+	return utils.StrHash(parts...)
+}
+
 func (h *HostAgent) installIntegrationConfigsAndFiles(
 	integrations map[string]*agentparams.FileDefinition,
 	files map[string]*agentparams.FileDefinition,
 	opts ...pulumi.ResourceOption,
 ) ([]pulumi.Resource, string, error) {
 	allCommands := make([]pulumi.Resource, 0)
-	var parts []string
 
 	// Build hash beforehand as we need to pass it to the restart command
-	for filePath, fileDef := range integrations {
-		parts = append(parts, filePath, fileDef.Content)
-	}
-	for fullPath, fileDef := range files {
-		parts = append(parts, fullPath, fileDef.Content)
-	}
-	hash := utils.StrHash(parts...)
+	hash := integrationsHash(integrations, files)
 
 	// Restart the agent when an integration is removed
 	// See longer comment in `installAgent` for more details
@@ -278,7 +534,7 @@ func (h *HostAgent) installIntegrationConfigsAndFiles(
 		configFolder := h.manager.getAgentConfigFolder()
 		fullPath := path.Join(configFolder, filePath)
 
-		file, err := h.writeFileDefinition(fullPath, fileDef.Content, fileDef.UseSudo, fileDef.Permissions, opts...)
+		file, err := h.writeFileDefinition(fullPath, fileDef.Content, fileDef.UseSudo, h.filePermissions(fileDef), opts...)
 		if err != nil {
 			return nil, "", err
 		}
@@ -290,7 +546,7 @@ func (h *HostAgent) installIntegrationConfigsAndFiles(
 			return nil, "", fmt.Errorf("failed to write file: \"%s\" is not an absolute filepath", fullPath)
 		}
 
-		cmd, err := h.writeFileDefinition(fullPath, fileDef.Content, fileDef.UseSudo, fileDef.Permissions, opts...)
+		cmd, err := h.writeFileDefinition(fullPath, fileDef.Content, fileDef.UseSudo, h.filePermissions(fileDef), opts...)
 		if err != nil {
 			return nil, "", err
 		}
@@ -300,6 +556,14 @@ func (h *HostAgent) installIntegrationConfigsAndFiles(
 	return allCommands, hash, nil
 }
 
+// filePermissions returns the permissions of the file, the Agent ones if it is owned by the Agent and does not set any
+func (h *HostAgent) filePermissions(fileDef *agentparams.FileDefinition) option.Option[perms.FilePermissions] {
+	if _, found := fileDef.Permissions.Get(); found || !fileDef.AgentOwned {
+		return fileDef.Permissions
+	}
+	return h.manager.getAgentFilePermissions()
+}
+
 func (h *HostAgent) writeFileDefinition(
 	fullPath string,
 	content string,
@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/DataDog/test-infra-definitions/common/utils"
+	"github.com/DataDog/test-infra-definitions/components/command"
+)
+
+// AgentStatus is the part of the status of the Agent exported once it is healthy
+type AgentStatus struct {
+	Version       string   `json:"version"`
+	Hostname      string   `json:"hostname"`
+	RunningChecks []string `json:"runningChecks"`
+}
+
+// unixWaitForHealthyScript returns a script waiting for `<agent> health` to succeed, it prints the output of logs to stderr if it does not before the timeout
+func unixWaitForHealthyScript(agent string, logs string, timeout time.Duration) string {
+	return fmt.Sprintf(`deadline=$(($(date +%%s) + %[1]d))
+until %[2]s health; do
+	if [ "$(date +%%s)" -ge "$deadline" ]; then
+		echo "the Agent is not healthy after %[1]d seconds" >&2
+		{ %[3]s; } >&2
+		exit 1
+	fi
+	sleep 5
+done`, int(timeout.Seconds()), agent, logs)
+}
+
+// parseAgentStatus parses the output of `agent status --json`, which may be preceded by log lines
+func parseAgentStatus(output string) (map[string]interface{}, error) {
+	start := strings.Index(output, "{")
+	if start < 0 {
+		return nil, fmt.Errorf("cannot find the status of the Agent in %q", output)
+	}
+
+	var status struct {
+		Version  string `json:"version"`
+		Metadata struct {
+			Meta struct {
+				Hostname string `json:"hostname"`
+			} `json:"meta"`
+		} `json:"metadata"`
+		HostInfo struct {
+			Hostname string `json:"hostname"`
+		} `json:"hostinfo"`
+		RunnerStats struct {
+			Checks map[string]json.RawMessage `json:"Checks"`
+		} `json:"runnerStats"`
+	}
+	if err := json.NewDecoder(strings.NewReader(output[start:])).Decode(&status); err != nil {
+		return nil, fmt.Errorf("cannot parse the status of the Agent: %w", err)
+	}
+
+	hostname := status.Metadata.Meta.Hostname
+	if hostname == "" {
+		hostname = status.HostInfo.Hostname
+	}
+	checks := make([]interface{}, 0, len(status.RunnerStats.Checks))
+	names := make([]string, 0, len(status.RunnerStats.Checks))
+	for name := range status.RunnerStats.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		checks = append(checks, name)
+	}
+
+	return map[string]interface{}{
+		"version":       status.Version,
+		"hostname":      hostname,
+		"runningChecks": checks,
+	}, nil
+}
+
+// withTriggers returns a transformer setting the triggers of the command, to run it again when they change
+func withTriggers(triggers pulumi.ArrayInput) command.Transformer {
+	return func(name string, cmdArgs command.RunnerCommandArgs) (string, command.RunnerCommandArgs) {
+		args := *cmdArgs.Arguments()
+		args.Triggers = triggers
+		return name, &args
+	}
+}
+
+// checkAgentHealth waits for the Agent to be healthy after the previous resource and records its status, it is run again when triggers change
+func (h *HostAgent) checkAgentHealth(timeout time.Duration, triggers pulumi.Array, previous pulumi.Resource, opts ...pulumi.ResourceOption) error {
+	if timeout == 0 {
+		h.Status = pulumi.Map{}.ToMapOutput()
+		return nil
+	}
+
+	healthCmd, err := h.manager.waitForHealthy(h.namer.ResourceName("agent-healthy"), timeout, withTriggers(triggers), utils.MergeOptions(opts, utils.PulumiDependsOn(previous))...)
+	if err != nil {
+		return err
+	}
+
+	statusCmd, err := h.manager.runAgentCommand(h.namer.ResourceName("agent-status"), "status --json", withTriggers(triggers), utils.MergeOptions(opts, utils.PulumiDependsOn(healthCmd))...)
+	if err != nil {
+		return err
+	}
+	h.Status = statusCmd.StdoutOutput().ApplyT(parseAgentStatus).(pulumi.MapOutput)
+	return nil
+}
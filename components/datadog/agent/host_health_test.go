@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAgentStatus(t *testing.T) {
+	status, err := parseAgentStatus(`Getting the status from the agent.
+{"version": "7.60.0", "metadata": {"meta": {"hostname": "i-0123"}}, "hostinfo": {"hostname": "ip-10-1-1-1"}, "runnerStats": {"Checks": {"memory": {}, "cpu": {}}}}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"version":       "7.60.0",
+		"hostname":      "i-0123",
+		"runningChecks": []interface{}{"cpu", "memory"},
+	}, status)
+
+	_, err = parseAgentStatus("Error: unable to read authentication token")
+	assert.Error(t, err)
+}
+
+func TestUnixWaitForHealthyScript(t *testing.T) {
+	output, err := exec.Command("sh", "-c", unixWaitForHealthyScript("true", "echo logs", time.Second)).CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	output, err = exec.Command("sh", "-c", unixWaitForHealthyScript("false", "echo logs", 0)).CombinedOutput()
+	require.Error(t, err)
+	assert.Equal(t, "the Agent is not healthy after 0 seconds\nlogs\n", string(output))
+}
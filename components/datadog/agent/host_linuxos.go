@@ -3,10 +3,17 @@ package agent
 import (
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/option"
+	"github.com/alessio/shellescape"
 
 	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/common/utils"
 	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams"
+	perms "github.com/DataDog/test-infra-definitions/components/datadog/agentparams/filepermissions"
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams/msi"
 	"github.com/DataDog/test-infra-definitions/components/os"
 	remoteComp "github.com/DataDog/test-infra-definitions/components/remote"
 
@@ -21,11 +28,34 @@ func newLinuxManager(host *remoteComp.Host) agentOSManager {
 	return &agentLinuxManager{targetOS: host.OS}
 }
 
-func (am *agentLinuxManager) directInstallCommand(_ config.Env, packagePath string, _ agentparams.PackageVersion, _ []string, opts ...pulumi.ResourceOption) (command.Command, error) {
-	return am.targetOS.PackageManager().Ensure("./"+packagePath, nil, "", os.AllowUnsignedPackages(true), os.WithPulumiResourceOptions(opts...))
+func (am *agentLinuxManager) directInstallCommand(_ config.Env, packagePath string, _ agentparams.PackageVersion, _ []string, _ pulumi.StringArray, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return am.targetOS.PackageManager().Ensure("./"+packagePath, transform, "", os.AllowUnsignedPackages(true), os.WithPulumiResourceOptions(opts...))
+}
+
+// repositoryInstallCommand adds the Agent repository to the package manager and installs the Agent package from it
+func (am *agentLinuxManager) repositoryInstallCommand(version agentparams.PackageVersion, repository agentparams.PackageRepository, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	packageRepository, pinnedVersion, err := agentPackageRepository(version, repository, am.targetOS.Descriptor())
+	if err != nil {
+		return nil, err
+	}
+
+	addRepositoryCmd, err := am.targetOS.PackageManager().AddRepository(packageRepository, transform, os.WithPulumiResourceOptions(opts...))
+	if err != nil {
+		return nil, err
+	}
+
+	packageOpts := []os.PackageManagerOption{os.WithPulumiResourceOptions(utils.MergeOptions(opts, utils.PulumiDependsOn(addRepositoryCmd))...)}
+	if pinnedVersion != "" {
+		packageOpts = append(packageOpts, os.WithVersion(pinnedVersion))
+	}
+	flavor := version.Flavor
+	if flavor == "" {
+		flavor = agentparams.DefaultFlavor
+	}
+	return am.targetOS.PackageManager().Ensure(flavor, transform, "", packageOpts...)
 }
 
-func (am *agentLinuxManager) getInstallCommand(version agentparams.PackageVersion, apiKey pulumi.StringInput, _ []string) (pulumi.StringOutput, error) {
+func (am *agentLinuxManager) getInstallCommand(version agentparams.PackageVersion, apiKey pulumi.StringInput, _ []string, _ pulumi.StringArray) (pulumi.StringOutput, error) {
 	var commandLine string
 	testEnvVars := []string{}
 
@@ -92,3 +122,47 @@ func (am *agentLinuxManager) ensureAgentUninstalled(version agentparams.PackageV
 	}
 	return uninstallCmd, nil
 }
+
+func (am *agentLinuxManager) waitForHealthy(name string, timeout time.Duration, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	script := unixWaitForHealthyScript("datadog-agent", "journalctl -u datadog-agent --no-pager -n 50; tail -n 100 /var/log/datadog/agent.log", timeout)
+	return runTransformed(am.targetOS.Runner(), name, &command.Args{
+		Create: pulumi.String("sh -c " + shellescape.Quote(script)),
+		Sudo:   true,
+	}, transform, opts...)
+}
+
+func (am *agentLinuxManager) runAgentCommand(name string, agentArgs string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runTransformed(am.targetOS.Runner(), name, &command.Args{
+		Create: pulumi.String("datadog-agent " + agentArgs),
+		Sudo:   true,
+	}, transform, opts...)
+}
+
+// getSecretBackend returns a script printing the secrets, owned by dd-agent and only accessible by it as required by the Agent
+func (am *agentLinuxManager) getSecretBackend(secrets map[string]string) (secretBackend, error) {
+	output, err := secretBackendOutput(secrets)
+	if err != nil {
+		return secretBackend{}, err
+	}
+
+	path := am.getAgentConfigFolder() + "/secret_backend.sh"
+	return secretBackend{
+		command: path,
+		files: map[string]*agentparams.FileDefinition{
+			path: {
+				Content:     fmt.Sprintf("#!/bin/sh\ncat > /dev/null\ncat <<'DD_SECRETS_EOF'\n%s\nDD_SECRETS_EOF\n", output),
+				UseSudo:     true,
+				Permissions: perms.NewUnixPermissions(perms.WithOwner("dd-agent"), perms.WithGroup("dd-agent"), perms.WithPermissions("700")),
+			},
+		},
+	}, nil
+}
+
+// getAgentFilePermissions returns files owned by dd-agent, as the Agent packages install the configuration
+func (am *agentLinuxManager) getAgentFilePermissions() option.Option[perms.FilePermissions] {
+	return perms.NewUnixPermissions(perms.WithOwner("dd-agent"), perms.WithGroup("dd-agent"), perms.WithPermissions("644"))
+}
+
+func (am *agentLinuxManager) maintenanceCommand(_ string, _ msi.MaintenanceOperation, _ command.Transformer, _ ...pulumi.ResourceOption) (command.Command, error) {
+	return nil, fmt.Errorf("MSI maintenance operations are only supported on Windows")
+}
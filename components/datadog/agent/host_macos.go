@@ -3,15 +3,23 @@ package agent
 import (
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/option"
+	"github.com/alessio/shellescape"
 
 	"github.com/DataDog/test-infra-definitions/common/config"
 	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams"
+	perms "github.com/DataDog/test-infra-definitions/components/datadog/agentparams/filepermissions"
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams/msi"
 	remoteComp "github.com/DataDog/test-infra-definitions/components/remote"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
+const macOSAgentBinary = "/opt/datadog-agent/bin/agent/agent"
+
 type agentMacOSManager struct {
 	host *remoteComp.Host
 }
@@ -21,13 +29,17 @@ func newMacOSManager(host *remoteComp.Host) agentOSManager {
 }
 
 // directInstallCommand expects a locally provided .dmg or .pkg uploaded to the host; it will install it with installer
-func (am *agentMacOSManager) directInstallCommand(_ config.Env, _ string, _ agentparams.PackageVersion, _ []string, _ ...pulumi.ResourceOption) (command.Command, error) {
+func (am *agentMacOSManager) directInstallCommand(_ config.Env, _ string, _ agentparams.PackageVersion, _ []string, _ pulumi.StringArray, _ command.Transformer, _ ...pulumi.ResourceOption) (command.Command, error) {
 	// Unsupported for now.
 	return nil, fmt.Errorf("installing directly from a dmg without the install script requires way too many step that would imply duplicating the install script code in there")
 }
 
+func (am *agentMacOSManager) repositoryInstallCommand(_ agentparams.PackageVersion, _ agentparams.PackageRepository, _ command.Transformer, _ ...pulumi.ResourceOption) (command.Command, error) {
+	return nil, fmt.Errorf("installing the Agent from a package repository is only supported on Linux")
+}
+
 // getInstallCommand downloads appropriate pkg and installs it
-func (am *agentMacOSManager) getInstallCommand(version agentparams.PackageVersion, apiKey pulumi.StringInput, _ []string) (pulumi.StringOutput, error) {
+func (am *agentMacOSManager) getInstallCommand(version agentparams.PackageVersion, apiKey pulumi.StringInput, _ []string, _ pulumi.StringArray) (pulumi.StringOutput, error) {
 	// For macOS, use the official install script which supports DD_API_KEY and version envs,
 	// mirroring Linux flow but using the macOS path. The script detects OS and uses pkg.
 	// If pipeline is specified, we cannot use public script; we assume local package will be provided in that case.
@@ -76,3 +88,31 @@ func (am *agentMacOSManager) ensureAgentUninstalled(_ agentparams.PackageVersion
 		Create: pulumi.String("true"),
 	}, opts...)
 }
+
+func (am *agentMacOSManager) waitForHealthy(name string, timeout time.Duration, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	script := unixWaitForHealthyScript(macOSAgentBinary, "tail -n 100 /opt/datadog-agent/logs/agent.log", timeout)
+	return runTransformed(am.host.OS.Runner(), name, &command.Args{
+		Create: pulumi.String("sh -c " + shellescape.Quote(script)),
+		Sudo:   true,
+	}, transform, opts...)
+}
+
+func (am *agentMacOSManager) runAgentCommand(name string, agentArgs string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runTransformed(am.host.OS.Runner(), name, &command.Args{
+		Create: pulumi.String(macOSAgentBinary + " " + agentArgs),
+		Sudo:   true,
+	}, transform, opts...)
+}
+
+func (am *agentMacOSManager) getSecretBackend(_ map[string]string) (secretBackend, error) {
+	return secretBackend{}, fmt.Errorf("the secrets backend is not supported on macOS")
+}
+
+// getAgentFilePermissions returns no permissions, the files written with sudo are readable by the user running the Agent
+func (am *agentMacOSManager) getAgentFilePermissions() option.Option[perms.FilePermissions] {
+	return option.None[perms.FilePermissions]()
+}
+
+func (am *agentMacOSManager) maintenanceCommand(_ string, _ msi.MaintenanceOperation, _ command.Transformer, _ ...pulumi.ResourceOption) (command.Command, error) {
+	return nil, fmt.Errorf("MSI maintenance operations are only supported on Windows")
+}
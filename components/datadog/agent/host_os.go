@@ -2,9 +2,14 @@ package agent
 
 import (
 	"fmt"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/option"
 
 	"github.com/DataDog/test-infra-definitions/common/config"
 	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams"
+	perms "github.com/DataDog/test-infra-definitions/components/datadog/agentparams/filepermissions"
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams/msi"
 
 	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/DataDog/test-infra-definitions/components/os"
@@ -15,11 +20,21 @@ import (
 
 // internal interface to be able to provide the different OS-specific commands
 type agentOSManager interface {
-	directInstallCommand(env config.Env, packagePath string, version agentparams.PackageVersion, additionalInstallParameters []string, opts ...pulumi.ResourceOption) (command.Command, error)
-	getInstallCommand(version agentparams.PackageVersion, apiKey pulumi.StringInput, additionalInstallParameters []string) (pulumi.StringOutput, error)
+	directInstallCommand(env config.Env, packagePath string, version agentparams.PackageVersion, additionalInstallParameters []string, secretInstallParameters pulumi.StringArray, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+	repositoryInstallCommand(version agentparams.PackageVersion, repository agentparams.PackageRepository, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+	getInstallCommand(version agentparams.PackageVersion, apiKey pulumi.StringInput, additionalInstallParameters []string, secretInstallParameters pulumi.StringArray) (pulumi.StringOutput, error)
 	getAgentConfigFolder() string
 	restartAgentServices(transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
 	ensureAgentUninstalled(version agentparams.PackageVersion, opts ...pulumi.ResourceOption) (command.Command, error)
+	// waitForHealthy waits up to timeout for the Agent to be healthy, the command fails with the logs of the Agent otherwise
+	waitForHealthy(name string, timeout time.Duration, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+	// runAgentCommand runs the agent binary with the given arguments, for instance `version`
+	runAgentCommand(name string, agentArgs string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+	getSecretBackend(secrets map[string]string) (secretBackend, error)
+	// getAgentFilePermissions returns the permissions of the files read by the Agent, such as integrations and custom checks
+	getAgentFilePermissions() option.Option[perms.FilePermissions]
+	// maintenanceCommand runs an MSI maintenance operation, such as a repair, on the installed Agent
+	maintenanceCommand(name string, operation msi.MaintenanceOperation, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
 }
 
 func getOSManager(host *remoteComp.Host) agentOSManager {
@@ -36,3 +51,11 @@ func getOSManager(host *remoteComp.Host) agentOSManager {
 		panic(fmt.Sprintf("unsupported OS: %v", host.OS.Descriptor().Family()))
 	}
 }
+
+// runTransformed runs a command after applying the transform, if any
+func runTransformed(runner command.Runner, name string, args command.RunnerCommandArgs, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	if transform != nil {
+		name, args = transform(name, args)
+	}
+	return runner.Command(name, args, opts...)
+}
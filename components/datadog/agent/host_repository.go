@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams"
+	"github.com/DataDog/test-infra-definitions/components/os"
+)
+
+// Keys signing the Datadog repositories, the pipeline repositories are signed with the test keys
+const (
+	aptKeyPath = "DATADOG_APT_KEY_CURRENT.public"
+	rpmKeyPath = "DATADOG_RPM_KEY_CURRENT.public"
+)
+
+type repositoryKind int
+
+const (
+	aptRepository repositoryKind = iota
+	yumRepository
+	suseRepository
+)
+
+func getRepositoryKind(flavor os.Flavor) (repositoryKind, error) {
+	switch flavor {
+	case os.Debian, os.Ubuntu:
+		return aptRepository, nil
+	case os.AmazonLinux, os.AmazonLinuxECS, os.CentOS, os.Fedora, os.RedHat, os.RockyLinux, os.AlmaLinux, os.OracleLinux:
+		return yumRepository, nil
+	case os.Suse, os.OpenSuseLeap:
+		return suseRepository, nil
+	default:
+		return 0, fmt.Errorf("installing the Agent from a package repository is not supported on %s", flavor)
+	}
+}
+
+// agentPackageRepository returns the repository of the given version of the Agent and the version of the package to pin, empty for the latest one
+func agentPackageRepository(version agentparams.PackageVersion, repository agentparams.PackageRepository, descriptor os.Descriptor) (os.PackageRepository, string, error) {
+	kind, err := getRepositoryKind(descriptor.Flavor)
+	if err != nil {
+		return os.PackageRepository{}, "", err
+	}
+
+	channel := string(version.Channel)
+	if channel == "" {
+		channel = string(agentparams.StableChannel)
+	}
+
+	baseURL, keysURL := "https://%s.datadoghq.com", "https://keys.datadoghq.com"
+	if channel != string(agentparams.StableChannel) {
+		baseURL = "https://%s.datad0g.com"
+	}
+	if version.PipelineID != "" {
+		if repository.BaseURL != "" {
+			return os.PackageRepository{}, "", fmt.Errorf("pipeline %s cannot be installed from a mirror", version.PipelineID)
+		}
+		baseURL, keysURL = "https://%stesting.datad0g.com", "https://apttesting.datad0g.com/test-keys"
+	}
+
+	result := os.PackageRepository{Name: "datadog"}
+	var path string
+	switch kind {
+	case aptRepository:
+		baseURL = fmt.Sprintf(baseURL, "apt")
+		result.KeyURL = keysURL + "/" + aptKeyPath
+		result.Distribution = channel
+		result.Components = []string{version.Major}
+		if version.PipelineID != "" {
+			path = fmt.Sprintf("/datadog-agent/pipeline-%s-a%s", version.PipelineID, version.Major)
+			result.Distribution = "stable-" + string(descriptor.Architecture)
+		}
+	case yumRepository, suseRepository:
+		baseURL = fmt.Sprintf(baseURL, "yum")
+		result.KeyURL = keysURL + "/" + rpmKeyPath
+		path = fmt.Sprintf("/%s/%s/$basearch", channel, version.Major)
+		if version.PipelineID != "" {
+			path = fmt.Sprintf("/testing/pipeline-%[1]s-a%[2]s/%[2]s/$basearch", version.PipelineID, version.Major)
+		}
+		if kind == suseRepository {
+			path = "/suse" + path
+		}
+	}
+
+	result.URL = baseURL + path
+	if repository.BaseURL != "" {
+		result.URL = repository.BaseURL + path
+		result.KeyURL = repository.KeyURL
+	}
+
+	return result, packageVersion(version, kind), nil
+}
+
+// packageVersion returns the version of the package to pin, the Agent packages have an epoch and a release number
+func packageVersion(version agentparams.PackageVersion, kind repositoryKind) string {
+	if version.Minor == "" || version.PipelineID != "" {
+		return ""
+	}
+	packageVersion := version.Major + "." + version.Minor
+	if !strings.Contains(version.Minor, "-") {
+		packageVersion += "-1"
+	}
+	if kind == yumRepository {
+		// yum does not take the epoch in package references
+		return packageVersion
+	}
+	return "1:" + packageVersion
+}
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams"
+	"github.com/DataDog/test-infra-definitions/components/os"
+)
+
+func TestAgentPackageRepository(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    agentparams.PackageVersion
+		repository agentparams.PackageRepository
+		descriptor os.Descriptor
+		expected   os.PackageRepository
+		pin        string
+	}{
+		{
+			name:       "apt stable",
+			version:    agentparams.PackageVersion{Major: "7", Minor: "50.1", Channel: agentparams.StableChannel},
+			descriptor: os.UbuntuDefault,
+			expected:   os.PackageRepository{Name: "datadog", URL: "https://apt.datadoghq.com", KeyURL: "https://keys.datadoghq.com/DATADOG_APT_KEY_CURRENT.public", Distribution: "stable", Components: []string{"7"}},
+			pin:        "1:7.50.1-1",
+		},
+		{
+			name:       "apt pipeline",
+			version:    agentparams.PackageVersion{Major: "7", PipelineID: "123"},
+			descriptor: os.NewDescriptorWithArch(os.Debian, "12", os.ARM64Arch),
+			expected:   os.PackageRepository{Name: "datadog", URL: "https://apttesting.datad0g.com/datadog-agent/pipeline-123-a7", KeyURL: "https://apttesting.datad0g.com/test-keys/DATADOG_APT_KEY_CURRENT.public", Distribution: "stable-arm64", Components: []string{"7"}},
+		},
+		{
+			name:       "yum nightly",
+			version:    agentparams.PackageVersion{Major: "7", Channel: agentparams.NightlyChannel},
+			descriptor: os.AmazonLinux2,
+			expected:   os.PackageRepository{Name: "datadog", URL: "https://yum.datad0g.com/nightly/7/$basearch", KeyURL: "https://keys.datadoghq.com/DATADOG_RPM_KEY_CURRENT.public"},
+		},
+		{
+			name:       "yum beta",
+			version:    agentparams.PackageVersion{Major: "7", Minor: "45~rc.1", Channel: agentparams.BetaChannel},
+			descriptor: os.RedHatDefault,
+			expected:   os.PackageRepository{Name: "datadog", URL: "https://yum.datad0g.com/beta/7/$basearch", KeyURL: "https://keys.datadoghq.com/DATADOG_RPM_KEY_CURRENT.public"},
+			pin:        "7.45~rc.1-1",
+		},
+		{
+			name:       "suse mirror",
+			version:    agentparams.PackageVersion{Major: "7", Minor: "50.1-1", Channel: agentparams.StableChannel},
+			repository: agentparams.PackageRepository{BaseURL: "http://mirror:8080"},
+			descriptor: os.SuseDefault,
+			expected:   os.PackageRepository{Name: "datadog", URL: "http://mirror:8080/suse/stable/7/$basearch"},
+			pin:        "1:7.50.1-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository, pin, err := agentPackageRepository(tt.version, tt.repository, tt.descriptor)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, repository)
+			assert.Equal(t, tt.pin, pin)
+		})
+	}
+
+	_, _, err := agentPackageRepository(agentparams.PackageVersion{Major: "7"}, agentparams.PackageRepository{}, os.WindowsServerDefault)
+	assert.Error(t, err)
+	_, _, err = agentPackageRepository(agentparams.PackageVersion{Major: "7", PipelineID: "123"}, agentparams.PackageRepository{BaseURL: "http://mirror"}, os.UbuntuDefault)
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"encoding/json"
+
+	"github.com/DataDog/test-infra-definitions/common/utils"
+	"github.com/DataDog/test-infra-definitions/components/command"
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// secretBackend is the secret backend command of the Agent and the files it needs
+type secretBackend struct {
+	// command is the path of the secret_backend_command
+	command string
+	files   map[string]*agentparams.FileDefinition
+	// build is run after the installation of the Agent to create the command, empty if it is one of the files
+	build string
+	// cleanup is run when the command is deleted, it is only used with build
+	cleanup string
+}
+
+type secretResponse struct {
+	Value string  `json:"value"`
+	Error *string `json:"error"`
+}
+
+// secretBackendOutput returns the output of the secret backend command, all the secrets are returned whatever the requested handles as the Agent ignores the extra ones
+func secretBackendOutput(secrets map[string]string) (string, error) {
+	response := make(map[string]secretResponse, len(secrets))
+	for handle, value := range secrets {
+		response[handle] = secretResponse{Value: value}
+	}
+	output, err := json.Marshal(response)
+	return string(output), err
+}
+
+// installSecretBackend adds the files of the secret backend to the files written by the Agent installation and configures the Agent to use it.
+// The command building the secret backend is returned if there is one, the Agent must be restarted after it.
+func (h *HostAgent) installSecretBackend(params *agentparams.Params, opts ...pulumi.ResourceOption) (command.Command, error) {
+	backend, err := h.manager.getSecretBackend(params.SecretsBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	for path, file := range backend.files {
+		params.Files[path] = file
+	}
+	params.ExtraAgentConfig = append(params.ExtraAgentConfig, pulumi.Sprintf("secret_backend_command: '%s'", backend.command))
+
+	if backend.build == "" {
+		return nil, nil
+	}
+	return h.Host.OS.Runner().Command(
+		h.namer.ResourceName("build-secret-backend", utils.StrHash(backend.build)),
+		&command.Args{
+			Create: pulumi.String(backend.build),
+			Delete: pulumi.String(backend.cleanup),
+		}, opts...)
+}
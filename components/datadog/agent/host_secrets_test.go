@@ -0,0 +1,14 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretBackendOutput(t *testing.T) {
+	output, err := secretBackendOutput(map[string]string{"api_key": "abcdef", "password": `p"a'ss`})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"api_key": {"value": "abcdef", "error": null}, "password": {"value": "p\"a'ss", "error": null}}`, output)
+}
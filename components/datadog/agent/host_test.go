@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/test-infra-definitions/common/utils"
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams"
+)
+
+func TestIntegrationsHash(t *testing.T) {
+	integrations := map[string]*agentparams.FileDefinition{
+		"conf.d/http_check.d/conf.yaml": {Content: "instances: [{url: http://localhost}]"},
+		"conf.d/disk.d/conf.yaml":       {Content: "instances: [{}]"},
+		"conf.d/process.d/conf.yaml":    {Content: "instances: [{name: agent}]"},
+	}
+	files := map[string]*agentparams.FileDefinition{
+		"/tmp/b.txt": {Content: "b"},
+		"/tmp/a.txt": {Content: "a"},
+	}
+
+	expected := utils.StrHash(
+		"conf.d/disk.d/conf.yaml", "instances: [{}]",
+		"conf.d/http_check.d/conf.yaml", "instances: [{url: http://localhost}]",
+		"conf.d/process.d/conf.yaml", "instances: [{name: agent}]",
+		"/tmp/a.txt", "a",
+		"/tmp/b.txt", "b",
+	)
+	// Map iteration order is random, the hash must not depend on it
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, expected, integrationsHash(integrations, files))
+	}
+
+	files["/tmp/a.txt"] = &agentparams.FileDefinition{Content: "updated"}
+	assert.NotEqual(t, expected, integrationsHash(integrations, files))
+}
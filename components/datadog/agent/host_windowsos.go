@@ -10,12 +10,17 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	"golang.org/x/exp/slices"
 
+	"github.com/DataDog/datadog-agent/pkg/util/option"
+
 	"github.com/DataDog/test-infra-definitions/common/config"
 	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams"
+	perms "github.com/DataDog/test-infra-definitions/components/datadog/agentparams/filepermissions"
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams/msi"
 	remoteComp "github.com/DataDog/test-infra-definitions/components/remote"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -24,6 +29,8 @@ import (
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
+const windowsAgentBinary = `$($env:ProgramFiles)\Datadog\Datadog Agent\bin\agent.exe`
+
 type agentWindowsManager struct {
 	host *remoteComp.Host
 }
@@ -32,20 +39,29 @@ func newWindowsManager(host *remoteComp.Host) agentOSManager {
 	return &agentWindowsManager{host: host}
 }
 
-func (am *agentWindowsManager) directInstallCommand(env config.Env, packagePath string, version agentparams.PackageVersion, additionalInstallParameters []string, opts ...pulumi.ResourceOption) (command.Command, error) {
+func (am *agentWindowsManager) directInstallCommand(env config.Env, packagePath string, version agentparams.PackageVersion, additionalInstallParameters []string, secretInstallParameters pulumi.StringArray, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
 	cmd := fmt.Sprintf(`
 $ProgressPreference = 'SilentlyContinue';
 $ErrorActionPreference = 'Stop';
 `)
-	installCommandStr, err := am.getInstallPackageCommand(packagePath, version, additionalInstallParameters)
+	installCommand, err := am.getInstallPackageCommand(packagePath, version, env.AgentAPIKey(), additionalInstallParameters, secretInstallParameters)
 	if err != nil {
 		return nil, err
 	}
-	cmd += installCommandStr
-	return am.host.OS.Runner().Command("install-agent", &command.Args{Create: pulumi.Sprintf(cmd, env.AgentAPIKey())}, opts...)
+
+	cmdName := "install-agent"
+	var cmdArgs command.RunnerCommandArgs = &command.Args{Create: pulumi.Sprintf("%s%s", cmd, installCommand)}
+	if transform != nil {
+		cmdName, cmdArgs = transform(cmdName, cmdArgs)
+	}
+	return am.host.OS.Runner().Command(cmdName, cmdArgs, opts...)
+}
+
+func (am *agentWindowsManager) repositoryInstallCommand(_ agentparams.PackageVersion, _ agentparams.PackageRepository, _ command.Transformer, _ ...pulumi.ResourceOption) (command.Command, error) {
+	return nil, fmt.Errorf("installing the Agent from a package repository is only supported on Linux")
 }
 
-func (am *agentWindowsManager) getInstallCommand(version agentparams.PackageVersion, apiKey pulumi.StringInput, additionalInstallParameters []string) (pulumi.StringOutput, error) {
+func (am *agentWindowsManager) getInstallCommand(version agentparams.PackageVersion, apiKey pulumi.StringInput, additionalInstallParameters []string, secretInstallParameters pulumi.StringArray) (pulumi.StringOutput, error) {
 	url, err := getAgentURL(version)
 	if err != nil {
 		return pulumi.Sprintf(""), err
@@ -71,16 +87,17 @@ for ($i=0; $i -lt 3; $i++) {
 	}
 };
 `, url, localFilename)
-	installPackageCommandStr, err := am.getInstallPackageCommand(localFilename, version, additionalInstallParameters)
+	installPackageCommand, err := am.getInstallPackageCommand(localFilename, version, apiKey, additionalInstallParameters, secretInstallParameters)
 	if err != nil {
 		return pulumi.Sprintf(""), err
 	}
-	cmd += installPackageCommandStr
 
-	return pulumi.Sprintf(cmd, apiKey), nil
+	return pulumi.Sprintf("%s%s", cmd, installPackageCommand), nil
 }
 
-func (am *agentWindowsManager) getInstallPackageCommand(filePath string, version agentparams.PackageVersion, additionalInstallParameters []string) (string, error) {
+// getInstallPackageCommand returns the command installing the MSI, with the API key of the environment unless the install parameters set their own.
+// Parameters are only passed as arguments of pulumi.Sprintf, never in its format.
+func (am *agentWindowsManager) getInstallPackageCommand(filePath string, version agentparams.PackageVersion, apiKey pulumi.StringInput, additionalInstallParameters []string, secretInstallParameters pulumi.StringArray) (pulumi.StringOutput, error) {
 	logFilePath := "C:\\install.log"
 	logParamIdx := slices.IndexFunc(additionalInstallParameters, func(s string) bool {
 		return strings.HasPrefix(s, "/log")
@@ -91,21 +108,31 @@ func (am *agentWindowsManager) getInstallPackageCommand(filePath string, version
 		// "/log C:\mycustomlog.txt" -> "C:\mycustomlog.txt"
 		paramParts := strings.Split(additionalInstallParameters[logParamIdx], " ")
 		if len(paramParts) != 2 {
-			return "", fmt.Errorf("/log parameter was malformed, must be '/log <path_to_log_file>'")
+			return pulumi.Sprintf(""), fmt.Errorf("/log parameter was malformed, must be '/log <path_to_log_file>'")
 		}
 		logFilePath = paramParts[1]
 	}
-	cmd := ""
+	fipsCmd := ""
 	if version.Flavor == agentparams.FIPSFlavor {
-		cmd = fmt.Sprintf(`
-Set-ItemProperty -Path 'HKLM:\System\CurrentControlSet\Control\Lsa\FipsAlgorithmPolicy' -Name 'Enabled' -Value 1 -Type DWORD`)
+		fipsCmd = `
+Set-ItemProperty -Path 'HKLM:\System\CurrentControlSet\Control\Lsa\FipsAlgorithmPolicy' -Name 'Enabled' -Value 1 -Type DWORD`
 	}
-	cmd += fmt.Sprintf(`
-$exitCode = (Start-Process -Wait msiexec -PassThru -ArgumentList '/qn /i %s APIKEY=%%s %s').ExitCode
+
+	// The parameters are in a single-quoted PowerShell string
+	parameters := pulumi.All(apiKey, secretInstallParameters).ApplyT(func(values []interface{}) string {
+		secretParameters, _ := values[1].([]string)
+		parameters := append(append([]string{}, secretParameters...), additionalInstallParameters...)
+		if !msi.HasAPIKey(parameters) {
+			parameters = append([]string{"APIKEY=" + values[0].(string)}, parameters...)
+		}
+		return strings.ReplaceAll(strings.Join(parameters, " "), "'", "''")
+	}).(pulumi.StringOutput)
+
+	return pulumi.Sprintf(`%s
+$exitCode = (Start-Process -Wait msiexec -PassThru -ArgumentList '/qn /i %s %s').ExitCode
 Get-Content %s
 Exit $exitCode
-	`, filePath, strings.Join(additionalInstallParameters, " "), logFilePath)
-	return cmd, nil
+	`, fipsCmd, filePath, parameters, logFilePath), nil
 }
 
 func (am *agentWindowsManager) getAgentConfigFolder() string {
@@ -163,6 +190,105 @@ start-process msiexec -Wait -ArgumentList ('/log', 'C:\uninst.log', '/q', '/x',
 	}}, opts...)
 }
 
+// maintenanceCommand runs the MSI maintenance operation on the installed Agent, the command fails with the MSI logs if the operation does
+func (am *agentWindowsManager) maintenanceCommand(name string, operation msi.MaintenanceOperation, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	arguments, err := operation.Args("{product}")
+	if err != nil {
+		return nil, err
+	}
+	logFilePath := fmt.Sprintf(`C:\msi-%s.log`, operation.Kind)
+	if !slices.ContainsFunc(operation.Parameters, func(s string) bool { return strings.HasPrefix(s, "/log") }) {
+		arguments += " /log " + logFilePath
+	}
+	// The arguments are in a single-quoted PowerShell string
+	escapedArguments := operation.SecretParameters.ToStringArrayOutput().ApplyT(func(secretParameters []string) string {
+		return strings.ReplaceAll(strings.Join(append([]string{arguments}, secretParameters...), " "), "'", "''")
+	}).(pulumi.StringOutput)
+	cmd := pulumi.Sprintf(`
+$ErrorActionPreference = 'Stop';
+$productCode = (@(Get-ChildItem -Path "HKLM:SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall" -Recurse) | Where {$_.GetValue("DisplayName") -like "Datadog Agent" }).PSChildName
+if (!$productCode) {
+	throw "No Datadog Agent installation found to %[1]s"
+}
+$exitCode = (Start-Process -Wait msiexec -PassThru -ArgumentList ('%[2]s'.Replace('{product}', $productCode))).ExitCode
+if ($exitCode -ne 0) {
+	Get-Content '%[3]s' -ErrorAction SilentlyContinue | ForEach-Object { [Console]::Error.WriteLine($_) }
+}
+Exit $exitCode
+`, operation.Kind, escapedArguments, logFilePath)
+	return runTransformed(am.host.OS.Runner(), name, &command.Args{Create: cmd}, transform, opts...)
+}
+
+func (am *agentWindowsManager) waitForHealthy(name string, timeout time.Duration, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	cmd := fmt.Sprintf(`
+$deadline = (Get-Date).AddSeconds(%d)
+while ($true) {
+	& "%s" health
+	if ($LASTEXITCODE -eq 0) {
+		Exit 0
+	}
+	if ((Get-Date) -gt $deadline) {
+		[Console]::Error.WriteLine('the Agent is not healthy after %[1]d seconds')
+		Get-Content -Tail 100 -Path "$env:ProgramData\Datadog\logs\agent.log" | ForEach-Object { [Console]::Error.WriteLine($_) }
+		Exit 1
+	}
+	Start-Sleep -Seconds 5
+}
+`, int(timeout.Seconds()), windowsAgentBinary)
+	return runTransformed(am.host.OS.Runner(), name, &command.Args{Create: pulumi.String(cmd)}, transform, opts...)
+}
+
+func (am *agentWindowsManager) runAgentCommand(name string, agentArgs string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runTransformed(am.host.OS.Runner(), name, &command.Args{
+		Create: pulumi.String(fmt.Sprintf(`& "%s" %s`, windowsAgentBinary, agentArgs)),
+	}, transform, opts...)
+}
+
+// getSecretBackend returns a command printing the secrets stored next to it. The Agent only runs Win32 applications so it is compiled on the host.
+// Only the Agent user, the administrators and the system can access them as required by the Agent.
+func (am *agentWindowsManager) getSecretBackend(secrets map[string]string) (secretBackend, error) {
+	output, err := secretBackendOutput(secrets)
+	if err != nil {
+		return secretBackend{}, err
+	}
+
+	permissions := perms.NewWindowsPermissions(perms.WithDisableInheritance(), perms.WithIcaclsCommand(`/grant "ddagentuser:(RX)" "*S-1-5-32-544:(F)" "*S-1-5-18:(F)"`))
+	path := am.getAgentConfigFolder() + `\secret_backend.exe`
+	setupPermissions := ""
+	if value, found := permissions.Get(); found {
+		setupPermissions = value.SetupPermissionsCommand(path)
+	}
+
+	return secretBackend{
+		command: path,
+		files: map[string]*agentparams.FileDefinition{
+			am.getAgentConfigFolder() + `\secret_backend.json`: {
+				Content:     output,
+				Permissions: permissions,
+			},
+		},
+		build: fmt.Sprintf(`
+$ErrorActionPreference = 'Stop'
+$source = @'
+using System;
+using System.IO;
+
+public static class SecretBackend {
+	public static int Main() {
+		Console.In.ReadToEnd();
+		Console.Write(File.ReadAllText(Path.Combine(AppDomain.CurrentDomain.BaseDirectory, "secret_backend.json")));
+		return 0;
+	}
+}
+'@
+Remove-Item -Force -ErrorAction SilentlyContinue -Path '%[1]s'
+Add-Type -TypeDefinition $source -OutputAssembly '%[1]s' -OutputType ConsoleApplication
+%[2]s
+`, path, setupPermissions),
+		cleanup: fmt.Sprintf(`Remove-Item -Force -ErrorAction SilentlyContinue -Path '%s'`, path),
+	}, nil
+}
+
 func getAgentURL(version agentparams.PackageVersion) (string, error) {
 	if version.Flavor == "" {
 		version.Flavor = agentparams.DefaultFlavor
@@ -332,3 +458,8 @@ func getKey[T any](m map[string]interface{}, keyName string) (T, error) {
 	}
 	return value, nil
 }
+
+// getAgentFilePermissions grants read access to ddagentuser, in addition to the permissions inherited from the configuration folder
+func (am *agentWindowsManager) getAgentFilePermissions() option.Option[perms.FilePermissions] {
+	return perms.NewWindowsPermissions(perms.WithIcaclsCommand(`/grant "ddagentuser:(R)"`))
+}
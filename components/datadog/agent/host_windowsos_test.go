@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams"
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams/msi"
+)
+
+func TestWindowsInstallPackageCommand(t *testing.T) {
+	resolve := func(output pulumi.StringOutput) string {
+		values := make(chan string, 1)
+		output.ApplyT(func(v string) string {
+			values <- v
+			return v
+		})
+		return <-values
+	}
+	manager := &agentWindowsManager{}
+	version := agentparams.PackageVersion{Major: "7"}
+
+	t.Run("should escape the parameters", func(t *testing.T) {
+		parameters, err := msi.NewInstallParams(msi.WithTags("owner:o'brien", "load:100%"))
+		require.NoError(t, err)
+		cmd, err := manager.getInstallPackageCommand(`C:\datadog-agent.msi`, version, pulumi.String("abcdef"), parameters, nil)
+		require.NoError(t, err)
+		assert.Contains(t, resolve(cmd), `-ArgumentList '/qn /i C:\datadog-agent.msi APIKEY=abcdef TAGS=owner:o''brien,load:100% /log C:\install.log'`)
+	})
+	t.Run("should use the API key of the secret parameters", func(t *testing.T) {
+		cmd, err := manager.getInstallPackageCommand(`C:\datadog-agent.msi`, version, pulumi.String("abcdef"), nil, msi.NewSecretInstallParams(msi.WithAPIKey(pulumi.String("123456"))))
+		require.NoError(t, err)
+		assert.Contains(t, resolve(cmd), `-ArgumentList '/qn /i C:\datadog-agent.msi APIKEY=123456 /log C:\install.log'`)
+		assert.True(t, pulumi.IsSecret(cmd))
+	})
+}
@@ -35,9 +35,9 @@ func GetPackagePath(localPath string, flavor tifos.Flavor, agentFlavor string, a
 	var wantedExt string
 	var subFolder string
 	switch flavor {
-	case tifos.AmazonLinux, tifos.CentOS, tifos.RedHat, tifos.AmazonLinuxECS, tifos.Fedora, tifos.RockyLinux:
+	case tifos.AmazonLinux, tifos.CentOS, tifos.RedHat, tifos.AmazonLinuxECS, tifos.Fedora, tifos.RockyLinux, tifos.AlmaLinux, tifos.OracleLinux:
 		wantedExt = ".rpm"
-	case tifos.Suse:
+	case tifos.Suse, tifos.OpenSuseLeap:
 		wantedExt = ".rpm"
 		subFolder = "suse"
 	case tifos.Debian, tifos.Ubuntu:
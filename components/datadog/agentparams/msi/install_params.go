@@ -3,18 +3,36 @@ package msi
 import (
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 
 	"github.com/DataDog/test-infra-definitions/components/datadog/fakeintake"
 )
 
 // InstallAgentParams are the parameters used for installing the Agent using msiexec.
+// Unset fields are not passed to msiexec, list fields are joined with their `installer_sep` tag.
+// Secret fields, of type pulumi.StringInput, are kept out of ToArgs and converted by SecretArgs.
 type InstallAgentParams struct {
-	AgentUser         string `installer_arg:"DDAGENTUSER_NAME"`
-	AgentUserPassword string `installer_arg:"DDAGENTUSER_PASSWORD"`
-	DdURL             string `installer_arg:"DD_URL"`
-	Site              string `installer_arg:"SITE"`
-	InstallPath       string `installer_arg:"PROJECTLOCATION"`
-	InstallLogFile    string `installer_arg:"/log"`
+	AgentUser         string             `installer_arg:"DDAGENTUSER_NAME"`
+	AgentUserPassword pulumi.StringInput `installer_arg:"DDAGENTUSER_PASSWORD"`
+	APIKey            pulumi.StringInput `installer_arg:"APIKEY"`
+	DdURL             string             `installer_arg:"DD_URL"`
+	Site              string             `installer_arg:"SITE"`
+	Hostname          string             `installer_arg:"HOSTNAME"`
+	Tags              []string           `installer_arg:"TAGS" installer_sep:","`
+	ProcessEnabled    *bool              `installer_arg:"PROCESS_ENABLED"`
+	APMEnabled        *bool              `installer_arg:"APM_ENABLED"`
+	LogsEnabled       *bool              `installer_arg:"LOGS_ENABLED"`
+	Features          []string           `installer_arg:"ADDLOCAL" installer_sep:","`
+	ProxyHost         string             `installer_arg:"PROXY_HOST"`
+	ProxyPort         string             `installer_arg:"PROXY_PORT"`
+	ProxyUser         string             `installer_arg:"PROXY_USER"`
+	ProxyPassword     pulumi.StringInput `installer_arg:"PROXY_PASSWORD"`
+	Transforms        []string           `installer_arg:"TRANSFORMS" installer_sep:";"`
+	InstallPath       string             `installer_arg:"PROJECTLOCATION"`
+	InstallLogFile    string             `installer_arg:"/log"`
 }
 
 // InstallAgentOption is an optional function parameter type for InstallAgentParams options
@@ -23,17 +41,41 @@ type InstallAgentOption = func(*InstallAgentParams)
 // NewInstallParams instantiates a new InstallAgentParams and runs all the given InstallAgentOption
 // Example usage:
 //
+//	installParams, err := msiparams.NewInstallParams(
+//		msiparams.WithAgentUser(fmt.Sprintf("%s\\%s", TestDomain, TestUser)),
+//		msiparams.WithTags("team:windows"))
+//
+// It fails if a secret parameter is set, use agentparams.WithMSIParameters to pass them:
+//
 //	awshost.WithAgentOptions(
-//	  agentparams.WithAdditionalInstallParameters(
-//		msiparams.NewInstallParams(
-//			msiparams.WithAgentUser(fmt.Sprintf("%s\\%s", TestDomain, TestUser)),
-//			msiparams.WithAgentUserPassword(TestPassword)))),
-func NewInstallParams(msiInstallParams ...InstallAgentOption) []string {
+//	  agentparams.WithMSIParameters(
+//		msiparams.WithAgentUser(fmt.Sprintf("%s\\%s", TestDomain, TestUser)),
+//		msiparams.WithAgentUserPassword(pulumi.String(TestPassword)))),
+func NewInstallParams(msiInstallParams ...InstallAgentOption) ([]string, error) {
+	params := newInstallAgentParams(msiInstallParams...)
+	if secrets := params.secretArgNames(); len(secrets) > 0 {
+		return nil, fmt.Errorf("secret MSI parameters %s cannot be returned as plain strings, use agentparams.WithMSIParameters", strings.Join(secrets, ", "))
+	}
+	return params.ToArgs(), nil
+}
+
+// NewInstallParamsWithSecrets instantiates a new InstallAgentParams and returns both its parameters and its secret parameters
+func NewInstallParamsWithSecrets(msiInstallParams ...InstallAgentOption) ([]string, pulumi.StringArray) {
+	params := newInstallAgentParams(msiInstallParams...)
+	return params.ToArgs(), params.SecretArgs()
+}
+
+// NewSecretInstallParams instantiates a new InstallAgentParams and returns its secret parameters, see SecretArgs
+func NewSecretInstallParams(msiInstallParams ...InstallAgentOption) pulumi.StringArray {
+	return newInstallAgentParams(msiInstallParams...).SecretArgs()
+}
+
+func newInstallAgentParams(msiInstallParams ...InstallAgentOption) *InstallAgentParams {
 	msiInstallAgentParams := &InstallAgentParams{}
 	for _, o := range msiInstallParams {
 		o(msiInstallAgentParams)
 	}
-	return msiInstallAgentParams.ToArgs()
+	return msiInstallAgentParams
 }
 
 // ToArgs convert the params to a list of valid msi switches, based on the `installer_arg` tag
@@ -43,20 +85,73 @@ func (p *InstallAgentParams) ToArgs() []string {
 	for fieldIndex := 0; fieldIndex < typeOfMSIInstallAgentParams.NumField(); fieldIndex++ {
 		field := typeOfMSIInstallAgentParams.Field(fieldIndex)
 		installerArg := field.Tag.Get("installer_arg")
-		if installerArg != "" {
-			installerArgValue := reflect.ValueOf(*p).FieldByName(field.Name).String()
-			if installerArgValue != "" {
-				format := "%s=%s"
-				if field.Name == "InstallLogFile" {
-					format = "%s %s"
-				}
-				args = append(args, fmt.Sprintf(format, installerArg, installerArgValue))
+		if installerArg == "" {
+			continue
+		}
+
+		var installerArgValue string
+		switch value := reflect.ValueOf(*p).FieldByName(field.Name).Interface().(type) {
+		case string:
+			installerArgValue = value
+		case []string:
+			installerArgValue = strings.Join(value, field.Tag.Get("installer_sep"))
+		case *bool:
+			if value != nil {
+				installerArgValue = strconv.FormatBool(*value)
 			}
 		}
+		if installerArgValue == "" {
+			continue
+		}
+
+		if field.Name == "InstallLogFile" {
+			args = append(args, fmt.Sprintf("%s %s", installerArg, installerArgValue))
+		} else {
+			args = append(args, formatArg(installerArg, installerArgValue))
+		}
 	}
 	return args
 }
 
+// SecretArgs converts the secret params, such as the API key, to msiexec switches which stay secret in the Pulumi state
+func (p *InstallAgentParams) SecretArgs() pulumi.StringArray {
+	var args pulumi.StringArray
+	typeOfMSIInstallAgentParams := reflect.TypeOf(*p)
+	for fieldIndex := 0; fieldIndex < typeOfMSIInstallAgentParams.NumField(); fieldIndex++ {
+		field := typeOfMSIInstallAgentParams.Field(fieldIndex)
+		value, ok := reflect.ValueOf(*p).Field(fieldIndex).Interface().(pulumi.StringInput)
+		if !ok || value == nil {
+			continue
+		}
+		installerArg := field.Tag.Get("installer_arg")
+		arg := value.ToStringOutput().ApplyT(func(v string) string {
+			return formatArg(installerArg, v)
+		})
+		args = append(args, pulumi.ToSecret(arg).(pulumi.StringOutput))
+	}
+	return args
+}
+
+// secretArgNames returns the installer arguments of the secret params which are set
+func (p *InstallAgentParams) secretArgNames() []string {
+	var names []string
+	typeOfMSIInstallAgentParams := reflect.TypeOf(*p)
+	for fieldIndex := 0; fieldIndex < typeOfMSIInstallAgentParams.NumField(); fieldIndex++ {
+		if value, ok := reflect.ValueOf(*p).Field(fieldIndex).Interface().(pulumi.StringInput); ok && value != nil {
+			names = append(names, typeOfMSIInstallAgentParams.Field(fieldIndex).Tag.Get("installer_arg"))
+		}
+	}
+	return names
+}
+
+// formatArg formats a property, quoting its value if it contains spaces or quotes, which are escaped by doubling them
+func formatArg(installerArg, value string) string {
+	if strings.ContainsAny(value, ` "`) {
+		return fmt.Sprintf(`%s="%s"`, installerArg, strings.ReplaceAll(value, `"`, `""`))
+	}
+	return fmt.Sprintf("%s=%s", installerArg, value)
+}
+
 // WithAgentUser specifies the DDAGENTUSER_NAME parameter.
 func WithAgentUser(username string) InstallAgentOption {
 	return func(i *InstallAgentParams) {
@@ -64,13 +159,20 @@ func WithAgentUser(username string) InstallAgentOption {
 	}
 }
 
-// WithAgentUserPassword specifies the DDAGENTUSER_PASSWORD parameter.
-func WithAgentUserPassword(password string) InstallAgentOption {
+// WithAgentUserPassword specifies the DDAGENTUSER_PASSWORD secret parameter.
+func WithAgentUserPassword(password pulumi.StringInput) InstallAgentOption {
 	return func(i *InstallAgentParams) {
 		i.AgentUserPassword = password
 	}
 }
 
+// WithAPIKey specifies the APIKEY secret parameter, it replaces the API key of the environment.
+func WithAPIKey(apiKey pulumi.StringInput) InstallAgentOption {
+	return func(i *InstallAgentParams) {
+		i.APIKey = apiKey
+	}
+}
+
 // WithSite specifies the SITE parameter.
 func WithSite(site string) InstallAgentOption {
 	return func(i *InstallAgentParams) {
@@ -85,6 +187,82 @@ func WithDdURL(ddURL string) InstallAgentOption {
 	}
 }
 
+// WithHostname specifies the HOSTNAME parameter.
+func WithHostname(hostname string) InstallAgentOption {
+	return func(i *InstallAgentParams) {
+		i.Hostname = hostname
+	}
+}
+
+// WithTags adds to the TAGS parameter, tags are in the `key:value` format.
+func WithTags(tags ...string) InstallAgentOption {
+	return func(i *InstallAgentParams) {
+		i.Tags = append(i.Tags, tags...)
+	}
+}
+
+// WithProcessEnabled specifies the PROCESS_ENABLED parameter.
+func WithProcessEnabled(enabled bool) InstallAgentOption {
+	return func(i *InstallAgentParams) {
+		i.ProcessEnabled = &enabled
+	}
+}
+
+// WithAPMEnabled specifies the APM_ENABLED parameter.
+func WithAPMEnabled(enabled bool) InstallAgentOption {
+	return func(i *InstallAgentParams) {
+		i.APMEnabled = &enabled
+	}
+}
+
+// WithLogsEnabled specifies the LOGS_ENABLED parameter.
+func WithLogsEnabled(enabled bool) InstallAgentOption {
+	return func(i *InstallAgentParams) {
+		i.LogsEnabled = &enabled
+	}
+}
+
+// WithFeatures adds features to the ADDLOCAL parameter, for instance `MainApplication`.
+func WithFeatures(features ...string) InstallAgentOption {
+	return func(i *InstallAgentParams) {
+		i.Features = append(i.Features, features...)
+	}
+}
+
+// WithNPM installs the Network Performance Monitoring feature along with the main application.
+func WithNPM() InstallAgentOption {
+	return func(i *InstallAgentParams) {
+		if len(i.Features) == 0 {
+			i.Features = []string{"MainApplication"}
+		}
+		i.Features = append(i.Features, "NPM")
+	}
+}
+
+// WithProxy specifies the PROXY_HOST and PROXY_PORT parameters.
+func WithProxy(host string, port int) InstallAgentOption {
+	return func(i *InstallAgentParams) {
+		i.ProxyHost = host
+		i.ProxyPort = strconv.Itoa(port)
+	}
+}
+
+// WithProxyCredentials specifies the PROXY_USER and PROXY_PASSWORD parameters, the password is secret.
+func WithProxyCredentials(user string, password pulumi.StringInput) InstallAgentOption {
+	return func(i *InstallAgentParams) {
+		i.ProxyUser = user
+		i.ProxyPassword = password
+	}
+}
+
+// WithTransforms adds `.mst` transforms, already on the host, to the TRANSFORMS parameter.
+// Use agentparams.WithMSITransforms to upload them from the local machine.
+func WithTransforms(remotePaths ...string) InstallAgentOption {
+	return func(i *InstallAgentParams) {
+		i.Transforms = append(i.Transforms, remotePaths...)
+	}
+}
+
 // WithInstallLogFile specifies the file where to save the MSI install logs.
 func WithInstallLogFile(logFileName string) InstallAgentOption {
 	return func(i *InstallAgentParams) {
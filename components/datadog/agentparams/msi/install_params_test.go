@@ -0,0 +1,103 @@
+package msi
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallParams(t *testing.T) {
+	t.Run("NewInstallParams should only pass the set parameters", func(t *testing.T) {
+		args, err := NewInstallParams(
+			WithSite("datadoghq.eu"),
+			WithTags("env:test", "team:agent"),
+			WithLogsEnabled(false),
+			WithAPMEnabled(true),
+			WithProxy("proxy.local", 3128),
+			WithInstallLogFile(`C:\install.log`),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"SITE=datadoghq.eu",
+			"TAGS=env:test,team:agent",
+			"APM_ENABLED=true",
+			"LOGS_ENABLED=false",
+			"PROXY_HOST=proxy.local",
+			"PROXY_PORT=3128",
+			`/log C:\install.log`,
+		}, args)
+	})
+	t.Run("NewInstallParams should quote the values with spaces", func(t *testing.T) {
+		args, err := NewInstallParams(WithCustomInstallPath(`C:\Program Files\Datadog`))
+		require.NoError(t, err)
+		assert.Equal(t, []string{`PROJECTLOCATION="C:\Program Files\Datadog"`}, args)
+	})
+	t.Run("NewInstallParams should escape the quotes", func(t *testing.T) {
+		args, err := NewInstallParams(WithTags(`team:"agent"`, "env:test"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{`TAGS="team:""agent"",env:test"`}, args)
+	})
+	t.Run("NewInstallParams should fail with secret parameters", func(t *testing.T) {
+		_, err := NewInstallParams(WithSite("datadoghq.eu"), WithAgentUserPassword(pulumi.String("secret")), WithAPIKey(pulumi.String("abcdef")))
+		assert.ErrorContains(t, err, "secret MSI parameters DDAGENTUSER_PASSWORD, APIKEY")
+	})
+	t.Run("WithNPM should install the main application along with NPM", func(t *testing.T) {
+		args, err := NewInstallParams(WithNPM())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ADDLOCAL=MainApplication,NPM"}, args)
+	})
+	t.Run("WithTransforms should separate the transforms with semicolons", func(t *testing.T) {
+		args, err := NewInstallParams(WithTransforms(`C:\a.mst`, `C:\b.mst`))
+		require.NoError(t, err)
+		assert.Equal(t, []string{`TRANSFORMS=C:\a.mst;C:\b.mst`}, args)
+	})
+	t.Run("HasAPIKey should detect the APIKEY parameter", func(t *testing.T) {
+		assert.True(t, HasAPIKey([]string{"SITE=datadoghq.eu", "APIKEY=abcdef"}))
+		args, err := NewInstallParams(WithSite("datadoghq.eu"))
+		require.NoError(t, err)
+		assert.False(t, HasAPIKey(args))
+	})
+	t.Run("NewInstallParamsWithSecrets should pass the secret parameters as secrets", func(t *testing.T) {
+		args, secretArgs := NewInstallParamsWithSecrets(
+			WithSite("datadoghq.eu"),
+			WithAgentUser(`DOMAIN\user`),
+			WithAgentUserPassword(pulumi.String(`pass"word`)),
+			WithAPIKey(pulumi.String("abcdef")),
+			WithProxyCredentials("user", pulumi.String("pass word")),
+		)
+		assert.Equal(t, []string{"DDAGENTUSER_NAME=DOMAIN\\user", "SITE=datadoghq.eu", "PROXY_USER=user"}, args)
+
+		secretOutput := secretArgs.ToStringArrayOutput()
+		values := make(chan []string, 1)
+		secretOutput.ApplyT(func(args []string) []string {
+			values <- args
+			return args
+		})
+		assert.Equal(t, []string{`DDAGENTUSER_PASSWORD="pass""word"`, "APIKEY=abcdef", `PROXY_PASSWORD="pass word"`}, <-values)
+		assert.True(t, pulumi.IsSecret(secretOutput))
+	})
+}
+
+func TestMaintenanceOperation(t *testing.T) {
+	t.Run("Args should repair the product", func(t *testing.T) {
+		args, err := NewRepairOperation().Args("{product}")
+		assert.NoError(t, err)
+		assert.Equal(t, "/qn /fa {product}", args)
+	})
+	t.Run("Args should reinstall the product with the new properties", func(t *testing.T) {
+		args, err := NewModifyOperation(WithHostname("my-host")).Args("{product}")
+		assert.NoError(t, err)
+		assert.Equal(t, "/qn /i {product} REINSTALL=ALL REINSTALLMODE=omus HOSTNAME=my-host", args)
+	})
+	t.Run("Args should not reinstall the product when changing its features", func(t *testing.T) {
+		args, err := NewModifyOperation(WithNPM()).Args("{product}")
+		assert.NoError(t, err)
+		assert.Equal(t, "/qn /i {product} ADDLOCAL=MainApplication,NPM", args)
+	})
+	t.Run("Args should fail on an unknown operation", func(t *testing.T) {
+		_, err := MaintenanceOperation{Kind: "upgrade"}.Args("{product}")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,70 @@
+package msi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// MaintenanceKind is the kind of a MaintenanceOperation
+type MaintenanceKind string
+
+const (
+	// Repair reinstalls all the files of the Agent, with `msiexec /fa`
+	Repair MaintenanceKind = "repair"
+	// Modify changes the features or the properties of the Agent, with `msiexec /i` and `REINSTALL`
+	Modify MaintenanceKind = "modify"
+)
+
+// MaintenanceOperation is an msiexec operation on an existing installation of the Agent
+type MaintenanceOperation struct {
+	Kind       MaintenanceKind
+	Parameters []string
+	// SecretParameters are passed after Args, see InstallAgentParams.SecretArgs
+	SecretParameters pulumi.StringArray
+}
+
+// NewRepairOperation returns an operation repairing the Agent with the given parameters
+func NewRepairOperation(options ...InstallAgentOption) MaintenanceOperation {
+	parameters, secretParameters := NewInstallParamsWithSecrets(options...)
+	return MaintenanceOperation{Kind: Repair, Parameters: parameters, SecretParameters: secretParameters}
+}
+
+// NewModifyOperation returns an operation modifying the Agent with the given parameters, for instance new features with WithFeatures
+func NewModifyOperation(options ...InstallAgentOption) MaintenanceOperation {
+	parameters, secretParameters := NewInstallParamsWithSecrets(options...)
+	return MaintenanceOperation{Kind: Modify, Parameters: parameters, SecretParameters: secretParameters}
+}
+
+// Args returns the msiexec arguments of the operation on the given product, a product code or a package, without the secret parameters
+func (o MaintenanceOperation) Args(product string) (string, error) {
+	var args []string
+	switch o.Kind {
+	case Repair:
+		args = []string{"/qn", "/fa", product}
+	case Modify:
+		args = []string{"/qn", "/i", product}
+		// REINSTALL applies the new properties to the installed features, it conflicts with explicit features
+		if !hasParameter(o.Parameters, "ADDLOCAL") && !hasParameter(o.Parameters, "REMOVE") {
+			args = append(args, "REINSTALL=ALL", "REINSTALLMODE=omus")
+		}
+	default:
+		return "", fmt.Errorf("unknown MSI maintenance operation %q", o.Kind)
+	}
+	return strings.Join(append(args, o.Parameters...), " "), nil
+}
+
+func hasParameter(parameters []string, name string) bool {
+	for _, parameter := range parameters {
+		if strings.HasPrefix(parameter, name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAPIKey returns whether the parameters set the API key, instead of the one of the environment
+func HasAPIKey(parameters []string) bool {
+	return hasParameter(parameters, "APIKEY")
+}
@@ -2,13 +2,18 @@ package agentparams
 
 import (
 	"fmt"
+	"io/fs"
+	"os"
 	"path"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/option"
 	"github.com/DataDog/test-infra-definitions/common"
 	"github.com/DataDog/test-infra-definitions/common/config"
 	perms "github.com/DataDog/test-infra-definitions/components/datadog/agentparams/filepermissions"
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams/msi"
 	"github.com/DataDog/test-infra-definitions/components/datadog/fakeintake"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
@@ -24,6 +29,8 @@ import (
 //   - [WithSystemProbeConfig]
 //   - [WithSecurityAgentConfig]
 //   - [WithIntegration]
+//   - [WithIntegrationsDir]
+//   - [WithCustomCheck]
 //   - [WithFile]
 //   - [WithTelemetry]
 //   - [WithPulumiResourceOptions]
@@ -33,6 +40,15 @@ import (
 //   - [WithLogs]
 //   - [WithAdditionalInstallParameters]
 //   - [WithSkipAPIKeyInConfig]
+//   - [WithConfigTypoHints]
+//   - [WithUpgradePath]
+//   - [WithPackageRepository]
+//   - [WithPackageRepositoryMirror]
+//   - [WithSecretsBackend]
+//   - [WithHealthCheck]
+//   - [WithMSIParameters]
+//   - [WithMSITransforms]
+//   - [WithMSIMaintenance]
 //
 // [Functional options pattern]: https://dave.cheney.net/2014/10/17/functional-options-for-friendly-apis
 
@@ -40,6 +56,9 @@ type FileDefinition struct {
 	Content     string
 	UseSudo     bool
 	Permissions option.Option[perms.FilePermissions]
+	// AgentOwned files are readable by the Agent user, with the permissions the Agent expects on the OS of the host.
+	// It is ignored if Permissions is set.
+	AgentOwned bool
 }
 
 type Params struct {
@@ -54,7 +73,22 @@ type Params struct {
 	// This is a list of additional installer flags that can be used to pass installer-specific
 	// parameters like the MSI flags.
 	AdditionalInstallParameters []string
-	SkipAPIKeyInConfig          bool
+	// AdditionalSecretInstallParameters are installer parameters kept secret, such as the MSI API key, see WithMSIParameters.
+	AdditionalSecretInstallParameters pulumi.StringArray
+	SkipAPIKeyInConfig                bool
+	CheckConfigTypos                  bool
+	// UpgradePath is the list of versions installed in sequence after Version, see WithUpgradePath.
+	UpgradePath []PackageVersion
+	// PackageRepository is set to install the Agent from a package repository instead of the install script
+	PackageRepository *PackageRepository
+	// SecretsBackend are the secrets resolved by the secret backend command, see WithSecretsBackend
+	SecretsBackend map[string]string
+	// HealthTimeout is how long to wait for the Agent to be healthy once installed, 0 to not wait
+	HealthTimeout time.Duration
+	// MSITransforms are the local paths of the `.mst` transforms applied to the Windows installations
+	MSITransforms []string
+	// MSIMaintenance are the operations run on the Windows installation of the Agent once installed
+	MSIMaintenance []msi.MaintenanceOperation
 }
 
 type Option = func(*Params) error
@@ -211,6 +245,56 @@ func WithIntegration(folderName string, content string) func(*Params) error {
 	}
 }
 
+// WithIntegrationsDir adds the configuration files of the local conf.d folder at localPath, for instance `<localPath>/http_check.d/conf.yaml`.
+func WithIntegrationsDir(localPath string) func(*Params) error {
+	return func(p *Params) error {
+		return filepath.WalkDir(localPath, func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			relativePath, err := filepath.Rel(localPath, filePath)
+			if err != nil {
+				return err
+			}
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				return err
+			}
+			p.Integrations[path.Join("conf.d", filepath.ToSlash(relativePath))] = &FileDefinition{
+				Content:    string(content),
+				UseSudo:    true,
+				AgentOwned: true,
+			}
+			return nil
+		})
+	}
+}
+
+// WithCustomCheck adds the custom check name, its code is read from the local pyFile and its configuration from the local confYAML file.
+func WithCustomCheck(name string, pyFile string, confYAML string) func(*Params) error {
+	return func(p *Params) error {
+		code, err := os.ReadFile(pyFile)
+		if err != nil {
+			return err
+		}
+		conf, err := os.ReadFile(confYAML)
+		if err != nil {
+			return err
+		}
+		p.Integrations[path.Join("checks.d", name+".py")] = &FileDefinition{
+			Content:    string(code),
+			UseSudo:    true,
+			AgentOwned: true,
+		}
+		p.Integrations[path.Join("conf.d", name+".d", "conf.yaml")] = &FileDefinition{
+			Content:    string(conf),
+			UseSudo:    true,
+			AgentOwned: true,
+		}
+		return nil
+	}
+}
+
 // WithFile adds a file with contents to the install at the given path. This should only be used when the agent needs to be restarted after writing the file.
 func WithFile(absolutePath string, content string, useSudo bool) func(*Params) error {
 	return WithFileWithPermissions(absolutePath, content, useSudo, option.None[perms.FilePermissions]())
@@ -345,6 +429,43 @@ func WithAdditionalInstallParameters(parameters []string) func(*Params) error {
 	}
 }
 
+// WithMSIParameters adds typed msiexec parameters to the Windows installations, see the msi package for the available ones.
+// Secret parameters, such as the API key, stay secret in the Pulumi state.
+func WithMSIParameters(options ...msi.InstallAgentOption) func(*Params) error {
+	return func(p *Params) error {
+		parameters, secretParameters := msi.NewInstallParamsWithSecrets(options...)
+		p.AdditionalInstallParameters = append(p.AdditionalInstallParameters, parameters...)
+		p.AdditionalSecretInstallParameters = append(p.AdditionalSecretInstallParameters, secretParameters...)
+		return nil
+	}
+}
+
+// WithMSITransforms uploads the local `.mst` transforms to the Windows host and applies them to the installations.
+func WithMSITransforms(localPaths ...string) func(*Params) error {
+	return func(p *Params) error {
+		for _, localPath := range localPaths {
+			if filepath.Ext(localPath) != ".mst" {
+				return fmt.Errorf("MSI transform %s is not a .mst file", localPath)
+			}
+		}
+		p.MSITransforms = append(p.MSITransforms, localPaths...)
+		return nil
+	}
+}
+
+// WithMSIMaintenance runs repair or modify operations, in order, on the Windows installation of the Agent once installed.
+// For example:
+//
+//	agentparams.WithMSIMaintenance(
+//		msi.NewModifyOperation(msi.WithNPM()),
+//		msi.NewRepairOperation())
+func WithMSIMaintenance(operations ...msi.MaintenanceOperation) func(*Params) error {
+	return func(p *Params) error {
+		p.MSIMaintenance = append(p.MSIMaintenance, operations...)
+		return nil
+	}
+}
+
 // WithSkipAPIKeyInConfig does not add the API key in the Agent configuration file.
 func WithSkipAPIKeyInConfig() func(*Params) error {
 	return func(p *Params) error {
@@ -353,6 +474,85 @@ func WithSkipAPIKeyInConfig() func(*Params) error {
 	}
 }
 
+// WithConfigTypoHints fails before the configuration files of the agent are uploaded if they contain likely typos or wrongly typed values.
+// It is a best-effort check against the hand-written schemas of the configschema package, most unknown keys are accepted.
+func WithConfigTypoHints() func(*Params) error {
+	return func(p *Params) error {
+		p.CheckConfigTypos = true
+		return nil
+	}
+}
+
+// WithUpgradePath installs the version set by from, then upgrades, or downgrades, the Agent to the versions set by to, in order.
+// from and to are version options, such as WithVersion, WithLatest, WithLatestNightly, WithPipeline or WithLocalPackage,
+// applied on top of the major version and flavor set by the previous options.
+// The configuration and integrations are written after the first installation and kept across upgrades.
+// Downgrades work only where the package manager or the MSI allows them.
+func WithUpgradePath(from Option, to ...Option) func(*Params) error {
+	return func(p *Params) error {
+		if len(to) == 0 {
+			return fmt.Errorf("the upgrade path needs at least one version to upgrade to")
+		}
+		base := PackageVersion{Major: p.Version.Major, Flavor: p.Version.Flavor}
+		versions := make([]PackageVersion, 0, len(to)+1)
+		for _, option := range append([]Option{from}, to...) {
+			stage := &Params{Version: base}
+			if err := option(stage); err != nil {
+				return err
+			}
+			// Options such as WithVersion replace the whole version
+			if stage.Version.Flavor == "" {
+				stage.Version.Flavor = base.Flavor
+			}
+			versions = append(versions, stage.Version)
+		}
+		p.Version = versions[0]
+		p.UpgradePath = versions[1:]
+		return nil
+	}
+}
+
+// WithPackageRepository installs the Agent with the package manager from the Datadog apt, yum or zypper repository of its version and channel,
+// instead of using the install script. Only Linux hosts are supported.
+func WithPackageRepository() func(*Params) error {
+	return func(p *Params) error {
+		p.PackageRepository = &PackageRepository{}
+		return nil
+	}
+}
+
+// WithPackageRepositoryMirror installs the Agent like WithPackageRepository from a mirror of the Datadog repositories at baseURL,
+// signed with the key at keyURL, or unsigned if keyURL is empty.
+// The mirror must have the layout of the Datadog repositories, for instance `<baseURL>/stable/7/x86_64` for yum.
+func WithPackageRepositoryMirror(baseURL string, keyURL string) func(*Params) error {
+	return func(p *Params) error {
+		if baseURL == "" {
+			return fmt.Errorf("the base URL of the package repository mirror cannot be empty")
+		}
+		p.PackageRepository = &PackageRepository{BaseURL: strings.TrimSuffix(baseURL, "/"), KeyURL: keyURL}
+		return nil
+	}
+}
+
+// WithSecretsBackend deploys a secret backend command resolving the given secrets, from handle to value, and configures the Agent to use it.
+// `ENC[<handle>]` placeholders can then be used in the configurations set by WithAgentConfig and WithIntegration.
+// Only Linux and Windows hosts are supported.
+func WithSecretsBackend(secrets map[string]string) func(*Params) error {
+	return func(p *Params) error {
+		p.SecretsBackend = secrets
+		return nil
+	}
+}
+
+// WithHealthCheck waits up to timeout for the Agent to be healthy once installed and configured, and after each version of the upgrade path if any.
+// The deployment fails with the logs of the Agent if it is not, otherwise its status is exported.
+func WithHealthCheck(timeout time.Duration) func(*Params) error {
+	return func(p *Params) error {
+		p.HealthTimeout = timeout
+		return nil
+	}
+}
+
 // WithTags add tags to the agent configuration
 func WithTags(tags []string) func(*Params) error {
 	return func(p *Params) error {
@@ -361,6 +561,22 @@ func WithTags(tags []string) func(*Params) error {
 	}
 }
 
+// WithProxy configures the agent to send its traffic through the proxy at url, hosts in noProxy are reached directly.
+func WithProxy(url pulumi.StringInput, noProxy []string) func(*Params) error {
+	return func(p *Params) error {
+		// Values are quoted as URLs may contain YAML special characters, such as `#` or `@` in credentials
+		noProxyConfig := ""
+		for _, host := range noProxy {
+			noProxyConfig += fmt.Sprintf("\n    - %q", host)
+		}
+		if noProxyConfig != "" {
+			noProxyConfig = "\n  no_proxy:" + noProxyConfig
+		}
+		p.ExtraAgentConfig = append(p.ExtraAgentConfig, pulumi.Sprintf("proxy:\n  http: %[1]q\n  https: %[1]q%[2]s", url, noProxyConfig))
+		return nil
+	}
+}
+
 // WithHostname add hostname to the agent configuration
 func WithHostname(hostname string) func(*Params) error {
 	return func(p *Params) error {
@@ -28,3 +28,11 @@ type PackageVersion struct {
 	Flavor     string // Empty means default (base)
 	LocalPath  string // Local path to the agent packages
 }
+
+// PackageRepository is the repository the Agent is installed from, the Datadog repositories when BaseURL is empty
+type PackageRepository struct {
+	// BaseURL is the base URL of a mirror of the Datadog repositories
+	BaseURL string
+	// KeyURL is the URL of the key signing the mirror, packages are not checked when empty
+	KeyURL string
+}
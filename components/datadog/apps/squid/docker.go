@@ -0,0 +1,32 @@
+package squid
+
+import (
+	_ "embed"
+
+	"github.com/DataDog/test-infra-definitions/components/command"
+	"github.com/DataDog/test-infra-definitions/components/docker"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// Port is the port the squid proxy listens on. The default squid configuration only allows clients from private networks.
+const Port = 3128
+
+//go:embed docker-compose.yaml
+var dockerComposeYAML string
+
+var DockerComposeManifest = docker.ComposeInlineManifest{
+	Name:    "squid",
+	Content: pulumi.String(dockerComposeYAML),
+}
+
+// NewDockerProxy runs a squid proxy on the docker host and returns its URL, to be used with proxy.NewHostProxy or agentparams.WithProxy.
+// The access log can be read with `docker exec squid cat /var/log/squid/access.log` to check the traffic went through the proxy.
+func NewDockerProxy(manager *docker.Manager, opts ...pulumi.ResourceOption) (command.Command, pulumi.StringOutput, error) {
+	cmd, err := manager.ComposeStrUp("squid", []docker.ComposeInlineManifest{DockerComposeManifest}, pulumi.StringMap{}, opts...)
+	if err != nil {
+		return nil, pulumi.StringOutput{}, err
+	}
+
+	return cmd, pulumi.Sprintf("http://%s:%d", manager.Host.Address, Port), nil
+}
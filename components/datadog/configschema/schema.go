@@ -0,0 +1,299 @@
+// Package configschema checks agent configuration files for likely mistakes against the schemas bundled in the schemas directory.
+//
+// The check is best effort and only gives typo hints: schemas are hand-written and only list the commonly used keys,
+// they are not generated from the config_template.yaml of the agent and may lag behind it.
+// they catch wrong types of known keys and unknown keys within a small edit distance of a known key.
+// Any other unknown key is accepted, passing the check does not mean the configuration is valid.
+// Schemas are versioned, the schema of a version is the merge of all the schemas up to it.
+package configschema
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed schemas
+var schemas embed.FS
+
+// Value types of the schemas
+const (
+	typeString  = "string"
+	typeBool    = "bool"
+	typeInt     = "int"
+	typeFloat   = "float"
+	typeList    = "list"
+	typeMap     = "map"
+	typeMapList = "maplist"
+	typeAny     = "any"
+)
+
+// maxTypoDistance is the maximum edit distance between an unknown key and a known one to report it as a typo
+const maxTypoDistance = 2
+
+// Validate reports likely typos in the content of a configuration file of the agent of the given version, version is `<major>.<minor>`,
+// `<major>` for the latest minor version, or empty for the latest one. Files without schema, or versions older than all the schemas, are not validated.
+// configPath is relative to the agent configuration folder, `conf.d/<integration>/conf.yaml` for integrations.
+func Validate(version, configPath, content string) error {
+	schema, err := schemaFor(version, configPath)
+	if err != nil || schema == nil {
+		return err
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+		return fmt.Errorf("%s: invalid YAML: %w", configPath, err)
+	}
+	if len(document.Content) == 0 {
+		return nil
+	}
+
+	var errs []error
+	validateNode(schema, document.Content[0], "", func(key string, node *yaml.Node, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s (line %d): %s", configPath, key, node.Line, fmt.Sprintf(format, args...)))
+	})
+	return errors.Join(errs...)
+}
+
+// schemaFor returns the schema of the file, nil if there is none or if the version is older than all the schemas
+func schemaFor(version, configPath string) (map[string]any, error) {
+	if strings.HasPrefix(configPath, "conf.d/") && path.Base(configPath) == "conf.yaml" {
+		return readSchema("schemas/integration.yaml")
+	}
+
+	versions, err := schemaVersions()
+	if err != nil {
+		return nil, err
+	}
+	var schema map[string]any
+	for _, v := range versions {
+		if isNewer(v, version) {
+			break
+		}
+		content, err := readSchema(path.Join("schemas", v, configPath))
+		if err != nil {
+			return nil, err
+		}
+		schema = mergeSchemas(schema, content)
+	}
+	return schema, nil
+}
+
+// schemaVersions returns the versions of the bundled schemas, sorted
+func schemaVersions() ([]string, error) {
+	entries, err := schemas.ReadDir("schemas")
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			versions = append(versions, entry.Name())
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return compareVersions(versions[i], versions[j]) < 0 })
+	return versions, nil
+}
+
+// readSchema returns nil if the schema does not exist
+func readSchema(name string) (map[string]any, error) {
+	content, err := schemas.ReadFile(name)
+	if err != nil {
+		return nil, nil //nolint:nilerr // not all the versions have a schema for all the files
+	}
+	schema := map[string]any{}
+	if err := yaml.Unmarshal(content, &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", name, err)
+	}
+	return schema, nil
+}
+
+func mergeSchemas(base, override map[string]any) map[string]any {
+	if base == nil {
+		return override
+	}
+	for key, value := range override {
+		baseSection, baseIsSection := base[key].(map[string]any)
+		section, isSection := value.(map[string]any)
+		if baseIsSection && isSection {
+			base[key] = mergeSchemas(baseSection, section)
+		} else {
+			base[key] = value
+		}
+	}
+	return base
+}
+
+// compareVersions compares `<major>.<minor>` versions, suffixes of the minor version such as patch or release candidates are ignored
+func compareVersions(a, b string) int {
+	aMajor, aMinor := splitVersion(a)
+	bMajor, bMinor := splitVersion(b)
+	if aMajor != bMajor {
+		return aMajor - bMajor
+	}
+	return aMinor - bMinor
+}
+
+// isNewer returns true if the schema version v is newer than version, see Validate
+func isNewer(v, version string) bool {
+	if version == "" {
+		return false
+	}
+	if !strings.Contains(version, ".") {
+		vMajor, _ := splitVersion(v)
+		major, _ := strconv.Atoi(version)
+		return vMajor > major
+	}
+	return compareVersions(v, version) > 0
+}
+
+func splitVersion(version string) (int, int) {
+	major, minor, _ := strings.Cut(version, ".")
+	if end := strings.IndexFunc(minor, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+		minor = minor[:end]
+	}
+	majorInt, _ := strconv.Atoi(major)
+	minorInt, _ := strconv.Atoi(minor)
+	return majorInt, minorInt
+}
+
+func validateNode(schema map[string]any, node *yaml.Node, prefix string, report func(key string, node *yaml.Node, format string, args ...any)) {
+	if node.Kind != yaml.MappingNode {
+		report(strings.TrimSuffix(prefix, "."), node, "expected a mapping, got %s", kindName(node))
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		key := prefix + keyNode.Value
+
+		// Keys are case insensitive
+		expected, known := schema[strings.ToLower(keyNode.Value)]
+		if !known {
+			if suggestion := closestKey(schema, strings.ToLower(keyNode.Value)); suggestion != "" {
+				report(key, keyNode, "unknown key, did you mean %s%s?", prefix, suggestion)
+			}
+			continue
+		}
+
+		switch expected := expected.(type) {
+		case map[string]any:
+			if !isNull(valueNode) {
+				validateNode(expected, valueNode, key+".", report)
+			}
+		case string:
+			if !matchesType(expected, valueNode) {
+				report(key, valueNode, "expected %s, got %s", typeDescription(expected), kindName(valueNode))
+			}
+		}
+	}
+}
+
+// matchesType follows the agent, which converts scalar values to the expected type
+func matchesType(expected string, node *yaml.Node) bool {
+	if isNull(node) || expected == typeAny || isSecret(node) {
+		return true
+	}
+
+	switch expected {
+	case typeString:
+		return node.Kind == yaml.ScalarNode
+	case typeBool:
+		_, err := strconv.ParseBool(node.Value)
+		return node.Kind == yaml.ScalarNode && err == nil
+	case typeInt:
+		_, err := strconv.ParseInt(node.Value, 0, 64)
+		return node.Kind == yaml.ScalarNode && err == nil
+	case typeFloat:
+		_, err := strconv.ParseFloat(node.Value, 64)
+		return node.Kind == yaml.ScalarNode && err == nil
+	case typeList:
+		// Lists can also be set as space separated strings
+		return node.Kind == yaml.SequenceNode || node.Tag == "!!str"
+	case typeMap:
+		return node.Kind == yaml.MappingNode
+	case typeMapList:
+		return node.Kind == yaml.SequenceNode && !slices.ContainsFunc(node.Content, func(item *yaml.Node) bool { return item.Kind != yaml.MappingNode })
+	}
+	return true
+}
+
+// isSecret returns true for `ENC[<handle>]` placeholders, resolved by the secret backend of the agent
+func isSecret(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && strings.HasPrefix(node.Value, "ENC[") && strings.HasSuffix(node.Value, "]")
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+func typeDescription(t string) string {
+	switch t {
+	case typeInt:
+		return "an integer"
+	case typeList:
+		return "a list"
+	case typeMap:
+		return "a mapping"
+	case typeMapList:
+		return "a list of mappings"
+	default:
+		return "a " + t
+	}
+}
+
+func kindName(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	case yaml.AliasNode:
+		return "an alias"
+	default:
+		return fmt.Sprintf("%q", node.Value)
+	}
+}
+
+// closestKey returns the known key closest to key if it is likely a typo, empty otherwise
+func closestKey(schema map[string]any, key string) string {
+	best, bestDistance := "", maxTypoDistance+1
+	for known := range schema {
+		if distance := editDistance(key, known); distance < bestDistance || (distance == bestDistance && known < best) {
+			best, bestDistance = known, distance
+		}
+	}
+	// Short keys are too close to each other to guess
+	if bestDistance > maxTypoDistance || len(key) <= 2*bestDistance {
+		return ""
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
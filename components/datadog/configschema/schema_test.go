@@ -0,0 +1,107 @@
+package configschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		path     string
+		content  string
+		expected []string
+	}{
+		{
+			name:    "valid",
+			path:    "datadog.yaml",
+			content: "api_key: abc\nlogs_enabled: \"true\"\nunknown_key: 1\nlogs_config:\n  container_collect_all: true\nTags: [a, b]\n",
+		},
+		{
+			name:    "secrets",
+			path:    "datadog.yaml",
+			content: "api_key: ENC[api_key]\ncmd_port: ENC[port]\n",
+		},
+		{
+			name:     "typo",
+			path:     "datadog.yaml",
+			content:  "log_enabled: true\nlogs_config:\n  container_colect_all: true\n",
+			expected: []string{"datadog.yaml: log_enabled (line 1): unknown key, did you mean logs_enabled?", "datadog.yaml: logs_config.container_colect_all (line 3): unknown key, did you mean logs_config.container_collect_all?"},
+		},
+		{
+			name:     "wrong types",
+			path:     "datadog.yaml",
+			content:  "logs_enabled: [true]\ncmd_port: abc\nproxy: http://proxy\n",
+			expected: []string{"datadog.yaml: logs_enabled (line 1): expected a bool, got a list", `datadog.yaml: cmd_port (line 2): expected an integer, got "abc"`, `datadog.yaml: proxy (line 3): expected a mapping, got "http://proxy"`},
+		},
+		{
+			name:     "newer key",
+			version:  "7.61.0",
+			path:     "datadog.yaml",
+			content:  "ha_agent:\n  enabled: yes\n",
+			expected: []string{`datadog.yaml: ha_agent.enabled (line 2): expected a bool, got "yes"`},
+		},
+		{
+			name:     "latest minor version",
+			version:  "7",
+			path:     "datadog.yaml",
+			content:  "ha_agent: 1\n",
+			expected: []string{`datadog.yaml: ha_agent (line 1): expected a mapping, got "1"`},
+		},
+		{
+			name:    "key unknown in older version",
+			version: "7.52.1",
+			path:    "datadog.yaml",
+			content: "ha_agent: 1\n",
+		},
+		{
+			name:    "version older than the schemas",
+			version: "7.40.0",
+			path:    "datadog.yaml",
+			content: "logs_enabled: [true]\n",
+		},
+		{
+			name:    "version without schema",
+			version: "6.53.0",
+			path:    "datadog.yaml",
+			content: "logs_enabled: [true]\n",
+		},
+		{
+			name:     "integration",
+			path:     "conf.d/http_check.d/conf.yaml",
+			content:  "init_config:\ninstances:\n  - url: http://localhost\n  - http://localhost\n",
+			expected: []string{"conf.d/http_check.d/conf.yaml: instances (line 3): expected a list of mappings, got a list"},
+		},
+		{
+			name:    "file without schema",
+			path:    "other.yaml",
+			content: "logs_enabled: [true]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.version, tt.path, tt.content)
+			if len(tt.expected) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.ElementsMatch(t, tt.expected, splitErrors(err))
+		})
+	}
+}
+
+func splitErrors(err error) []string {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var messages []string
+		for _, e := range joined.Unwrap() {
+			messages = append(messages, e.Error())
+		}
+		return messages
+	}
+	return []string{err.Error()}
+}
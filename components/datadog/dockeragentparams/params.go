@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/DataDog/test-infra-definitions/common"
 	"github.com/DataDog/test-infra-definitions/common/config"
@@ -29,6 +30,7 @@ import (
 //	 - [WithFakeintake]
 //	 - [WithLogs]
 //   - [WithExtraComposeManifest]
+//   - [WithHealthCheck]
 //
 // [Functional options pattern]: https://dave.cheney.net/2014/10/17/functional-options-for-friendly-apis
 
@@ -52,6 +54,8 @@ type Params struct {
 	PulumiDependsOn []pulumi.ResourceOption
 	// FIPS is true if FIPS image is needed.
 	FIPS bool
+	// HealthTimeout is how long to wait for the Agent to be healthy once started, 0 to not wait.
+	HealthTimeout time.Duration
 }
 
 type Option = func(*Params) error
@@ -242,3 +246,12 @@ func WithExtraComposeInlineManifest(cpms ...docker.ComposeInlineManifest) func(*
 		return nil
 	}
 }
+
+// WithHealthCheck waits up to timeout for the Agent to be healthy once started.
+// The deployment fails with the logs of the Agent container if it is not, otherwise its status is exported.
+func WithHealthCheck(timeout time.Duration) func(*Params) error {
+	return func(p *Params) error {
+		p.HealthTimeout = timeout
+		return nil
+	}
+}
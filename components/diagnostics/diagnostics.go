@@ -0,0 +1,179 @@
+// Package diagnostics collects logs from hosts into local archives before they are destroyed,
+// so that failures can be investigated once the stack is gone.
+//
+// Collection is enabled with the `diagnostics` configuration, archives are written to `<diagnosticsDir>/<stack>`.
+// It is best effort: a host which cannot be reached is skipped and never blocks destroy.
+//
+// A collector is a local command fetching the bundle when it is deleted. When `pulumi up` fails, the deploy tasks
+// collect the bundles by destroying the collectors only, with `--target` and CollectEnvVar set,
+// the next update recreates them without side effect. Commands themselves are left untouched.
+package diagnostics
+
+import (
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"unicode/utf16"
+
+	"github.com/alessio/shellescape"
+	"github.com/pulumi/pulumi-command/sdk/go/command/local"
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+)
+
+// Collection modes, set with the `diagnostics` configuration
+const (
+	// ModeFailure collects diagnostics when `pulumi up` fails
+	ModeFailure = "failure"
+	// ModeAlways also collects diagnostics before the hosts are destroyed
+	ModeAlways = "always"
+)
+
+// CollectEnvVar must be set to true in the environment of `pulumi destroy` to collect diagnostics in failure mode
+const CollectEnvVar = "DIAGNOSTICS_COLLECT"
+
+// CollectorNamePrefix is the prefix of the name of the collector resources, to target them after a failed `pulumi up`
+const CollectorNamePrefix = "diagnostics-"
+
+type Bundle string
+
+const (
+	// HostBundle contains the system journal or event logs, cloud-init and package manager logs
+	HostBundle Bundle = "host"
+	// AgentBundle contains the agent logs, configuration and status
+	AgentBundle Bundle = "agent"
+)
+
+//go:embed scripts
+var scripts embed.FS
+
+type Args struct {
+	// Connection is the SSH connection to the host, proxies are supported
+	Connection remote.ConnectionOutput
+	Windows    bool
+	Bundle     Bundle
+}
+
+// Enabled returns true if diagnostics are collected, it fails if the `diagnostics` configuration is invalid
+func Enabled(e config.Env) (bool, error) {
+	switch mode := e.InfraDiagnostics(); mode {
+	case "":
+		return false, nil
+	case ModeFailure, ModeAlways:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unsupported diagnostics mode %q, expected %s or %s", mode, ModeFailure, ModeAlways)
+	}
+}
+
+// NewCollector creates a local command fetching the bundle from the host when it is deleted, the archive is named after name.
+// It must be deleted before the host and the resources whose logs are collected, it should depend on them.
+func NewCollector(e config.Env, name string, args Args, opts ...pulumi.ResourceOption) (*local.Command, error) {
+	fetchScript, err := scripts.ReadFile("scripts/fetch.sh")
+	if err != nil {
+		return nil, err
+	}
+	remoteCommand, err := collectCommand(args.Bundle, args.Windows)
+	if err != nil {
+		return nil, err
+	}
+
+	extension, encoding := ".tar.gz", ""
+	if args.Windows {
+		extension, encoding = ".zip", "base64"
+	}
+	output, err := filepath.Abs(filepath.Join(e.InfraDiagnosticsDir(), e.Ctx().Stack(), name+extension))
+	if err != nil {
+		return nil, err
+	}
+
+	onFailureOnly := ""
+	if e.InfraDiagnostics() == ModeFailure {
+		onFailureOnly = "true"
+	}
+
+	proxy := args.Connection.Proxy()
+	return local.NewCommand(e.Ctx(), CollectorNamePrefix+e.CommonNamer().ResourceName(name), &local.CommandArgs{
+		Delete: pulumi.String(string(fetchScript)),
+		Environment: pulumi.StringMap{
+			"DIAGNOSTICS_HOST":              args.Connection.Host(),
+			"DIAGNOSTICS_PORT":              portString(args.Connection.Port()),
+			"DIAGNOSTICS_USER":              args.Connection.User().Elem(),
+			"DIAGNOSTICS_PRIVATE_KEY":       args.Connection.PrivateKey().Elem(),
+			"DIAGNOSTICS_PASSWORD":          args.Connection.Password().Elem(),
+			"DIAGNOSTICS_PROXY_HOST":        proxy.Host().Elem(),
+			"DIAGNOSTICS_PROXY_PORT":        portString(proxy.Port()),
+			"DIAGNOSTICS_PROXY_USER":        proxy.User().Elem(),
+			"DIAGNOSTICS_PROXY_PRIVATE_KEY": proxy.PrivateKey().Elem(),
+			"DIAGNOSTICS_REMOTE_COMMAND":    pulumi.String(remoteCommand),
+			"DIAGNOSTICS_ENCODING":          pulumi.String(encoding),
+			"DIAGNOSTICS_ON_FAILURE_ONLY":   pulumi.String(onFailureOnly),
+			"DIAGNOSTICS_OUTPUT":            pulumi.String(output),
+		},
+	}, append(opts, e.WithProviders(config.ProviderCommand))...)
+}
+
+// collectCommand returns the command run on the host, it writes the bundle archive to stdout
+func collectCommand(bundle Bundle, windows bool) (string, error) {
+	if windows {
+		script, err := scripts.ReadFile(fmt.Sprintf("scripts/%s.ps1", bundle))
+		if err != nil {
+			return "", fmt.Errorf("unknown diagnostics bundle %s: %w", bundle, err)
+		}
+		return "powershell.exe -NoProfile -NonInteractive -EncodedCommand " + encodePowerShell(fmt.Sprintf(windowsCollectScript, script)), nil
+	}
+
+	script, err := scripts.ReadFile(fmt.Sprintf("scripts/%s.sh", bundle))
+	if err != nil {
+		return "", fmt.Errorf("unknown diagnostics bundle %s: %w", bundle, err)
+	}
+	collect := shellescape.Quote(fmt.Sprintf(unixCollectScript, script))
+	return fmt.Sprintf(`if [ "$(id -u)" = 0 ]; then sh -c %[1]s; else sudo -n sh -c %[1]s; fi`, collect), nil
+}
+
+// unixCollectScript runs the bundle script in a temporary directory, its output goes to stderr to keep stdout for the archive
+const unixCollectScript = `dir=$(mktemp -d) || exit 1
+cd "$dir" || exit 1
+exec 3>&1 1>&2
+%s
+tar -czf - . >&3
+cd / && rm -rf "$dir"
+`
+
+// windowsCollectScript runs the bundle script in a temporary directory and prints the archive encoded in base64 between markers
+const windowsCollectScript = `$ProgressPreference = 'SilentlyContinue'
+$dir = Join-Path $env:TEMP ('diagnostics-' + [guid]::NewGuid())
+New-Item -ItemType Directory -Path $dir | Out-Null
+Set-Location $dir
+& {
+%s
+} *> $null
+Set-Location $env:TEMP
+Compress-Archive -Path "$dir\*" -DestinationPath "$dir.zip"
+'BEGIN-DIAGNOSTICS'
+[Convert]::ToBase64String([IO.File]::ReadAllBytes("$dir.zip"), 'InsertLineBreaks')
+'END-DIAGNOSTICS'
+Remove-Item -Recurse -Force -Path $dir, "$dir.zip"
+`
+
+func encodePowerShell(script string) string {
+	encoded := utf16.Encode([]rune(script))
+	b := make([]byte, 0, len(encoded)*2)
+	for _, c := range encoded {
+		b = append(b, byte(c), byte(c>>8))
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+func portString(port pulumi.Float64PtrOutput) pulumi.StringOutput {
+	return port.ApplyT(func(p *float64) string {
+		if p == nil {
+			return "22"
+		}
+		return strconv.Itoa(int(*p))
+	}).(pulumi.StringOutput)
+}
@@ -0,0 +1,26 @@
+package diagnostics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodePowerShell(t *testing.T) {
+	// UTF-16LE, as expected by -EncodedCommand
+	assert.Equal(t, "ZABpAHIA", encodePowerShell("dir"))
+}
+
+func TestCollectCommand(t *testing.T) {
+	command, err := collectCommand(HostBundle, false)
+	require.NoError(t, err)
+	assert.Contains(t, command, "journalctl")
+
+	command, err = collectCommand(AgentBundle, true)
+	require.NoError(t, err)
+	assert.Contains(t, command, "-EncodedCommand ")
+
+	_, err = collectCommand("unknown", false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,10 @@
+New-Item -ItemType Directory agent | Out-Null
+Copy-Item -Recurse -Path "$env:ProgramData\Datadog\logs" -Destination agent\logs -ErrorAction SilentlyContinue
+Copy-Item -Path "$env:ProgramData\Datadog\*.yaml" -Destination agent -ErrorAction SilentlyContinue
+$agent = "$env:ProgramFiles\Datadog\Datadog Agent\bin\agent.exe"
+if (Test-Path $agent) {
+    & $agent status *> agent\status.log
+    & $agent configcheck *> agent\configcheck.log
+    & $agent health *> agent\health.log
+}
+Get-Service -Name datadog* -ErrorAction SilentlyContinue | Format-Table -AutoSize | Out-String -Width 300 | Out-File agent\services.log
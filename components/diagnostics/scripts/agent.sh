@@ -0,0 +1,13 @@
+mkdir agent
+cp -r /var/log/datadog agent/logs 2> /dev/null
+cp -r /etc/datadog-agent agent/config 2> /dev/null
+rm -f agent/config/auth_token agent/config/*.pem
+for agent in /opt/datadog-agent/bin/agent/agent /usr/bin/datadog-agent; do
+	if [ -x "$agent" ]; then
+		"$agent" status > agent/status.log 2>&1
+		"$agent" configcheck > agent/configcheck.log 2>&1
+		"$agent" health > agent/health.log 2>&1
+		break
+	fi
+done
+command -v systemctl > /dev/null && systemctl status 'datadog-agent*' --no-pager > agent/services.log 2>&1
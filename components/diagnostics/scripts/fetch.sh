@@ -0,0 +1,49 @@
+# Fetches the diagnostics bundle of a remote host over SSH.
+# It is best effort and always succeeds, so that it never blocks the deletion of the host.
+fetch() {
+	if [ -n "$DIAGNOSTICS_ON_FAILURE_ONLY" ] && [ "$DIAGNOSTICS_COLLECT" != true ]; then
+		echo "diagnostics of $DIAGNOSTICS_HOST are only collected when pulumi up fails"
+		return 0
+	fi
+
+	key=$(mktemp) || return 1
+	proxy_key=$(mktemp) || return 1
+	trap 'rm -f "$key" "$proxy_key" "$DIAGNOSTICS_OUTPUT.tmp"' EXIT
+
+	set -- -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -o LogLevel=ERROR \
+		-o ConnectTimeout=15 -o ServerAliveInterval=15 -o ServerAliveCountMax=4 -p "$DIAGNOSTICS_PORT"
+	if [ -n "$DIAGNOSTICS_PRIVATE_KEY" ]; then
+		printf '%s\n' "$DIAGNOSTICS_PRIVATE_KEY" > "$key"
+		set -- "$@" -i "$key"
+	fi
+	if [ -n "$DIAGNOSTICS_PROXY_HOST" ]; then
+		proxy_identity=""
+		if [ -n "$DIAGNOSTICS_PROXY_PRIVATE_KEY" ]; then
+			printf '%s\n' "$DIAGNOSTICS_PROXY_PRIVATE_KEY" > "$proxy_key"
+			proxy_identity="-i $proxy_key"
+		fi
+		set -- "$@" -o "ProxyCommand=ssh -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -o LogLevel=ERROR -o BatchMode=yes $proxy_identity -p $DIAGNOSTICS_PROXY_PORT -W %h:%p $DIAGNOSTICS_PROXY_USER@$DIAGNOSTICS_PROXY_HOST"
+	fi
+
+	ssh_command="ssh"
+	if [ -n "$DIAGNOSTICS_PASSWORD" ] && command -v sshpass > /dev/null; then
+		export SSHPASS="$DIAGNOSTICS_PASSWORD"
+		ssh_command="sshpass -e ssh"
+	else
+		set -- "$@" -o BatchMode=yes
+	fi
+	target="$DIAGNOSTICS_USER@$DIAGNOSTICS_HOST"
+
+	mkdir -p "$(dirname "$DIAGNOSTICS_OUTPUT")" || return 1
+	$ssh_command "$@" "$target" "$DIAGNOSTICS_REMOTE_COMMAND" > "$DIAGNOSTICS_OUTPUT.tmp" || return 1
+	if [ "$DIAGNOSTICS_ENCODING" = "base64" ]; then
+		# The archive is printed between markers, as PowerShell does not write binary data to stdout
+		sed -n '/^BEGIN-DIAGNOSTICS/,/^END-DIAGNOSTICS/p' "$DIAGNOSTICS_OUTPUT.tmp" | grep -v DIAGNOSTICS | tr -d '\r' | base64 -d > "$DIAGNOSTICS_OUTPUT" || return 1
+	else
+		mv "$DIAGNOSTICS_OUTPUT.tmp" "$DIAGNOSTICS_OUTPUT" || return 1
+	fi
+	echo "diagnostics of $DIAGNOSTICS_HOST written to $DIAGNOSTICS_OUTPUT"
+}
+
+fetch || echo "failed to collect diagnostics from $DIAGNOSTICS_HOST" >&2
+exit 0
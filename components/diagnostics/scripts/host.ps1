@@ -0,0 +1,7 @@
+foreach ($log in 'System', 'Application', 'Setup') {
+    wevtutil epl $log "$log.evtx"
+}
+New-Item -ItemType Directory packages | Out-Null
+Copy-Item -Path "$env:WINDIR\Logs\CBS\CBS.log", "$env:WINDIR\WindowsUpdate.log" -Destination packages -ErrorAction SilentlyContinue
+Get-ChildItem -Path $env:TEMP, "$env:WINDIR\Temp" -Filter *.log -ErrorAction SilentlyContinue | Copy-Item -Destination packages -ErrorAction SilentlyContinue
+Get-Service | Format-Table -AutoSize | Out-String -Width 300 | Out-File services.log
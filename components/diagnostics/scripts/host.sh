@@ -0,0 +1,13 @@
+if command -v journalctl > /dev/null; then
+	journalctl --no-pager -b > journal.log 2>&1
+else
+	cp /var/log/messages /var/log/syslog . 2> /dev/null
+fi
+dmesg > dmesg.log 2>&1
+
+mkdir cloud-init
+cp /var/log/cloud-init.log /var/log/cloud-init-output.log cloud-init/ 2> /dev/null
+command -v cloud-init > /dev/null && cloud-init status --long > cloud-init/status.log 2>&1
+
+mkdir packages
+cp -r /var/log/apt /var/log/dpkg.log /var/log/yum.log /var/log/dnf.log /var/log/dnf.rpm.log /var/log/zypp /var/log/zypper.log /var/log/pacman.log packages/ 2> /dev/null
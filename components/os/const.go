@@ -1,10 +1,5 @@
 package os
 
-import (
-	"fmt"
-	"strings"
-)
-
 type Architecture string
 
 const (
@@ -12,16 +7,13 @@ const (
 	ARM64Arch = Architecture("arm64")
 )
 
+// ArchitectureFromString panics if the architecture is unknown, see ParseArchitecture
 func ArchitectureFromString(archStr string) Architecture {
-	archStr = strings.ToLower(archStr)
-	switch archStr {
-	case "x86_64", "amd64", "", "x86_64_mac": // Default architecture is AMD64
-		return AMD64Arch
-	case "arm64", "aarch64", "arm64_mac":
-		return ARM64Arch
-	default:
-		panic(fmt.Sprintf("unknown architecture: %s", archStr))
+	arch, err := ParseArchitecture(archStr)
+	if err != nil {
+		panic(err.Error())
 	}
+	return arch
 }
 
 type Family int
@@ -49,6 +41,11 @@ const (
 	Fedora
 	CentOS
 	RockyLinux
+	Alpine
+	AlmaLinux
+	OracleLinux
+	OpenSuseLeap
+	ArchLinux
 
 	// Windows
 	WindowsServer Flavor = (500 + iota)
@@ -58,36 +55,13 @@ const (
 	MacosOS Flavor = (1000 + iota)
 )
 
+// FlavorFromString panics if the flavor is unknown, see ParseFlavor
 func FlavorFromString(flavorStr string) Flavor {
-	flavorStr = strings.ToLower(flavorStr)
-	switch flavorStr {
-	case "", "ubuntu": // Default flavor is Ubuntu
-		return Ubuntu
-	case "amazon-linux", "amazonlinux":
-		return AmazonLinux
-	case "amazon-linux-ecs", "amazonlinuxecs":
-		return AmazonLinuxECS
-	case "debian":
-		return Debian
-	case "redhat":
-		return RedHat
-	case "suse":
-		return Suse
-	case "fedora":
-		return Fedora
-	case "centos":
-		return CentOS
-	case "rocky-linux", "rockylinux":
-		return RockyLinux
-	case "windows", "windows-server":
-		return WindowsServer
-	case "windows-client":
-		return WindowsClient
-	case "macos":
-		return MacosOS
-	default:
-		panic(fmt.Sprintf("unknown OS flavor: %s", flavorStr))
+	flavor, err := ParseFlavor(flavorStr)
+	if err != nil {
+		panic(err.Error())
 	}
+	return flavor
 }
 
 func (f Flavor) Type() Family {
@@ -123,6 +97,16 @@ func (f Flavor) String() string {
 		return "centos"
 	case RockyLinux:
 		return "rocky-linux"
+	case Alpine:
+		return "alpine"
+	case AlmaLinux:
+		return "alma-linux"
+	case OracleLinux:
+		return "oracle-linux"
+	case OpenSuseLeap:
+		return "opensuse-leap"
+	case ArchLinux:
+		return "arch-linux"
 	case WindowsServer:
 		return "windows-server"
 	case WindowsClient:
@@ -1,7 +1,6 @@
 package os
 
 import (
-	"fmt"
 	"strings"
 )
 
@@ -29,24 +28,13 @@ func NewDescriptorWithArch(f Flavor, version string, arch Architecture) Descript
 }
 
 // String format is <flavor>:<version>(:<arch>)
+// DescriptorFromString panics if the descriptor is invalid, see ParseDescriptor
 func DescriptorFromString(descStr string, defaultDescriptor Descriptor) Descriptor {
-	if descStr == "" {
-		return defaultDescriptor
+	desc, err := ParseDescriptor(descStr, defaultDescriptor)
+	if err != nil {
+		panic(err.Error())
 	}
-
-	parts := strings.Split(descStr, osDescriptorSep)
-	if len(parts) < 2 || len(parts) > 3 {
-		panic(fmt.Sprintf("invalid OS descriptor string, was: %s", descStr))
-	}
-
-	flavor := FlavorFromString(parts[0])
-	version := parts[1]
-
-	if len(parts) == 3 {
-		return NewDescriptorWithArch(flavor, version, ArchitectureFromString(parts[2]))
-	}
-
-	return NewDescriptor(flavor, version)
+	return desc
 }
 
 func (d Descriptor) Family() Family {
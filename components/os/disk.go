@@ -0,0 +1,107 @@
+package os
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/DataDog/test-infra-definitions/common/utils"
+	"github.com/DataDog/test-infra-definitions/components/command"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+type FileSystem string
+
+const (
+	Ext4FileSystem FileSystem = "ext4"
+	XFSFileSystem  FileSystem = "xfs"
+	NTFSFileSystem FileSystem = "ntfs"
+)
+
+// maxLabelLength is the maximum length of a file system label per file system
+var maxLabelLength = map[FileSystem]int{
+	Ext4FileSystem: 16,
+	XFSFileSystem:  12,
+	NTFSFileSystem: 32,
+}
+
+var diskLabelRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Disk describes a data disk attached to a host
+type Disk struct {
+	// SizeGiB is the size of the disk. It is used to create the disk on the cloud side and to find the raw device on the host.
+	SizeGiB int
+	// Label is the file system label, it identifies the disk once formatted
+	Label string
+	// FileSystem defaults to ext4 on Linux and NTFS on Windows
+	FileSystem FileSystem
+	// MountPoint is a directory on Linux, a drive letter like `E:` or an empty directory on Windows.
+	// The disk is only attached and left unformatted if empty.
+	MountPoint string
+}
+
+// withDefaults returns the disk with its default file system set and checks that it can be mounted
+func (d Disk) withDefaults(defaultFileSystem FileSystem, supported ...FileSystem) (Disk, error) {
+	if d.FileSystem == "" {
+		d.FileSystem = defaultFileSystem
+	}
+
+	isSupported := false
+	for _, fs := range supported {
+		isSupported = isSupported || fs == d.FileSystem
+	}
+	if !isSupported {
+		return d, fmt.Errorf("unsupported file system %q, expected one of %v", d.FileSystem, supported)
+	}
+	if d.SizeGiB <= 0 {
+		return d, fmt.Errorf("invalid size %d for disk %q", d.SizeGiB, d.Label)
+	}
+	if d.MountPoint == "" {
+		return d, fmt.Errorf("missing mount point for disk %q", d.Label)
+	}
+	if !diskLabelRegexp.MatchString(d.Label) || len(d.Label) > maxLabelLength[d.FileSystem] {
+		return d, fmt.Errorf("invalid label %q, it must be at most %d alphanumeric, '-' or '_' characters for %s", d.Label, maxLabelLength[d.FileSystem], d.FileSystem)
+	}
+
+	return d, nil
+}
+
+// DiskSizes returns the size in GiB of each disk, to be attached on the cloud side
+func DiskSizes(disks []Disk) []int {
+	sizes := make([]int, 0, len(disks))
+	for _, disk := range disks {
+		sizes = append(sizes, disk.SizeGiB)
+	}
+	return sizes
+}
+
+// MountDisks mounts the disks having a mount point, one after the other so that disks of the same size are not picked twice
+func MountDisks(o OS, disks []Disk, opts ...pulumi.ResourceOption) ([]command.Command, error) {
+	mounts := make([]command.Command, 0, len(disks))
+	for _, disk := range disks {
+		if disk.MountPoint == "" {
+			continue
+		}
+
+		mountOpts := opts
+		if len(mounts) > 0 {
+			mountOpts = utils.MergeOptions(opts, utils.PulumiDependsOn(mounts[len(mounts)-1]))
+		}
+		mount, err := o.DiskManager().EnsureMounted(disk, nil, mountOpts...)
+		if err != nil {
+			return nil, err
+		}
+		mounts = append(mounts, mount)
+	}
+
+	return mounts, nil
+}
+
+// unsupportedDiskManager is used on systems where disks cannot be managed
+type unsupportedDiskManager struct {
+	osName string
+}
+
+func (m unsupportedDiskManager) EnsureMounted(Disk, command.Transformer, ...pulumi.ResourceOption) (command.Command, error) {
+	return nil, fmt.Errorf("disk management is not supported on %s", m.osName)
+}
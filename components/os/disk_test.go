@@ -0,0 +1,28 @@
+package os
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskWithDefaults(t *testing.T) {
+	disk, err := Disk{SizeGiB: 10, Label: "data", MountPoint: "/data"}.withDefaults(Ext4FileSystem, Ext4FileSystem, XFSFileSystem)
+	require.NoError(t, err)
+	assert.Equal(t, Ext4FileSystem, disk.FileSystem)
+
+	tests := map[string]Disk{
+		"unsupported file system": {SizeGiB: 10, Label: "data", MountPoint: "/data", FileSystem: NTFSFileSystem},
+		"missing size":            {Label: "data", MountPoint: "/data"},
+		"missing mount point":     {SizeGiB: 10, Label: "data"},
+		"invalid label":           {SizeGiB: 10, Label: "my data", MountPoint: "/data"},
+		"label too long for xfs":  {SizeGiB: 10, Label: "thirteen-char", MountPoint: "/data", FileSystem: XFSFileSystem},
+	}
+	for name, disk := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := disk.withDefaults(Ext4FileSystem, Ext4FileSystem, XFSFileSystem)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package os
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/test-infra-definitions/components/command"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// firewallRulePrefix tags every rule created by a Firewall so that they can be removed by Reset
+const firewallRulePrefix = "test-infra-"
+
+var firewallRuleIDInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9-]+`)
+
+// firewallRuleID returns an identifier usable as a rule comment or name in all firewall back ends
+func firewallRuleID(parts ...string) string {
+	return firewallRulePrefix + firewallRuleIDInvalidChars.ReplaceAllString(strings.Join(parts, "-"), "-")
+}
+
+// isIPOrCIDR returns true if the destination does not need to be resolved
+func isIPOrCIDR(destination string) bool {
+	if net.ParseIP(destination) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(destination)
+	return err == nil
+}
+
+func validateFirewallProtocol(protocol FirewallProtocol) error {
+	switch protocol {
+	case TCPProtocol, UDPProtocol:
+		return nil
+	default:
+		return fmt.Errorf("unsupported firewall protocol %q", protocol)
+	}
+}
+
+// unsupportedFirewall is used on systems without a supported firewall back end
+type unsupportedFirewall struct {
+	osName string
+}
+
+func (f unsupportedFirewall) AllowPort(int, FirewallProtocol, command.Transformer, ...pulumi.ResourceOption) (command.Command, error) {
+	return nil, fmt.Errorf("firewall is not supported on %s", f.osName)
+}
+
+func (f unsupportedFirewall) BlockEgress(string, command.Transformer, ...pulumi.ResourceOption) (command.Command, error) {
+	return nil, fmt.Errorf("firewall is not supported on %s", f.osName)
+}
+
+func (f unsupportedFirewall) Reset(command.Transformer, ...pulumi.ResourceOption) (command.Command, error) {
+	return nil, fmt.Errorf("firewall is not supported on %s", f.osName)
+}
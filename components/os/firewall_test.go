@@ -0,0 +1,77 @@
+package os
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUFWFirewall(t *testing.T) {
+	runner := &fakeRunner{}
+	firewall := newLinuxFirewall(fakeEnv{}, Ubuntu2204, runner)
+
+	_, err := firewall.AllowPort(8080, TCPProtocol, nil)
+	require.NoError(t, err)
+	_, err = firewall.BlockEgress("10.0.0.1", nil)
+	require.NoError(t, err)
+	_, err = firewall.Reset(nil)
+	require.NoError(t, err)
+
+	require.Len(t, runner.commands, 3)
+	assert.NotContains(t, createCommand(t, runner.commands[0]), "ufw --force enable")
+	assert.Contains(t, createCommand(t, runner.commands[1]), "ufw default allow incoming && ufw --force enable")
+	assert.NotContains(t, createCommand(t, runner.commands[1]), "ufw allow 22/tcp")
+	assert.NotContains(t, createCommand(t, runner.commands[2]), "ufw --force enable")
+}
+
+func TestUFWBlockEgressWithoutRules(t *testing.T) {
+	runner := &fakeRunner{}
+	_, err := newLinuxFirewall(fakeEnv{}, Ubuntu2204, runner).BlockEgress("10.0.0.1", nil)
+	require.NoError(t, err)
+
+	require.Len(t, runner.commands, 1)
+	// ufw cannot insert at position 1 in an empty ruleset, as right after being enabled
+	assert.Contains(t, createCommand(t, runner.commands[0]),
+		`if ufw status numbered | grep -q "^\["; then ufw insert 1 deny out to 10.0.0.1 comment test-infra-egress-10-0-0-1; else ufw deny out to 10.0.0.1 comment test-infra-egress-10-0-0-1; fi`)
+}
+
+func TestRedHatFirewallDoesNotStartFirewalld(t *testing.T) {
+	runner := &fakeRunner{}
+	firewall := newLinuxFirewall(fakeEnv{}, RedHat9, runner)
+
+	_, err := firewall.AllowPort(8080, TCPProtocol, nil)
+	require.NoError(t, err)
+	_, err = firewall.BlockEgress("10.0.0.1", nil)
+	require.NoError(t, err)
+
+	require.Len(t, runner.commands, 2)
+	for _, cmd := range runner.commands {
+		create := createCommand(t, cmd)
+		assert.NotContains(t, create, "systemctl enable")
+		assert.Contains(t, create, "if systemctl is-active --quiet firewalld; then firewall-cmd ")
+		assert.Contains(t, create, "; elif command -v nft > /dev/null; then nft add table inet test_infra")
+		assert.Contains(t, create, "; else iptables -I ")
+
+		remove, ok := cmd.Delete.(pulumi.String)
+		require.True(t, ok)
+		assert.NotContains(t, string(remove), "systemctl enable")
+		assert.NotContains(t, string(remove), "nft add table")
+	}
+}
+
+func TestFirewallResetDoesNotEnableFirewall(t *testing.T) {
+	for _, desc := range []Descriptor{Debian12, RedHat9, AmazonLinux2} {
+		t.Run(desc.String(), func(t *testing.T) {
+			runner := &fakeRunner{}
+			_, err := newLinuxFirewall(fakeEnv{}, desc, runner).Reset(nil)
+			require.NoError(t, err)
+
+			require.Len(t, runner.commands, 1)
+			reset := createCommand(t, runner.commands[0])
+			assert.NotContains(t, reset, "nft add table")
+			assert.NotContains(t, reset, "enable --now firewalld")
+		})
+	}
+}
@@ -11,15 +11,37 @@ import (
 )
 
 type GenericPackageManager struct {
-	namer              namer.Namer
+	packageManagerCommands
+	name               string
 	updateDBCommand    command.Command
-	runner             command.Runner
-	opts               []pulumi.ResourceOption
 	installCmd         string
 	updateCmd          string
 	uninstallCmd       string
 	env                pulumi.StringMap
+	sudo               bool
 	packageNameMapping map[string]string
+	specifics          packageManagerSpecifics
+}
+
+// packageManagerCommands runs the commands of a package manager one after the other
+type packageManagerCommands struct {
+	namer  namer.Namer
+	runner command.Runner
+	// opts make the next command depend on the previous ones
+	opts []pulumi.ResourceOption
+}
+
+// packageManagerSpecifics holds the commands that cannot be expressed as `<cmd> <package>` and differ between package managers.
+// A nil field means the feature is not supported by the package manager.
+type packageManagerSpecifics struct {
+	// pinVersion returns the package reference to install the given version of a package
+	pinVersion func(packageRef, version string) string
+	// downgradeFlag is the install flag allowing to install an older version than the installed one
+	downgradeFlag string
+	// addRepository returns the create and delete commands for a repository
+	addRepository func(repository PackageRepository) (string, string)
+	hold          func(packageRef string) string
+	unhold        func(packageRef string) string
 }
 
 func NewGenericPackageManager(
@@ -32,18 +54,33 @@ func NewGenericPackageManager(
 	pacakgeNameMapping map[string]string,
 ) *GenericPackageManager {
 	packageManager := &GenericPackageManager{
-		namer:              namer.NewNamer(runner.Environment().Ctx(), name),
-		runner:             runner,
+		packageManagerCommands: packageManagerCommands{
+			namer:  namer.NewNamer(runner.Environment().Ctx(), name),
+			runner: runner,
+		},
+		name:               name,
 		installCmd:         installCmd,
 		updateCmd:          updateCmd,
 		uninstallCmd:       uninstallCmd,
 		env:                env,
+		sudo:               true,
 		packageNameMapping: pacakgeNameMapping,
 	}
 
 	return packageManager
 }
 
+func (m *GenericPackageManager) withSpecifics(specifics packageManagerSpecifics) *GenericPackageManager {
+	m.specifics = specifics
+	return m
+}
+
+// withoutSudo runs the commands as the connection user, for package managers refusing to run as root
+func (m *GenericPackageManager) withoutSudo() *GenericPackageManager {
+	m.sudo = false
+	return m
+}
+
 func (m *GenericPackageManager) EnsureUninstalled(packageRef string, transform command.Transformer, checkBinary string, opts ...PackageManagerOption) (command.Command, error) {
 	params, err := common.ApplyOption(&PackageManagerParams{}, opts)
 	if err != nil {
@@ -63,7 +100,7 @@ func (m *GenericPackageManager) EnsureUninstalled(packageRef string, transform c
 	var cmdArgs command.RunnerCommandArgs = &command.Args{
 		Create:      pulumi.String(cmdStr),
 		Environment: m.env,
-		Sudo:        true,
+		Sudo:        m.sudo,
 	}
 
 	// If a transform is provided, use it to modify the command name and args
@@ -100,18 +137,30 @@ func (m *GenericPackageManager) Ensure(packageRef string, transform command.Tran
 		packageRef = dedicatedPackageRef
 	}
 
+	installCmd := m.installCmd
+	if params.Version != "" {
+		if m.specifics.pinVersion == nil {
+			return nil, fmt.Errorf("version pinning is not supported by %s", m.name)
+		}
+		packageRef = m.specifics.pinVersion(packageRef, params.Version)
+		checkBinary = ""
+		if m.specifics.downgradeFlag != "" {
+			installCmd += " " + m.specifics.downgradeFlag
+		}
+	}
+
 	var cmdStr string
 	if checkBinary != "" {
-		cmdStr = fmt.Sprintf("bash -c 'command -v %s || %s %s'", checkBinary, m.installCmd, packageRef)
+		cmdStr = fmt.Sprintf("bash -c 'command -v %s || %s %s'", checkBinary, installCmd, packageRef)
 	} else {
-		cmdStr = fmt.Sprintf("%s %s", m.installCmd, packageRef)
+		cmdStr = fmt.Sprintf("%s %s", installCmd, packageRef)
 	}
 
 	cmdName := m.namer.ResourceName("install-"+packageRef, utils.StrHash(cmdStr))
 	var cmdArgs command.RunnerCommandArgs = &command.Args{
 		Create:      pulumi.String(cmdStr),
 		Environment: m.env,
-		Sudo:        true,
+		Sudo:        m.sudo,
 	}
 
 	// If a transform is provided, use it to modify the command name and args
@@ -129,6 +178,70 @@ func (m *GenericPackageManager) Ensure(packageRef string, transform command.Tran
 	return cmd, nil
 }
 
+func (m *GenericPackageManager) AddRepository(repository PackageRepository, transform command.Transformer, opts ...PackageManagerOption) (command.Command, error) {
+	if m.specifics.addRepository == nil {
+		return nil, fmt.Errorf("adding repositories is not supported by %s", m.name)
+	}
+
+	createCmd, deleteCmd := m.specifics.addRepository(repository)
+	return m.runCommand("add-repository-"+repository.Name, createCmd, deleteCmd, transform, opts)
+}
+
+func (m *GenericPackageManager) Hold(packageRef string, transform command.Transformer, opts ...PackageManagerOption) (command.Command, error) {
+	if m.specifics.hold == nil || m.specifics.unhold == nil {
+		return nil, fmt.Errorf("holding packages is not supported by %s", m.name)
+	}
+
+	return m.runCommand("hold-"+packageRef, m.specifics.hold(packageRef), m.specifics.unhold(packageRef), transform, opts)
+}
+
+func (m *GenericPackageManager) Unhold(packageRef string, transform command.Transformer, opts ...PackageManagerOption) (command.Command, error) {
+	if m.specifics.unhold == nil {
+		return nil, fmt.Errorf("holding packages is not supported by %s", m.name)
+	}
+
+	return m.runCommand("unhold-"+packageRef, m.specifics.unhold(packageRef), "", transform, opts)
+}
+
+// runCommand runs a package manager command after all the previous ones, deleteCmd is not run if empty
+func (m *GenericPackageManager) runCommand(name string, createCmd string, deleteCmd string, transform command.Transformer, opts []PackageManagerOption) (command.Command, error) {
+	return m.packageManagerCommands.run(name, createCmd, deleteCmd, m.env, m.sudo, transform, opts)
+}
+
+// run runs a command after all the previous ones, deleteCmd is not run if empty
+func (c *packageManagerCommands) run(name string, createCmd string, deleteCmd string, env pulumi.StringMap, sudo bool, transform command.Transformer, opts []PackageManagerOption) (command.Command, error) {
+	params, err := common.ApplyOption(&PackageManagerParams{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	pulumiOpts := append(params.PulumiResourceOptions, c.opts...)
+
+	cmdName := c.namer.ResourceName(name, utils.StrHash(createCmd))
+	args := &command.Args{
+		Create:      pulumi.String(createCmd),
+		Environment: env,
+		Sudo:        sudo,
+	}
+	if deleteCmd != "" {
+		args.Delete = pulumi.String(deleteCmd)
+	}
+	var cmdArgs command.RunnerCommandArgs = args
+
+	// If a transform is provided, use it to modify the command name and args
+	if transform != nil {
+		cmdName, cmdArgs = transform(cmdName, cmdArgs)
+	}
+
+	cmd, err := c.runner.Command(cmdName, cmdArgs, pulumiOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure the package manager isn't running in parallel
+	c.opts = append(c.opts, utils.PulumiDependsOn(cmd))
+	return cmd, nil
+}
+
 func (m *GenericPackageManager) updateDB(opts []pulumi.ResourceOption) (command.Command, error) {
 	if m.updateDBCommand != nil {
 		return m.updateDBCommand, nil
@@ -139,7 +252,7 @@ func (m *GenericPackageManager) updateDB(opts []pulumi.ResourceOption) (command.
 		&command.Args{
 			Create:      pulumi.String(m.updateCmd),
 			Environment: m.env,
-			Sudo:        true,
+			Sudo:        m.sudo,
 		}, opts...)
 	if err == nil {
 		m.updateDBCommand = c
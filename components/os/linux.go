@@ -12,6 +12,10 @@ func newLinuxOS(e config.Env, desc Descriptor, runner command.Runner) OS {
 		descriptor:  desc,
 		runner:      runner,
 		fileManager: command.NewFileManager(runner),
+		userManager: newLinuxUserManager(e, runner),
+		firewall:    newLinuxFirewall(e, desc, runner),
+		diskManager: newLinuxDiskManager(e, runner),
+		trustStore:  newLinuxTrustStore(e, desc, runner),
 	}
 
 	switch desc.Flavor {
@@ -19,16 +23,22 @@ func newLinuxOS(e config.Env, desc Descriptor, runner command.Runner) OS {
 		// AL2 is YUM, AL2023 is DNF (but with yum compatibility)
 		os.packageManager = newYumManager(runner)
 
-	case Fedora, RedHat, RockyLinux:
+	case Fedora, RedHat, RockyLinux, AlmaLinux, OracleLinux:
 		os.packageManager = newDnfManager(runner)
 
 	case Debian, Ubuntu:
 		os.packageManager = newAptManager(runner)
 
-	case Suse:
+	case Suse, OpenSuseLeap:
 		os.packageManager = newZypperManager(runner)
 
-	case Unknown, WindowsServer, MacosOS:
+	case Alpine:
+		os.packageManager = newApkManager(runner)
+
+	case ArchLinux:
+		os.packageManager = newPacmanManager(runner)
+
+	case Unknown, WindowsServer, WindowsClient, MacosOS:
 		fallthrough
 	default:
 		panic(fmt.Sprintf("unsupported linux flavor from desc: %+v", desc))
@@ -36,6 +46,8 @@ func newLinuxOS(e config.Env, desc Descriptor, runner command.Runner) OS {
 
 	if desc.Flavor == AmazonLinux2018.Flavor && desc.Version == AmazonLinux2018.Version {
 		os.serviceManager = newSysvinitServiceManager(e, runner)
+	} else if desc.Flavor == Alpine {
+		os.serviceManager = newOpenRCServiceManager(e, runner)
 	} else {
 		os.serviceManager = newSystemdServiceManager(e, runner)
 	}
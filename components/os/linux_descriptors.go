@@ -31,6 +31,21 @@ var (
 
 	CentOSDefault = CentOS7
 	CentOS7       = NewDescriptor(CentOS, "79")
+
+	AlpineDefault = Alpine320
+	Alpine320     = NewDescriptor(Alpine, "3-20")
+
+	AlmaLinuxDefault = AlmaLinux9
+	AlmaLinux9       = NewDescriptor(AlmaLinux, "9")
+
+	OracleLinuxDefault = OracleLinux9
+	OracleLinux9       = NewDescriptor(OracleLinux, "9")
+
+	OpenSuseLeapDefault = OpenSuseLeap156
+	OpenSuseLeap156     = NewDescriptor(OpenSuseLeap, "15-6")
+
+	// Arch Linux is a rolling release, there is a single version
+	ArchLinuxDefault = NewDescriptor(ArchLinux, "rolling")
 )
 
 var LinuxDescriptorsDefault = map[Flavor]Descriptor{
@@ -42,4 +57,9 @@ var LinuxDescriptorsDefault = map[Flavor]Descriptor{
 	Suse:           SuseDefault,
 	Fedora:         FedoraDefault,
 	CentOS:         CentOSDefault,
+	Alpine:         AlpineDefault,
+	AlmaLinux:      AlmaLinuxDefault,
+	OracleLinux:    OracleLinuxDefault,
+	OpenSuseLeap:   OpenSuseLeapDefault,
+	ArchLinux:      ArchLinuxDefault,
 }
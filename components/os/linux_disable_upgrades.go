@@ -12,3 +12,6 @@ var SSHAllowSFTPRootScriptContent string
 
 //go:embed scripts/zypper-disable-unattended-upgrades.sh
 var ZypperDisableUnattendedUpgradesScriptContent string
+
+//go:embed scripts/apk-install-bash-sudo.sh
+var APKInstallBashSudoScriptContent string
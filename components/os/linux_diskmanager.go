@@ -0,0 +1,56 @@
+package os
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/components/command"
+
+	"github.com/alessio/shellescape"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+type linuxDiskManager struct {
+	e      config.Env
+	runner command.Runner
+}
+
+func newLinuxDiskManager(e config.Env, runner command.Runner) DiskManager {
+	return &linuxDiskManager{e: e, runner: runner}
+}
+
+func (m *linuxDiskManager) EnsureMounted(disk Disk, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	disk, err := disk.withDefaults(Ext4FileSystem, Ext4FileSystem, XFSFileSystem)
+	if err != nil {
+		return nil, err
+	}
+
+	fstabEntry := fmt.Sprintf("LABEL=%s %s %s defaults,nofail 0 2", disk.Label, disk.MountPoint, disk.FileSystem)
+	create := []string{
+		"set -e",
+		// The disk is only formatted if no file system has the label, the first whole disk of the expected size without partition nor file system is used
+		fmt.Sprintf(`if ! blkid -L %s > /dev/null; then`, disk.Label),
+		fmt.Sprintf(`DISK=$(for dev in $(lsblk -dpnbo NAME,SIZE,TYPE | awk '$2 == %d && $3 == "disk" { print $1 }'); do if [ "$(lsblk -npo NAME "$dev" | wc -l)" -eq 1 ] && [ -z "$(blkid -p -o value -s TYPE "$dev")" ]; then echo "$dev"; break; fi; done)`, int64(disk.SizeGiB)<<30),
+		fmt.Sprintf(`if [ -z "$DISK" ]; then echo "no unused disk of %dGiB found" >&2; exit 1; fi`, disk.SizeGiB),
+		`printf 'label: gpt\n,,L\n' | sfdisk --quiet "$DISK"`,
+		`udevadm settle 2> /dev/null || sleep 2`,
+		`PARTITION=$(lsblk -npo NAME "$DISK" | sed -n 2p)`,
+		fmt.Sprintf(`mkfs.%s -L %s "$PARTITION"`, disk.FileSystem, disk.Label),
+		"fi",
+		fmt.Sprintf("mkdir -p %s", disk.MountPoint),
+		fmt.Sprintf("grep -q '^LABEL=%s ' /etc/fstab || echo '%s' >> /etc/fstab", disk.Label, fstabEntry),
+		fmt.Sprintf("mountpoint -q %[1]s || mount %[1]s", disk.MountPoint),
+	}
+	// The data is kept on destroy, only the mount and the fstab entry are removed
+	del := []string{
+		fmt.Sprintf("umount %s || true", disk.MountPoint),
+		fmt.Sprintf(`sed -i '/^LABEL=%s /d' /etc/fstab`, disk.Label),
+	}
+
+	return runNamedCommand(m.e, m.runner, "disk", disk.Label, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String("bash -c " + shellescape.Quote(strings.Join(create, "\n"))),
+		Delete: pulumi.String("bash -c " + shellescape.Quote(strings.Join(del, "; "))),
+	}, transform, opts...)
+}
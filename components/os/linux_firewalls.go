@@ -0,0 +1,255 @@
+package os
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/components/command"
+
+	"github.com/alessio/shellescape"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// linuxFirewallBackend generates the shell commands of a Linux firewall tool
+type linuxFirewallBackend struct {
+	// setup is run before adding or removing rules, it may be empty
+	setup string
+	// egressSetup is run after setup before adding outbound rules, it may be empty
+	egressSetup string
+	// allowPort returns the commands adding and removing an inbound rule
+	allowPort func(id string, port int, protocol FirewallProtocol) (string, string)
+	// blockEgress returns the command adding an outbound drop rule, address may be a shell variable
+	blockEgress func(id string, address string) string
+	// deleteRules returns the command removing the rules with the given identifier, or all rules starting with it if prefix is true
+	deleteRules func(id string, prefix bool) string
+	// reset removes all the rules without setup, deleteRules is used with firewallRulePrefix if empty
+	reset string
+}
+
+// ruleMatchSuffix returns the regular expression matching the end of an identifier unless prefix is true
+func ruleMatchSuffix(prefix bool, suffix string) string {
+	if prefix {
+		return ""
+	}
+	return suffix
+}
+
+var iptablesBackend = linuxFirewallBackend{
+	allowPort: func(id string, port int, protocol FirewallProtocol) (string, string) {
+		rule := fmt.Sprintf("INPUT -p %[1]s --dport %[2]d -m comment --comment %[3]s -j ACCEPT", protocol, port, id)
+		return "iptables -I " + rule, "iptables -D " + rule
+	},
+	blockEgress: func(id string, address string) string {
+		return fmt.Sprintf("iptables -I OUTPUT -d %s -m comment --comment %s -j DROP", address, id)
+	},
+	deleteRules: func(id string, prefix bool) string {
+		return fmt.Sprintf(`for chain in INPUT OUTPUT; do iptables -S $chain | grep -E -- "--comment %s%s" | sed "s/^-A/-D/" | while read -r rule; do iptables $rule; done; done`,
+			id, ruleMatchSuffix(prefix, "( |$)"))
+	},
+}
+
+// nftablesBackend keeps its rules in a dedicated table to not interfere with the existing configuration.
+// As every table sees all the packets, its accept rules do not override drop rules of other tables.
+var nftablesBackend = linuxFirewallBackend{
+	setup: `nft add table inet test_infra && ` +
+		`nft add chain inet test_infra input "{ type filter hook input priority 0; policy accept; }" && ` +
+		`nft add chain inet test_infra output "{ type filter hook output priority 0; policy accept; }"`,
+	allowPort: func(id string, port int, protocol FirewallProtocol) (string, string) {
+		return fmt.Sprintf(`nft add rule inet test_infra input %s dport %d accept comment \"%s\"`, protocol, port, id), nftablesDeleteRules(id, false)
+	},
+	blockEgress: func(id string, address string) string {
+		return fmt.Sprintf(`nft add rule inet test_infra output ip daddr %s drop comment \"%s\"`, address, id)
+	},
+	deleteRules: nftablesDeleteRules,
+	reset:       "(! nft list table inet test_infra > /dev/null 2>&1 || nft delete table inet test_infra)",
+}
+
+func nftablesDeleteRules(id string, prefix bool) string {
+	return fmt.Sprintf(`for chain in input output; do nft -a list chain inet test_infra $chain | grep -E "comment \"%s%s" | sed -E "s/.*# handle ([0-9]+)$/\1/" | while read -r handle; do nft delete rule inet test_infra $chain handle $handle; done; done`,
+		id, ruleMatchSuffix(prefix, `\"`))
+}
+
+// firewalldBackend requires firewalld to be running, rules are only added to the runtime configuration
+var firewalldBackend = linuxFirewallBackend{
+	allowPort: func(_ string, port int, protocol FirewallProtocol) (string, string) {
+		return fmt.Sprintf("firewall-cmd --add-port=%d/%s", port, protocol), fmt.Sprintf("firewall-cmd --remove-port=%d/%s", port, protocol)
+	},
+	blockEgress: func(id string, address string) string {
+		return fmt.Sprintf("firewall-cmd --direct --add-rule ipv4 filter OUTPUT 0 -d %s -m comment --comment %s -j DROP", address, id)
+	},
+	deleteRules: func(id string, prefix bool) string {
+		return fmt.Sprintf(`firewall-cmd --direct --get-all-rules | grep -E -- "--comment %s%s" | while read -r rule; do firewall-cmd --direct --remove-rule $rule; done`,
+			id, ruleMatchSuffix(prefix, "( |$)"))
+	},
+	// Reloading drops the runtime configuration, including opened ports which have no identifier
+	reset: "(! systemctl is-active --quiet firewalld || firewall-cmd --reload)",
+}
+
+// ufwBackend only enables ufw to block egress as inbound traffic is not filtered while it is inactive.
+// When enabled, inbound traffic is allowed by default to only apply the requested rules.
+var ufwBackend = linuxFirewallBackend{
+	egressSetup: `(ufw status | grep -q "Status: active" || (ufw default allow incoming && ufw --force enable))`,
+	allowPort: func(id string, port int, protocol FirewallProtocol) (string, string) {
+		return fmt.Sprintf("ufw allow %d/%s comment %s", port, protocol, id), fmt.Sprintf("ufw delete allow %d/%s", port, protocol)
+	},
+	blockEgress: func(id string, address string) string {
+		// Inserting at position 1 fails when there are no rules yet, as right after enabling ufw
+		return fmt.Sprintf(`if ufw status numbered | grep -q "^\["; then ufw insert 1 deny out to %[1]s comment %[2]s; else ufw deny out to %[1]s comment %[2]s; fi`, address, id)
+	},
+	deleteRules: func(id string, prefix bool) string {
+		// Rules are deleted by number in reverse order as numbers shift after each deletion
+		return fmt.Sprintf(`for n in $(ufw status numbered | grep -E "# %s%s" | sed -E "s/^\[ *([0-9]+)\].*/\1/" | sort -rn); do ufw --force delete $n; done`,
+			id, ruleMatchSuffix(prefix, " *$"))
+	},
+}
+
+// firewalldOrNftablesBackend uses firewalld when it is already running, as its reload drops other runtime rules.
+// Otherwise, it does not start it as its default zone would block all the ports which are not explicitly opened, nftables or iptables are used instead.
+var firewalldOrNftablesBackend = conditionalBackend(
+	backendChoice{"systemctl is-active --quiet firewalld", firewalldBackend},
+	backendChoice{"command -v nft > /dev/null", nftablesBackend},
+	backendChoice{"", iptablesBackend},
+)
+
+// backendChoice is a backend used when its condition succeeds on the host, an empty condition always succeeds
+type backendChoice struct {
+	condition string
+	backend   linuxFirewallBackend
+}
+
+// conditionalBackend returns a backend using the first choice whose condition succeeds, the last choice must have an empty condition.
+// The setup of the chosen backend is part of its commands adding rules.
+func conditionalBackend(choices ...backendChoice) linuxFirewallBackend {
+	choose := func(command func(backend linuxFirewallBackend) string) string {
+		script := ""
+		for i, choice := range choices {
+			switch {
+			case i == 0:
+				script += fmt.Sprintf("if %s; then ", choice.condition)
+			case choice.condition != "":
+				script += fmt.Sprintf("; elif %s; then ", choice.condition)
+			default:
+				script += "; else "
+			}
+			script += command(choice.backend)
+		}
+		return script + "; fi"
+	}
+
+	return linuxFirewallBackend{
+		allowPort: func(id string, port int, protocol FirewallProtocol) (string, string) {
+			create := choose(func(backend linuxFirewallBackend) string {
+				createCmd, _ := backend.allowPort(id, port, protocol)
+				return joinCommands(backend.setup, createCmd)
+			})
+			remove := choose(func(backend linuxFirewallBackend) string {
+				_, deleteCmd := backend.allowPort(id, port, protocol)
+				return deleteCmd
+			})
+			return create, remove
+		},
+		blockEgress: func(id string, address string) string {
+			return choose(func(backend linuxFirewallBackend) string {
+				return joinCommands(backend.setup, backend.egressSetup, backend.blockEgress(id, address))
+			})
+		},
+		deleteRules: func(id string, prefix bool) string {
+			return choose(func(backend linuxFirewallBackend) string { return backend.deleteRules(id, prefix) })
+		},
+		reset: choose(func(backend linuxFirewallBackend) string {
+			if backend.reset != "" {
+				return backend.reset
+			}
+			return backend.deleteRules(firewallRulePrefix, true)
+		}),
+	}
+}
+
+// joinCommands joins the non-empty commands
+func joinCommands(commands ...string) string {
+	nonEmpty := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		if cmd != "" {
+			nonEmpty = append(nonEmpty, cmd)
+		}
+	}
+	return strings.Join(nonEmpty, "; ")
+}
+
+// firewallBackendFromDescriptor picks the firewall tool shipped by default with the distribution
+func firewallBackendFromDescriptor(desc Descriptor) linuxFirewallBackend {
+	switch desc.Flavor { // nolint:exhaustive
+	case Ubuntu:
+		return ufwBackend
+	case Debian, ArchLinux:
+		return nftablesBackend
+	case RedHat, CentOS, Fedora, RockyLinux, AlmaLinux, OracleLinux:
+		return firewalldOrNftablesBackend
+	case AmazonLinux, AmazonLinuxECS:
+		if desc.Version == AmazonLinux2023.Version || desc.Version == AmazonLinuxECS2023.Version {
+			return nftablesBackend
+		}
+		return iptablesBackend
+	default:
+		return iptablesBackend
+	}
+}
+
+type linuxFirewall struct {
+	e       config.Env
+	runner  command.Runner
+	backend linuxFirewallBackend
+}
+
+func newLinuxFirewall(e config.Env, desc Descriptor, runner command.Runner) Firewall {
+	return &linuxFirewall{e: e, runner: runner, backend: firewallBackendFromDescriptor(desc)}
+}
+
+func (f *linuxFirewall) AllowPort(port int, protocol FirewallProtocol, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	if err := validateFirewallProtocol(protocol); err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%s-%d", protocol, port)
+	createCmd, deleteCmd := f.backend.allowPort(firewallRuleID("allow", name), port, protocol)
+	return runNamedCommand(f.e, f.runner, "firewall-allow", name, &command.Args{
+		Sudo:   true,
+		Create: f.script(createCmd, f.backend.setup),
+		Delete: f.script(deleteCmd),
+	}, transform, opts...)
+}
+
+func (f *linuxFirewall) BlockEgress(destination string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	id := firewallRuleID("egress", destination)
+
+	createCmd := f.backend.blockEgress(id, destination)
+	if !isIPOrCIDR(destination) {
+		createCmd = fmt.Sprintf(`addresses=$(getent ahostsv4 %s | awk "{print \$1}" | sort -u) && [ -n "$addresses" ] && for address in $addresses; do %s; done`,
+			destination, f.backend.blockEgress(id, "$address"))
+	}
+
+	return runNamedCommand(f.e, f.runner, "firewall-block-egress", destination, &command.Args{
+		Sudo:   true,
+		Create: f.script(createCmd, f.backend.setup, f.backend.egressSetup),
+		Delete: f.script(f.backend.deleteRules(id, false)),
+	}, transform, opts...)
+}
+
+func (f *linuxFirewall) Reset(transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	resetCmd := f.backend.reset
+	if resetCmd == "" {
+		resetCmd = f.backend.deleteRules(firewallRulePrefix, true)
+	}
+
+	return runNamedCommand(f.e, f.runner, "firewall", "reset", &command.Args{
+		Sudo:   true,
+		Create: f.script(resetCmd),
+	}, transform, opts...)
+}
+
+// script wraps the command in a single bash invocation run with sudo, after the non-empty setup commands.
+// Setup is only run to add rules, deleting rules never sets up the firewall.
+func (f *linuxFirewall) script(cmd string, setup ...string) pulumi.StringInput {
+	return pulumi.String("bash -c " + shellescape.Quote(joinCommands(append(append([]string{"set -e"}, setup...), cmd)...)))
+}
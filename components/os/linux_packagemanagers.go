@@ -1,6 +1,9 @@
 package os
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
@@ -13,8 +16,41 @@ var (
 	yumPackageNameMapping = map[string]string{}
 
 	dnfPackageNameMapping = map[string]string{}
+
+	apkPackageNameMapping = map[string]string{}
+
+	pacmanPackageNameMapping = map[string]string{}
 )
 
+var aptSpecifics = packageManagerSpecifics{
+	pinVersion: func(packageRef, version string) string {
+		return fmt.Sprintf("%s=%s", packageRef, version)
+	},
+	downgradeFlag: "--allow-downgrades",
+	addRepository: func(repository PackageRepository) (string, string) {
+		listPath := fmt.Sprintf("/etc/apt/sources.list.d/%s.list", repository.Name)
+		keyringPath := fmt.Sprintf("/usr/share/keyrings/%s-archive-keyring.gpg", repository.Name)
+
+		sourceOptions := "[trusted=yes]"
+		importKey := ""
+		if repository.KeyURL != "" {
+			sourceOptions = fmt.Sprintf("[signed-by=%s]", keyringPath)
+			importKey = fmt.Sprintf("curl --retry 10 -fsSL %s | gpg --dearmor --yes -o %s && ", repository.KeyURL, keyringPath)
+		}
+
+		createCmd := fmt.Sprintf(`bash -c '%secho "deb %s %s %s %s" > %s && apt-get update -y'`,
+			importKey, sourceOptions, repository.URL, repository.Distribution, strings.Join(repository.Components, " "), listPath)
+		deleteCmd := fmt.Sprintf("rm -f %s %s", listPath, keyringPath)
+		return createCmd, deleteCmd
+	},
+	hold: func(packageRef string) string {
+		return "apt-mark hold " + packageRef
+	},
+	unhold: func(packageRef string) string {
+		return "apt-mark unhold " + packageRef
+	},
+}
+
 func newAptManager(runner command.Runner) PackageManager {
 	return NewGenericPackageManager(
 		runner,
@@ -26,13 +62,84 @@ func newAptManager(runner command.Runner) PackageManager {
 			"DEBIAN_FRONTEND": pulumi.String("noninteractive"),
 		},
 		aptPackageNameMapping,
-	)
+	).withSpecifics(aptSpecifics)
 }
 
 func newYumManager(runner command.Runner) PackageManager {
-	return NewGenericPackageManager(runner, "yum", "yum install -y", "", "yum remove -y", nil, yumPackageNameMapping)
+	return NewGenericPackageManager(runner, "yum", "yum install -y", "", "yum remove -y", nil, yumPackageNameMapping).
+		withSpecifics(rpmSpecifics("yum", "yum-plugin-versionlock"))
 }
 
 func newDnfManager(runner command.Runner) PackageManager {
-	return NewGenericPackageManager(runner, "dnf", "dnf install -y", "", "dnf remove -y", nil, dnfPackageNameMapping)
+	return NewGenericPackageManager(runner, "dnf", "dnf install -y", "", "dnf remove -y", nil, dnfPackageNameMapping).
+		withSpecifics(rpmSpecifics("dnf", "python3-dnf-plugin-versionlock"))
+}
+
+// rpmSpecifics returns the specifics shared by yum and dnf, holds rely on the versionlock plugin which is installed when missing
+func rpmSpecifics(binary string, versionlockPackage string) packageManagerSpecifics {
+	return packageManagerSpecifics{
+		pinVersion: func(packageRef, version string) string {
+			return fmt.Sprintf("%s-%s", packageRef, version)
+		},
+		addRepository: func(repository PackageRepository) (string, string) {
+			repoPath := fmt.Sprintf("/etc/yum.repos.d/%s.repo", repository.Name)
+
+			lines := []string{
+				fmt.Sprintf("[%s]", repository.Name),
+				"name=" + repository.Name,
+				"baseurl=" + repository.URL,
+				"enabled=1",
+			}
+			if repository.KeyURL != "" {
+				lines = append(lines, "gpgcheck=1", "gpgkey="+repository.KeyURL)
+			} else {
+				lines = append(lines, "gpgcheck=0")
+			}
+
+			quotedLines := make([]string, 0, len(lines))
+			for _, line := range lines {
+				// Repository URLs may contain yum variables like $basearch that must not be expanded by the shell
+				quotedLines = append(quotedLines, fmt.Sprintf(`"%s"`, strings.ReplaceAll(line, "$", `\$`)))
+			}
+
+			createCmd := fmt.Sprintf(`bash -c 'printf "%%s\n" %s > %s'`, strings.Join(quotedLines, " "), repoPath)
+			deleteCmd := "rm -f " + repoPath
+			return createCmd, deleteCmd
+		},
+		hold: func(packageRef string) string {
+			return fmt.Sprintf(`bash -c '%[1]s versionlock list > /dev/null 2>&1 || %[1]s install -y %[2]s; %[1]s versionlock add %[3]s'`, binary, versionlockPackage, packageRef)
+		},
+		unhold: func(packageRef string) string {
+			return fmt.Sprintf("%s versionlock delete %s", binary, packageRef)
+		},
+	}
+}
+
+// apkSpecifics does not support holds, repositories are expected to be signed as apk refuses unsigned indexes by default
+var apkSpecifics = packageManagerSpecifics{
+	pinVersion: func(packageRef, version string) string {
+		return fmt.Sprintf("%s=%s", packageRef, version)
+	},
+	addRepository: func(repository PackageRepository) (string, string) {
+		keyPath := fmt.Sprintf("/etc/apk/keys/%s.rsa.pub", repository.Name)
+
+		importKey := ""
+		if repository.KeyURL != "" {
+			importKey = fmt.Sprintf("wget -qO %s %s && ", keyPath, repository.KeyURL)
+		}
+
+		createCmd := fmt.Sprintf(`sh -c '%secho "%s" >> /etc/apk/repositories && apk update'`, importKey, repository.URL)
+		deleteCmd := fmt.Sprintf(`sh -c 'sed -i "\\|^%s$|d" /etc/apk/repositories; rm -f %s'`, repository.URL, keyPath)
+		return createCmd, deleteCmd
+	},
+}
+
+func newApkManager(runner command.Runner) PackageManager {
+	return NewGenericPackageManager(runner, "apk", "apk add", "apk update", "apk del", nil, apkPackageNameMapping).
+		withSpecifics(apkSpecifics)
+}
+
+// newPacmanManager returns a pacman package manager, as Arch Linux is a rolling release neither version pinning nor third-party repositories are supported
+func newPacmanManager(runner command.Runner) PackageManager {
+	return NewGenericPackageManager(runner, "pacman", "pacman -S --noconfirm", "pacman -Sy", "pacman -R --noconfirm", nil, pacmanPackageNameMapping)
 }
@@ -2,12 +2,16 @@ package os
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/DataDog/test-infra-definitions/common/config"
 	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
+// serviceLogsLinesOnFailure is the number of log lines printed when a service does not become active
+const serviceLogsLinesOnFailure = 100
+
 type systemdServiceManager struct {
 	e      config.Env
 	runner command.Runner
@@ -18,18 +22,49 @@ func newSystemdServiceManager(e config.Env, runner command.Runner) ServiceManage
 }
 
 func (s *systemdServiceManager) EnsureRestarted(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
-	cmdName := s.e.CommonNamer().ResourceName("running", serviceName)
-	var cmdArgs command.RunnerCommandArgs = &command.Args{
+	return runNamedCommand(s.e, s.runner, "running", serviceName, &command.Args{
 		Sudo:   true,
 		Create: pulumi.String("systemctl restart " + serviceName),
-	}
+	}, transform, opts...)
+}
 
-	// If a transform is provided, use it to modify the command name and args
-	if transform != nil {
-		cmdName, cmdArgs = transform(cmdName, cmdArgs)
-	}
+func (s *systemdServiceManager) EnsureStopped(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "stopped", serviceName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String("systemctl stop " + serviceName),
+	}, transform, opts...)
+}
+
+func (s *systemdServiceManager) EnsureEnabled(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "enabled", serviceName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String("systemctl enable " + serviceName),
+		Delete: pulumi.String("systemctl disable " + serviceName),
+	}, transform, opts...)
+}
 
-	return s.runner.Command(cmdName, cmdArgs, opts...)
+func (s *systemdServiceManager) EnsureDisabled(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "disabled", serviceName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String("systemctl disable " + serviceName),
+		Delete: pulumi.String("systemctl enable " + serviceName),
+	}, transform, opts...)
+}
+
+func (s *systemdServiceManager) WaitForActive(serviceName string, timeout time.Duration, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "active", serviceName, &command.Args{
+		Sudo: true,
+		Create: pulumi.String(fmt.Sprintf(
+			`bash -c 'for i in $(seq 1 %[1]d); do systemctl is-active --quiet %[2]s && exit 0; sleep 1; done; systemctl status %[2]s --no-pager; journalctl -u %[2]s -n %[3]d --no-pager; exit 1'`,
+			int(timeout.Seconds()), serviceName, serviceLogsLinesOnFailure)),
+	}, transform, opts...)
+}
+
+func (s *systemdServiceManager) CollectLogs(serviceName string, lines int, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "logs", serviceName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String(fmt.Sprintf("journalctl -u %s -n %d --no-pager", serviceName, lines)),
+	}, transform, opts...)
 }
 
 type sysvinitServiceManager struct {
@@ -42,18 +77,111 @@ func newSysvinitServiceManager(e config.Env, runner command.Runner) ServiceManag
 }
 
 func (s *sysvinitServiceManager) EnsureRestarted(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
-	cmdName := s.e.CommonNamer().ResourceName("running", serviceName)
 	// To the difference of systemctl the restart doesn't work if the service isn't already running
 	// so instead we run a stop command that we allow to fail and then a start command
-	var cmdArgs command.RunnerCommandArgs = &command.Args{
+	return runNamedCommand(s.e, s.runner, "running", serviceName, &command.Args{
 		Sudo:   false,
 		Create: pulumi.String(fmt.Sprintf("sudo stop %[1]s; sudo start %[1]s", serviceName)),
-	}
+	}, transform, opts...)
+}
+
+func (s *sysvinitServiceManager) EnsureStopped(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	// `stop` fails if the service is not running, which is the state we want
+	return runNamedCommand(s.e, s.runner, "stopped", serviceName, &command.Args{
+		Sudo:   false,
+		Create: pulumi.String(fmt.Sprintf("sudo stop %s || true", serviceName)),
+	}, transform, opts...)
+}
+
+func (s *sysvinitServiceManager) EnsureEnabled(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "enabled", serviceName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String(fmt.Sprintf("chkconfig %s on", serviceName)),
+		Delete: pulumi.String(fmt.Sprintf("chkconfig %s off", serviceName)),
+	}, transform, opts...)
+}
+
+func (s *sysvinitServiceManager) EnsureDisabled(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "disabled", serviceName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String(fmt.Sprintf("chkconfig %s off", serviceName)),
+		Delete: pulumi.String(fmt.Sprintf("chkconfig %s on", serviceName)),
+	}, transform, opts...)
+}
+
+func (s *sysvinitServiceManager) WaitForActive(serviceName string, timeout time.Duration, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "active", serviceName, &command.Args{
+		Sudo: true,
+		Create: pulumi.String(fmt.Sprintf(
+			`bash -c 'for i in $(seq 1 %[1]d); do status %[2]s | grep -q running && exit 0; sleep 1; done; status %[2]s; %[3]s; exit 1'`,
+			int(timeout.Seconds()), serviceName, sysvinitLogsCommand(serviceName, serviceLogsLinesOnFailure))),
+	}, transform, opts...)
+}
 
-	// If a transform is provided, use it to modify the command name and args
-	if transform != nil {
-		cmdName, cmdArgs = transform(cmdName, cmdArgs)
-	}
+func (s *sysvinitServiceManager) CollectLogs(serviceName string, lines int, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "logs", serviceName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String(fmt.Sprintf("bash -c '%s'", sysvinitLogsCommand(serviceName, lines))),
+	}, transform, opts...)
+}
+
+// sysvinitLogsCommand reads the upstart log of the service, falling back to syslog when the service does not have its own log file
+func sysvinitLogsCommand(serviceName string, lines int) string {
+	return fmt.Sprintf("tail -n %[2]d /var/log/upstart/%[1]s.log 2>/dev/null || tail -n %[2]d /var/log/messages", serviceName, lines)
+}
+
+type openRCServiceManager struct {
+	e      config.Env
+	runner command.Runner
+}
+
+func newOpenRCServiceManager(e config.Env, runner command.Runner) ServiceManager {
+	return &openRCServiceManager{e: e, runner: runner}
+}
+
+func (s *openRCServiceManager) EnsureRestarted(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "running", serviceName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String(fmt.Sprintf("rc-service %s restart", serviceName)),
+	}, transform, opts...)
+}
 
-	return s.runner.Command(cmdName, cmdArgs, opts...)
+func (s *openRCServiceManager) EnsureStopped(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "stopped", serviceName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String(fmt.Sprintf("rc-service %s stop", serviceName)),
+	}, transform, opts...)
+}
+
+func (s *openRCServiceManager) EnsureEnabled(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "enabled", serviceName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String(fmt.Sprintf("rc-update add %s default", serviceName)),
+		Delete: pulumi.String(fmt.Sprintf("rc-update del %s default", serviceName)),
+	}, transform, opts...)
+}
+
+func (s *openRCServiceManager) EnsureDisabled(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "disabled", serviceName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String(fmt.Sprintf("rc-update del %s default", serviceName)),
+		Delete: pulumi.String(fmt.Sprintf("rc-update add %s default", serviceName)),
+	}, transform, opts...)
+}
+
+func (s *openRCServiceManager) WaitForActive(serviceName string, timeout time.Duration, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "active", serviceName, &command.Args{
+		Sudo: true,
+		Create: pulumi.String(fmt.Sprintf(
+			`sh -c 'for i in $(seq 1 %[1]d); do rc-service %[2]s status > /dev/null 2>&1 && exit 0; sleep 1; done; rc-service %[2]s status; tail -n %[3]d /var/log/messages; exit 1'`,
+			int(timeout.Seconds()), serviceName, serviceLogsLinesOnFailure)),
+	}, transform, opts...)
+}
+
+func (s *openRCServiceManager) CollectLogs(serviceName string, lines int, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	// OpenRC services log to syslog unless they define their own log file
+	return runNamedCommand(s.e, s.runner, "logs", serviceName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String(fmt.Sprintf(`sh -c 'grep "%s" /var/log/messages | tail -n %d'`, serviceName, lines)),
+	}, transform, opts...)
 }
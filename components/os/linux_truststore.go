@@ -0,0 +1,48 @@
+package os
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/components/command"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+type linuxTrustStore struct {
+	e         config.Env
+	runner    command.Runner
+	anchorDir string
+	updateCmd string
+}
+
+func newLinuxTrustStore(e config.Env, desc Descriptor, runner command.Runner) TrustStore {
+	store := &linuxTrustStore{e: e, runner: runner}
+
+	switch desc.Flavor {
+	case Suse, OpenSuseLeap:
+		store.anchorDir, store.updateCmd = "/etc/pki/trust/anchors", "update-ca-certificates"
+	case AmazonLinux, AmazonLinuxECS, CentOS, Fedora, RedHat, RockyLinux, AlmaLinux, OracleLinux:
+		store.anchorDir, store.updateCmd = "/etc/pki/ca-trust/source/anchors", "update-ca-trust extract"
+	case ArchLinux:
+		store.anchorDir, store.updateCmd = "/etc/ca-certificates/trust-source/anchors", "update-ca-trust extract"
+	default:
+		// Debian, Ubuntu and Alpine
+		store.anchorDir, store.updateCmd = "/usr/local/share/ca-certificates", "update-ca-certificates"
+	}
+
+	return store
+}
+
+func (s *linuxTrustStore) EnsureCA(name string, pem pulumi.StringInput, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	// update-ca-certificates only picks up files with the .crt extension
+	certPath := path.Join(s.anchorDir, name+".crt")
+
+	// Commands are run with sudo individually as sudo does not forward the environment holding the certificate
+	return runNamedCommand(s.e, s.runner, "ca", name, &command.Args{
+		Create:      pulumi.String(fmt.Sprintf(`sudo mkdir -p %s && printf '%%s\n' "$%s" | sudo tee %s > /dev/null && sudo %s`, s.anchorDir, caCertificateEnvVar, certPath, s.updateCmd)),
+		Delete:      pulumi.String(fmt.Sprintf("sudo rm -f %s && sudo %s", certPath, s.updateCmd)),
+		Environment: pulumi.StringMap{caCertificateEnvVar: pem},
+	}, transform, opts...)
+}
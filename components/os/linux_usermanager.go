@@ -0,0 +1,72 @@
+package os
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/components/command"
+
+	"github.com/alessio/shellescape"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+type linuxUserManager struct {
+	e      config.Env
+	runner command.Runner
+}
+
+func newLinuxUserManager(e config.Env, runner command.Runner) UserManager {
+	return &linuxUserManager{e: e, runner: runner}
+}
+
+func (m *linuxUserManager) EnsureUser(user User, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	addUserArgs := []string{"--create-home"}
+	if user.Home != "" {
+		addUserArgs = append(addUserArgs, "--home-dir", user.Home)
+	}
+	if user.Shell != "" {
+		addUserArgs = append(addUserArgs, "--shell", user.Shell)
+	}
+	if len(user.Groups) > 0 {
+		addUserArgs = append(addUserArgs, "--groups", strings.Join(user.Groups, ","))
+	}
+
+	// Commands are run with sudo individually, chpasswd reads the password from stdin
+	commands := []string{fmt.Sprintf("(id -u %[1]s > /dev/null 2>&1 || sudo useradd %[2]s %[1]s)", user.Name, strings.Join(addUserArgs, " "))}
+	if len(user.Groups) > 0 {
+		commands = append(commands, fmt.Sprintf("sudo usermod --append --groups %s %s", strings.Join(user.Groups, ","), user.Name))
+	}
+	if user.Password != nil {
+		commands = append(commands, "sudo chpasswd")
+	}
+	if len(user.AuthorizedKeys) > 0 {
+		commands = append(commands, unixAuthorizedKeysCommand(user, fmt.Sprintf("$(getent passwd %s | cut -d: -f6)", user.Name)))
+	}
+
+	return runNamedCommand(m.e, m.runner, "user", user.Name, &command.Args{
+		Create: pulumi.String(strings.Join(commands, " && ")),
+		Delete: pulumi.String(fmt.Sprintf("sudo userdel --remove %s", user.Name)),
+		Stdin:  user.passwordStdin("%s:%s\n"),
+	}, transform, opts...)
+}
+
+func (m *linuxUserManager) EnsureGroup(groupName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(m.e, m.runner, "group", groupName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String(fmt.Sprintf("groupadd -f %s", groupName)),
+		Delete: pulumi.String(fmt.Sprintf("groupdel %s", groupName)),
+	}, transform, opts...)
+}
+
+// unixAuthorizedKeysCommand returns a command writing the authorized keys of the user, homeDir may be a shell expression
+func unixAuthorizedKeysCommand(user User, homeDir string) string {
+	quotedKeys := make([]string, 0, len(user.AuthorizedKeys))
+	for _, key := range user.AuthorizedKeys {
+		quotedKeys = append(quotedKeys, shellescape.Quote(key))
+	}
+
+	return fmt.Sprintf(
+		`USER_HOME=%[2]s && sudo mkdir -p "$USER_HOME/.ssh" && printf '%%s\n' %[3]s | sudo tee "$USER_HOME/.ssh/authorized_keys" > /dev/null && sudo chown -R %[1]s "$USER_HOME/.ssh" && sudo chmod 700 "$USER_HOME/.ssh" && sudo chmod 600 "$USER_HOME/.ssh/authorized_keys"`,
+		user.Name, homeDir, strings.Join(quotedKeys, " "))
+}
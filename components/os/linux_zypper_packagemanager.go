@@ -2,6 +2,7 @@ package os
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/DataDog/test-infra-definitions/common"
 	"github.com/DataDog/test-infra-definitions/common/namer"
@@ -12,16 +13,15 @@ import (
 
 func newZypperManager(runner command.Runner) *ZypperPackageManager {
 	return &ZypperPackageManager{
-		namer:      namer.NewNamer(runner.Environment().Ctx(), "zypper"),
-		runner:     runner,
-		pulumiOpts: []pulumi.ResourceOption{},
+		packageManagerCommands: packageManagerCommands{
+			namer:  namer.NewNamer(runner.Environment().Ctx(), "zypper"),
+			runner: runner,
+		},
 	}
 }
 
 type ZypperPackageManager struct {
-	namer      namer.Namer
-	runner     command.Runner
-	pulumiOpts []pulumi.ResourceOption
+	packageManagerCommands
 }
 
 func (m *ZypperPackageManager) Ensure(packageRef string, transform command.Transformer, checkBinary string, opts ...PackageManagerOption) (command.Command, error) {
@@ -30,13 +30,20 @@ func (m *ZypperPackageManager) Ensure(packageRef string, transform command.Trans
 		return nil, err
 	}
 
-	pulumiOpts := append(params.PulumiResourceOptions, m.pulumiOpts...)
+	pulumiOpts := append(params.PulumiResourceOptions, m.opts...)
 
 	zypperInstallCmd := "zypper -n install"
 	if params.AllowUnsignedPackages {
 		zypperInstallCmd = "zypper -n --no-gpg-checks install"
 	}
 
+	if params.Version != "" {
+		packageRef = fmt.Sprintf("%s=%s", packageRef, params.Version)
+		checkBinary = ""
+		// Allow to install an older version than the installed one
+		zypperInstallCmd += " --oldpackage"
+	}
+
 	var cmdStr string
 	if checkBinary != "" {
 		cmdStr = fmt.Sprintf("bash -c 'command -v %s || %s %s'", checkBinary, zypperInstallCmd, packageRef)
@@ -61,7 +68,7 @@ func (m *ZypperPackageManager) Ensure(packageRef string, transform command.Trans
 	}
 
 	// Make sure the package manager isn't running in parallel
-	m.pulumiOpts = append(m.pulumiOpts, utils.PulumiDependsOn(cmd))
+	m.opts = append(m.opts, utils.PulumiDependsOn(cmd))
 	return cmd, nil
 }
 
@@ -71,7 +78,7 @@ func (m *ZypperPackageManager) EnsureUninstalled(packageRef string, transform co
 		return nil, err
 	}
 
-	pulumiOpts := append(params.PulumiResourceOptions, m.pulumiOpts...)
+	pulumiOpts := append(params.PulumiResourceOptions, m.opts...)
 	// Ensure the package is uninstalled
 	zypperUninstallCmd := "zypper -n remove"
 
@@ -99,6 +106,34 @@ func (m *ZypperPackageManager) EnsureUninstalled(packageRef string, transform co
 	}
 
 	// Make sure the package manager isn't running in parallel
-	m.pulumiOpts = append(m.pulumiOpts, utils.PulumiDependsOn(cmd))
+	m.opts = append(m.opts, utils.PulumiDependsOn(cmd))
 	return cmd, nil
 }
+
+func (m *ZypperPackageManager) AddRepository(repository PackageRepository, transform command.Transformer, opts ...PackageManagerOption) (command.Command, error) {
+	gpgCheck := "--no-gpgcheck"
+	importKey := ""
+	if repository.KeyURL != "" {
+		gpgCheck = "--gpgcheck"
+		importKey = fmt.Sprintf("rpm --import %s && ", repository.KeyURL)
+	}
+
+	// Repository URLs may contain zypper variables like $basearch that must not be expanded by the shell
+	url := strings.ReplaceAll(repository.URL, "$", `\$`)
+	createCmd := fmt.Sprintf(`bash -c '%[1]szypper -n addrepo --refresh %[2]s "%[3]s" %[4]s && zypper -n --gpg-auto-import-keys refresh %[4]s'`, importKey, gpgCheck, url, repository.Name)
+	deleteCmd := "zypper -n removerepo " + repository.Name
+	return m.runCommand("add-repository-"+repository.Name, createCmd, deleteCmd, transform, opts)
+}
+
+func (m *ZypperPackageManager) Hold(packageRef string, transform command.Transformer, opts ...PackageManagerOption) (command.Command, error) {
+	return m.runCommand("hold-"+packageRef, "zypper -n addlock "+packageRef, "zypper -n removelock "+packageRef, transform, opts)
+}
+
+func (m *ZypperPackageManager) Unhold(packageRef string, transform command.Transformer, opts ...PackageManagerOption) (command.Command, error) {
+	return m.runCommand("unhold-"+packageRef, "zypper -n removelock "+packageRef, "", transform, opts)
+}
+
+// runCommand runs a zypper command after all the previous ones, deleteCmd is not run if empty
+func (m *ZypperPackageManager) runCommand(name string, createCmd string, deleteCmd string, transform command.Transformer, opts []PackageManagerOption) (command.Command, error) {
+	return m.packageManagerCommands.run(name, createCmd, deleteCmd, nil, true, transform, opts)
+}
@@ -12,6 +12,10 @@ func newMacOS(e config.Env, desc Descriptor, runner command.Runner) OS {
 		fileManager:    command.NewFileManager(runner),
 		packageManager: newBrewManager(runner),
 		serviceManager: newMacOSServiceManager(e, runner),
+		userManager:    newMacOSUserManager(e, runner),
+		firewall:       unsupportedFirewall{osName: "macOS"},
+		diskManager:    unsupportedDiskManager{osName: "macOS"},
+		trustStore:     newMacOSTrustStore(e, runner),
 	}
 
 	return os
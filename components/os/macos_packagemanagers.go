@@ -1,6 +1,8 @@
 package os
 
 import (
+	"fmt"
+
 	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
@@ -9,7 +11,23 @@ var (
 	brewPackageNameMapping = map[string]string{}
 )
 
+// brewSpecifics maps repositories to taps, signing keys are not supported by brew and are ignored.
+// Versions cannot be pinned as brew only installs the latest version of a formula, `<formula>@<version>` formulae are
+// distinct formulae which must be installed by name.
+var brewSpecifics = packageManagerSpecifics{
+	addRepository: func(repository PackageRepository) (string, string) {
+		return fmt.Sprintf("brew tap %s %s", repository.Name, repository.URL), "brew untap " + repository.Name
+	},
+	hold: func(packageRef string) string {
+		return "brew pin " + packageRef
+	},
+	unhold: func(packageRef string) string {
+		return "brew unpin " + packageRef
+	},
+}
+
+// newBrewManager returns a brew package manager, its commands are not run with sudo as brew refuses to run as root
 func newBrewManager(runner command.Runner) PackageManager {
 	return NewGenericPackageManager(runner, "brew", "brew install -y", "brew update -y", "brew uninstall -y",
-		pulumi.StringMap{"NONINTERACTIVE": pulumi.String("1")}, brewPackageNameMapping)
+		pulumi.StringMap{"NONINTERACTIVE": pulumi.String("1")}, brewPackageNameMapping).withSpecifics(brewSpecifics).withoutSudo()
 }
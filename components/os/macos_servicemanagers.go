@@ -2,12 +2,15 @@ package os
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/DataDog/test-infra-definitions/common/config"
 	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
+// macOSServiceManager expects launchd service targets as service names, for instance `system/com.datadoghq.agent`
 type macOSServiceManager struct {
 	e      config.Env
 	runner command.Runner
@@ -18,16 +21,54 @@ func newMacOSServiceManager(e config.Env, runner command.Runner) ServiceManager
 }
 
 func (s *macOSServiceManager) EnsureRestarted(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
-	cmdName := s.e.CommonNamer().ResourceName("running", serviceName)
-	var cmdArgs command.RunnerCommandArgs = &command.Args{
+	return runNamedCommand(s.e, s.runner, "running", serviceName, &command.Args{
 		Sudo:   true,
-		Create: pulumi.String(fmt.Sprintf("launchctl kickstart -k %s'", serviceName)),
-	}
+		Create: pulumi.String(fmt.Sprintf("launchctl kickstart -k %s", serviceName)),
+	}, transform, opts...)
+}
+
+func (s *macOSServiceManager) EnsureStopped(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	// `kill` fails if the service is not running, which is the state we want
+	return runNamedCommand(s.e, s.runner, "stopped", serviceName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String(fmt.Sprintf("bash -c 'launchctl kill SIGTERM %s || true'", serviceName)),
+	}, transform, opts...)
+}
+
+func (s *macOSServiceManager) EnsureEnabled(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "enabled", serviceName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String(fmt.Sprintf("launchctl enable %s", serviceName)),
+		Delete: pulumi.String(fmt.Sprintf("launchctl disable %s", serviceName)),
+	}, transform, opts...)
+}
 
-	// If a transform is provided, use it to modify the command name and args
-	if transform != nil {
-		cmdName, cmdArgs = transform(cmdName, cmdArgs)
-	}
+func (s *macOSServiceManager) EnsureDisabled(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "disabled", serviceName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String(fmt.Sprintf("launchctl disable %s", serviceName)),
+		Delete: pulumi.String(fmt.Sprintf("launchctl enable %s", serviceName)),
+	}, transform, opts...)
+}
+
+func (s *macOSServiceManager) WaitForActive(serviceName string, timeout time.Duration, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "active", serviceName, &command.Args{
+		Sudo: true,
+		Create: pulumi.String(fmt.Sprintf(
+			`bash -c 'for i in $(seq 1 %[1]d); do launchctl print %[2]s | grep -q "state = running" && exit 0; sleep 1; done; launchctl print %[2]s; %[3]s; exit 1'`,
+			int(timeout.Seconds()), serviceName, macOSLogsCommand(serviceName, serviceLogsLinesOnFailure))),
+	}, transform, opts...)
+}
+
+func (s *macOSServiceManager) CollectLogs(serviceName string, lines int, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "logs", serviceName, &command.Args{
+		Sudo:   true,
+		Create: pulumi.String(fmt.Sprintf("bash -c '%s'", macOSLogsCommand(serviceName, lines))),
+	}, transform, opts...)
+}
 
-	return s.runner.Command(cmdName, cmdArgs, opts...)
+// macOSLogsCommand reads the unified log entries of the last hour matching the service label
+func macOSLogsCommand(serviceName string, lines int) string {
+	label := serviceName[strings.LastIndex(serviceName, "/")+1:]
+	return fmt.Sprintf(`log show --style syslog --last 1h --predicate "subsystem CONTAINS \"%[1]s\" OR process CONTAINS \"%[1]s\"" | tail -n %[2]d`, label, lines)
 }
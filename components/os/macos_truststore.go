@@ -0,0 +1,38 @@
+package os
+
+import (
+	"fmt"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/components/command"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+const (
+	macOSSystemKeychain = "/Library/Keychains/System.keychain"
+	// macOSCADir keeps a copy of the installed certificates to find their hash on destroy
+	macOSCADir = "/usr/local/share/test-infra-ca"
+)
+
+type macOSTrustStore struct {
+	e      config.Env
+	runner command.Runner
+}
+
+func newMacOSTrustStore(e config.Env, runner command.Runner) TrustStore {
+	return &macOSTrustStore{e: e, runner: runner}
+}
+
+func (s *macOSTrustStore) EnsureCA(name string, pem pulumi.StringInput, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	certPath := fmt.Sprintf("%s/%s.pem", macOSCADir, name)
+
+	// Commands are run with sudo individually as sudo does not forward the environment holding the certificate
+	return runNamedCommand(s.e, s.runner, "ca", name, &command.Args{
+		Create: pulumi.String(fmt.Sprintf(`sudo mkdir -p %[1]s && printf '%%s\n' "$%[2]s" | sudo tee %[3]s > /dev/null && sudo security add-trusted-cert -d -r trustRoot -k %[4]s %[3]s`,
+			macOSCADir, caCertificateEnvVar, certPath, macOSSystemKeychain)),
+		Delete: pulumi.String(fmt.Sprintf(`sudo security delete-certificate -Z "$(openssl x509 -noout -fingerprint -sha1 -in %[1]s | cut -d= -f2 | tr -d :)" -t %[2]s; sudo rm -f %[1]s`,
+			certPath, macOSSystemKeychain)),
+		Environment: pulumi.StringMap{caCertificateEnvVar: pem},
+	}, transform, opts...)
+}
@@ -0,0 +1,62 @@
+package os
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/components/command"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+type macOSUserManager struct {
+	e      config.Env
+	runner command.Runner
+}
+
+func newMacOSUserManager(e config.Env, runner command.Runner) UserManager {
+	return &macOSUserManager{e: e, runner: runner}
+}
+
+func (m *macOSUserManager) EnsureUser(user User, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	addUserArgs := []string{}
+	if user.Home != "" {
+		addUserArgs = append(addUserArgs, "-home", user.Home)
+	}
+	if user.Shell != "" {
+		addUserArgs = append(addUserArgs, "-shell", user.Shell)
+	}
+	if user.Password != nil {
+		addUserArgs = append(addUserArgs, "-password", `"$USER_PASSWORD"`)
+	}
+
+	// Commands are run with sudo individually, the password is read from stdin into a shell variable
+	commands := []string{}
+	if user.Password != nil {
+		commands = append(commands, "IFS= read -r USER_PASSWORD")
+	}
+	commands = append(commands,
+		fmt.Sprintf("(id -u %[1]s > /dev/null 2>&1 || sudo sysadminctl -addUser %[1]s %[2]s)", user.Name, strings.Join(addUserArgs, " ")),
+		fmt.Sprintf("sudo createhomedir -c -u %s > /dev/null", user.Name),
+	)
+	for _, group := range user.Groups {
+		commands = append(commands, fmt.Sprintf("sudo dseditgroup -o edit -a %s -t user %s", user.Name, group))
+	}
+	if len(user.AuthorizedKeys) > 0 {
+		commands = append(commands, unixAuthorizedKeysCommand(user, fmt.Sprintf("$(dscl . -read /Users/%s NFSHomeDirectory | awk '{print $2}')", user.Name)))
+	}
+
+	return runNamedCommand(m.e, m.runner, "user", user.Name, &command.Args{
+		Create: pulumi.String(strings.Join(commands, " && ")),
+		Delete: pulumi.String(fmt.Sprintf("sudo sysadminctl -deleteUser %s", user.Name)),
+		Stdin:  user.passwordStdin("%[2]s\n"),
+	}, transform, opts...)
+}
+
+func (m *macOSUserManager) EnsureGroup(groupName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(m.e, m.runner, "group", groupName, &command.Args{
+		Create: pulumi.String(fmt.Sprintf("dseditgroup -o read %[1]s > /dev/null 2>&1 || sudo dseditgroup -o create %[1]s", groupName)),
+		Delete: pulumi.String(fmt.Sprintf("sudo dseditgroup -o delete %s", groupName)),
+	}, transform, opts...)
+}
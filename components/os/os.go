@@ -2,6 +2,7 @@ package os
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/DataDog/test-infra-definitions/common/config"
 	"github.com/DataDog/test-infra-definitions/components/command"
@@ -16,6 +17,26 @@ type PackageManager interface {
 	// if it succeeds we consider the package is already installed
 	Ensure(packageRef string, transform command.Transformer, checkBinary string, opts ...PackageManagerOption) (command.Command, error)
 	EnsureUninstalled(packageRef string, transform command.Transformer, checkBinary string, opts ...PackageManagerOption) (command.Command, error)
+	// AddRepository registers a third-party repository and its signing key, the repository is removed on destroy
+	AddRepository(repository PackageRepository, transform command.Transformer, opts ...PackageManagerOption) (command.Command, error)
+	// Hold prevents a package from being upgraded or removed, the hold is released on destroy
+	Hold(packageRef string, transform command.Transformer, opts ...PackageManagerOption) (command.Command, error)
+	// Unhold releases a hold previously set on a package
+	Unhold(packageRef string, transform command.Transformer, opts ...PackageManagerOption) (command.Command, error)
+}
+
+// PackageRepository describes a third-party package repository
+type PackageRepository struct {
+	// Name identifies the repository, it is used to name the repository and keyring files
+	Name string
+	// URL is the base URL of the repository
+	URL string
+	// KeyURL is the URL of the public key used to sign the repository. If empty the repository is trusted without signature check.
+	KeyURL string
+	// Distribution is only used by apt, for instance `stable`
+	Distribution string
+	// Components is only used by apt, for instance `[]string{"7"}`
+	Components []string
 }
 
 func AllowUnsignedPackages(allow bool) PackageManagerOption {
@@ -32,16 +53,118 @@ func WithPulumiResourceOptions(opts ...pulumi.ResourceOption) PackageManagerOpti
 	}
 }
 
+// WithVersion pins the exact version of the package to install.
+// The check binary passed to Ensure is ignored when a version is set, to make sure the pinned version is installed.
+func WithVersion(version string) PackageManagerOption {
+	return func(pm *PackageManagerParams) error {
+		pm.Version = version
+		return nil
+	}
+}
+
 type PackageManagerOption = func(*PackageManagerParams) error
 
 type PackageManagerParams struct {
 	AllowUnsignedPackages bool
+	Version               string
 	PulumiResourceOptions []pulumi.ResourceOption
 }
 
+// ServiceManager manages the services of the OS.
+// The names of the commands are derived from the operation and the service name, so running the same operation
+// more than once on a service requires a transform renaming the command, for instance [command.WithNameSuffix].
 type ServiceManager interface {
 	// EnsureStarted starts or restarts (may be stop+start depending on implementation) the service if already running
 	EnsureRestarted(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+	// EnsureStopped stops the service, it does not fail if the service is already stopped
+	EnsureStopped(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+	// EnsureEnabled configures the service to be started at boot
+	EnsureEnabled(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+	// EnsureDisabled configures the service to not be started at boot
+	EnsureDisabled(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+	// WaitForActive waits for the service to be running, it fails after timeout and prints the service logs
+	WaitForActive(serviceName string, timeout time.Duration, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+	// CollectLogs returns a command whose stdout contains the last `lines` log lines of the service
+	CollectLogs(serviceName string, lines int, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+}
+
+// UserManager manages local users and groups, they are deleted on destroy
+type UserManager interface {
+	// EnsureUser creates the user if it does not exist and configures its groups, password and SSH authorized keys
+	EnsureUser(user User, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+	// EnsureGroup creates the group if it does not exist
+	EnsureGroup(groupName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+}
+
+// User describes a local user account
+type User struct {
+	Name string
+	// Groups are supplementary groups of the user, they must already exist, see UserManager.EnsureGroup
+	Groups []string
+	// Shell is the login shell, the system default is used if empty. Not supported on Windows.
+	Shell string
+	// Home is the home directory, the system default is used if empty. Not supported on Windows.
+	Home string
+	// AuthorizedKeys are the SSH public keys allowed to log in as the user
+	AuthorizedKeys []string
+	// Password is fed to the command on stdin to stay out of the command line, it should be a secret to stay encrypted in the state.
+	// The user has no password if nil.
+	Password pulumi.StringInput
+}
+
+// passwordStdin returns the stdin of UserManager commands reading the password, format is formatted with the user name and the password
+func (u User) passwordStdin(format string) pulumi.StringPtrInput {
+	if u.Password == nil {
+		return nil
+	}
+	return pulumi.Sprintf(format, u.Name, u.Password).ToStringPtrOutput()
+}
+
+// Firewall manages host firewall rules, rules are removed on destroy
+type Firewall interface {
+	// AllowPort accepts inbound traffic on the given port.
+	// With nftables the rule is added to a dedicated table, it does not override drop rules of other tables.
+	AllowPort(port int, protocol FirewallProtocol, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+	// BlockEgress drops outbound traffic to the given IP address, CIDR or hostname.
+	// Hostnames are resolved when the rule is created.
+	BlockEgress(destination string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+	// Reset removes all the rules created through this interface
+	Reset(transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+}
+
+type FirewallProtocol string
+
+const (
+	TCPProtocol FirewallProtocol = "tcp"
+	UDPProtocol FirewallProtocol = "udp"
+)
+
+// DiskManager prepares data disks attached to the host, the disk is unmounted on destroy but its data is kept
+type DiskManager interface {
+	// EnsureMounted partitions and formats the disk if no file system has its label yet, then mounts it persistently
+	EnsureMounted(disk Disk, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+}
+
+// TrustStore manages the certificate authorities trusted by the system, they are removed on destroy
+type TrustStore interface {
+	// EnsureCA adds the PEM encoded CA certificate to the system trust store, name must be usable as a file name
+	EnsureCA(name string, pem pulumi.StringInput, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error)
+}
+
+// caCertificateEnvVar is the environment variable holding the certificate in TrustStore commands
+const caCertificateEnvVar = "CA_CERTIFICATE_PEM"
+
+// runNamedCommand is a helper shared by ServiceManager and UserManager implementations to run a command named after the action and the service or user
+func runNamedCommand(e config.Env, runner command.Runner, action string, name string, args *command.Args, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	cmdName := e.CommonNamer().ResourceName(action, name)
+	var cmdArgs command.RunnerCommandArgs = args
+
+	// If a transform is provided, use it to modify the command name and args
+	if transform != nil {
+		cmdName, cmdArgs = transform(cmdName, cmdArgs)
+	}
+
+	return runner.Command(cmdName, cmdArgs, opts...)
 }
 
 // FileManager needs to be added here as well instead of the command package
@@ -54,6 +177,10 @@ type OS interface {
 	FileManager() *command.FileManager
 	PackageManager() PackageManager
 	ServiceManger() ServiceManager
+	UserManager() UserManager
+	Firewall() Firewall
+	DiskManager() DiskManager
+	TrustStore() TrustStore
 }
 
 var _ OS = &os{}
@@ -65,6 +192,10 @@ type os struct {
 	fileManager    *command.FileManager
 	packageManager PackageManager
 	serviceManager ServiceManager
+	userManager    UserManager
+	firewall       Firewall
+	diskManager    DiskManager
+	trustStore     TrustStore
 }
 
 func (o os) Descriptor() Descriptor {
@@ -87,6 +218,22 @@ func (o os) ServiceManger() ServiceManager {
 	return o.serviceManager
 }
 
+func (o os) UserManager() UserManager {
+	return o.userManager
+}
+
+func (o os) Firewall() Firewall {
+	return o.firewall
+}
+
+func (o os) DiskManager() DiskManager {
+	return o.diskManager
+}
+
+func (o os) TrustStore() TrustStore {
+	return o.trustStore
+}
+
 func NewOS(
 	e config.Env,
 	descriptor Descriptor,
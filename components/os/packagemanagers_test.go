@@ -0,0 +1,143 @@
+package os
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+)
+
+func (fakeEnv) Ctx() *pulumi.Context {
+	return nil
+}
+
+func (r *fakeRunner) Environment() config.Env {
+	return fakeEnv{}
+}
+
+func TestPackageManagerVersionPinning(t *testing.T) {
+	tests := []struct {
+		name       string
+		newManager func(*fakeRunner) PackageManager
+		install    string
+	}{
+		{"apt", func(r *fakeRunner) PackageManager { return newAptManager(r) }, "apt-get install -y --allow-downgrades datadog-agent=1:7.50.0-1"},
+		{"yum", func(r *fakeRunner) PackageManager { return newYumManager(r) }, "yum install -y datadog-agent-1:7.50.0-1"},
+		{"zypper", func(r *fakeRunner) PackageManager { return newZypperManager(r) }, "zypper -n install --oldpackage datadog-agent=1:7.50.0-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeRunner{}
+			_, err := tt.newManager(runner).Ensure("datadog-agent", nil, "datadog-agent", WithVersion("1:7.50.0-1"))
+			require.NoError(t, err)
+
+			install := runner.commands[len(runner.commands)-1]
+			assert.Equal(t, tt.install, createCommand(t, install))
+			assert.True(t, install.Sudo)
+		})
+	}
+
+	t.Run("brew", func(t *testing.T) {
+		_, err := newBrewManager(&fakeRunner{}).Ensure("datadog-agent", nil, "", WithVersion("7.50.0"))
+		assert.ErrorContains(t, err, "version pinning is not supported by brew")
+	})
+}
+
+func TestPackageManagerHolds(t *testing.T) {
+	tests := []struct {
+		name       string
+		newManager func(*fakeRunner) PackageManager
+		hold       string
+		unhold     string
+		sudo       bool
+	}{
+		{"apt", func(r *fakeRunner) PackageManager { return newAptManager(r) }, "apt-mark hold datadog-agent", "apt-mark unhold datadog-agent", true},
+		{"dnf", func(r *fakeRunner) PackageManager { return newDnfManager(r) }, "dnf versionlock add datadog-agent", "dnf versionlock delete datadog-agent", true},
+		{"zypper", func(r *fakeRunner) PackageManager { return newZypperManager(r) }, "zypper -n addlock datadog-agent", "zypper -n removelock datadog-agent", true},
+		{"brew", func(r *fakeRunner) PackageManager { return newBrewManager(r) }, "brew pin datadog-agent", "brew unpin datadog-agent", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeRunner{}
+			manager := tt.newManager(runner)
+			_, err := manager.Hold("datadog-agent", nil)
+			require.NoError(t, err)
+			_, err = manager.Unhold("datadog-agent", nil)
+			require.NoError(t, err)
+
+			require.Len(t, runner.commands, 2)
+			hold, unhold := runner.commands[0], runner.commands[1]
+			assert.Contains(t, createCommand(t, hold), tt.hold)
+			assert.Equal(t, pulumi.String(tt.unhold), hold.Delete, "the hold should be removed on delete")
+			assert.Equal(t, tt.unhold, createCommand(t, unhold))
+			assert.Nil(t, unhold.Delete)
+			assert.Equal(t, tt.sudo, hold.Sudo)
+		})
+	}
+
+	t.Run("pacman", func(t *testing.T) {
+		_, err := newPacmanManager(&fakeRunner{}).Hold("datadog-agent", nil)
+		assert.ErrorContains(t, err, "holding packages is not supported by pacman")
+	})
+}
+
+func TestPackageManagerRepositories(t *testing.T) {
+	repository := PackageRepository{
+		Name:         "datadog",
+		URL:          "https://yum.datadoghq.com/stable/7/$basearch/",
+		KeyURL:       "https://keys.datadoghq.com/DATADOG_RPM_KEY_CURRENT.public",
+		Distribution: "stable",
+		Components:   []string{"7"},
+	}
+
+	tests := []struct {
+		name       string
+		newManager func(*fakeRunner) PackageManager
+		create     []string
+		delete     string
+	}{
+		{
+			"apt",
+			func(r *fakeRunner) PackageManager { return newAptManager(r) },
+			[]string{"gpg --dearmor --yes -o /usr/share/keyrings/datadog-archive-keyring.gpg", `"deb [signed-by=/usr/share/keyrings/datadog-archive-keyring.gpg] https://yum.datadoghq.com/stable/7/$basearch/ stable 7" > /etc/apt/sources.list.d/datadog.list`},
+			"rm -f /etc/apt/sources.list.d/datadog.list /usr/share/keyrings/datadog-archive-keyring.gpg",
+		},
+		{
+			"yum",
+			func(r *fakeRunner) PackageManager { return newYumManager(r) },
+			[]string{`"baseurl=https://yum.datadoghq.com/stable/7/\$basearch/"`, `"gpgcheck=1"`, "> /etc/yum.repos.d/datadog.repo"},
+			"rm -f /etc/yum.repos.d/datadog.repo",
+		},
+		{
+			"zypper",
+			func(r *fakeRunner) PackageManager { return newZypperManager(r) },
+			[]string{"rpm --import https://keys.datadoghq.com/DATADOG_RPM_KEY_CURRENT.public", `zypper -n addrepo --refresh --gpgcheck "https://yum.datadoghq.com/stable/7/\$basearch/" datadog`},
+			"zypper -n removerepo datadog",
+		},
+		{
+			"brew",
+			func(r *fakeRunner) PackageManager { return newBrewManager(r) },
+			[]string{"brew tap datadog https://yum.datadoghq.com/stable/7/$basearch/"},
+			"brew untap datadog",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeRunner{}
+			_, err := tt.newManager(runner).AddRepository(repository, nil)
+			require.NoError(t, err)
+
+			require.Len(t, runner.commands, 1)
+			for _, expected := range tt.create {
+				assert.Contains(t, createCommand(t, runner.commands[0]), expected)
+			}
+			assert.Equal(t, pulumi.String(tt.delete), runner.commands[0].Delete)
+		})
+	}
+}
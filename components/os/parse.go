@@ -0,0 +1,197 @@
+package os
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// flavorAliases maps the accepted spellings of each flavor, the empty string defaults to Ubuntu
+var flavorAliases = map[string]Flavor{
+	"":                 Ubuntu,
+	"ubuntu":           Ubuntu,
+	"amazon-linux":     AmazonLinux,
+	"amazonlinux":      AmazonLinux,
+	"amazon-linux-ecs": AmazonLinuxECS,
+	"amazonlinuxecs":   AmazonLinuxECS,
+	"debian":           Debian,
+	"redhat":           RedHat,
+	"suse":             Suse,
+	"fedora":           Fedora,
+	"centos":           CentOS,
+	"rocky-linux":      RockyLinux,
+	"rockylinux":       RockyLinux,
+	"alpine":           Alpine,
+	"alma-linux":       AlmaLinux,
+	"almalinux":        AlmaLinux,
+	"oracle-linux":     OracleLinux,
+	"oraclelinux":      OracleLinux,
+	"opensuse-leap":    OpenSuseLeap,
+	"opensuse":         OpenSuseLeap,
+	"arch-linux":       ArchLinux,
+	"archlinux":        ArchLinux,
+	"arch":             ArchLinux,
+	"windows":          WindowsServer,
+	"windows-server":   WindowsServer,
+	"windows-client":   WindowsClient,
+	"macos":            MacosOS,
+}
+
+// architectureAliases maps the accepted spellings of each architecture, the empty string defaults to AMD64
+var architectureAliases = map[string]Architecture{
+	"":           AMD64Arch,
+	"x86_64":     AMD64Arch,
+	"amd64":      AMD64Arch,
+	"x86_64_mac": AMD64Arch,
+	"arm64":      ARM64Arch,
+	"aarch64":    ARM64Arch,
+	"arm64_mac":  ARM64Arch,
+}
+
+// ParseFlavor returns the flavor matching flavorStr, or an error suggesting close matches
+func ParseFlavor(flavorStr string) (Flavor, error) {
+	flavorStr = strings.ToLower(flavorStr)
+	if flavor, ok := flavorAliases[flavorStr]; ok {
+		return flavor, nil
+	}
+
+	return Unknown, fmt.Errorf("unknown OS flavor: %s%s", flavorStr, suggestionMessage(flavorStr, lo.Keys(flavorAliases)))
+}
+
+// ParseArchitecture returns the architecture matching archStr, or an error suggesting close matches
+func ParseArchitecture(archStr string) (Architecture, error) {
+	archStr = strings.ToLower(archStr)
+	if arch, ok := architectureAliases[archStr]; ok {
+		return arch, nil
+	}
+
+	return "", fmt.Errorf("unknown architecture: %s%s", archStr, suggestionMessage(archStr, lo.Keys(architectureAliases)))
+}
+
+// ParseDescriptor parses a descriptor in the format <flavor>:<version>(:<arch>), defaultDescriptor is returned if descStr is empty.
+// The version is normalized, see NormalizeVersion. Windows client versions may contain the separator, for instance `windows-11:win11-24h2-pro`.
+func ParseDescriptor(descStr string, defaultDescriptor Descriptor) (Descriptor, error) {
+	if descStr == "" {
+		return defaultDescriptor, nil
+	}
+
+	parts := strings.Split(descStr, osDescriptorSep)
+	if len(parts) < 2 {
+		return Descriptor{}, fmt.Errorf("invalid OS descriptor %q, expected <flavor>:<version>(:<arch>)", descStr)
+	}
+
+	flavor, err := ParseFlavor(parts[0])
+	if err != nil {
+		return Descriptor{}, fmt.Errorf("invalid OS descriptor %q: %w", descStr, err)
+	}
+
+	versionParts := parts[1:]
+	arch := AMD64Arch
+	if len(parts) > 2 {
+		lastPart := parts[len(parts)-1]
+		parsedArch, archErr := ParseArchitecture(lastPart)
+		switch {
+		case archErr == nil:
+			arch = parsedArch
+			versionParts = parts[1 : len(parts)-1]
+		case flavor != WindowsClient:
+			return Descriptor{}, fmt.Errorf("invalid OS descriptor %q: %w", descStr, archErr)
+		}
+	}
+	if len(versionParts) > 1 && flavor != WindowsClient {
+		return Descriptor{}, fmt.Errorf("invalid OS descriptor %q, expected <flavor>:<version>(:<arch>)", descStr)
+	}
+
+	return NewDescriptorWithArch(flavor, NormalizeVersion(flavor, strings.Join(versionParts, osDescriptorSep)), arch), nil
+}
+
+// NormalizeVersion returns the canonical spelling of a version, used by ParseDescriptor and in the platforms tables.
+// Linux versions are lower case and use dashes as separators, for instance `22.04` becomes `22-04` and `15-sp4` becomes `15-4`.
+// Red Hat like versions join the major and minor versions, for instance `8.6` becomes `86` and `7.9` becomes `79`.
+// Versions of other families are provider-specific identifiers and are returned as is.
+func NormalizeVersion(flavor Flavor, version string) string {
+	if flavor.Type() != LinuxFamily {
+		return version
+	}
+
+	version = strings.ToLower(version)
+	if flavor == RedHat || flavor == CentOS || flavor == RockyLinux {
+		version = strings.ReplaceAll(version, ".", "")
+	}
+	version = strings.NewReplacer(".", "-", "_", "-").Replace(version)
+	if flavor == Suse || flavor == OpenSuseLeap {
+		version = strings.ReplaceAll(version, "-sp", "-")
+	}
+
+	return version
+}
+
+// ValidateVersion returns an error suggesting close matches if the version of the descriptor is not one of knownVersions
+func ValidateVersion(desc Descriptor, knownVersions []string) error {
+	for _, known := range knownVersions {
+		if known == desc.Version {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("version %q is not supported for %s on %s%s", desc.Version, desc.Flavor, desc.Architecture, suggestionMessage(desc.Version, knownVersions))
+}
+
+// suggestionMessage returns a message listing the candidates close to value, or the empty string if there are none
+func suggestionMessage(value string, candidates []string) string {
+	maxDistance := len(value)/3 + 1
+	type match struct {
+		candidate string
+		distance  int
+	}
+
+	var matches []match
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if distance := levenshteinDistance(value, candidate); distance <= maxDistance {
+			matches = append(matches, match{candidate, distance})
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance == matches[j].distance {
+			return matches[i].candidate < matches[j].candidate
+		}
+		return matches[i].distance < matches[j].distance
+	})
+	suggestions := make([]string, 0, len(matches))
+	for _, m := range matches {
+		suggestions = append(suggestions, m.candidate)
+	}
+
+	return fmt.Sprintf(", did you mean: %s?", strings.Join(suggestions, ", "))
+}
+
+func levenshteinDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
@@ -0,0 +1,50 @@
+package os
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDescriptor(t *testing.T) {
+	tests := []struct {
+		descStr string
+		want    Descriptor
+	}{
+		{"", UbuntuDefault},
+		{"ubuntu:22.04", Ubuntu2204},
+		{"ubuntu:22-04:arm64", Ubuntu2204.WithArch(ARM64Arch)},
+		{"suse:15-sp4:aarch64", Suse15.WithArch(ARM64Arch)},
+		{"redhat:8.6", NewDescriptor(RedHat, "86")},
+		{"redhat:8.6-fips:arm64", NewDescriptorWithArch(RedHat, "86-fips", ARM64Arch)},
+		{"centos:7.9", CentOS7},
+		{"windows-client:windows-11:win11-24h2-pro", WindowsClient1124H2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.descStr, func(t *testing.T) {
+			desc, err := ParseDescriptor(tt.descStr, UbuntuDefault)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, desc)
+		})
+	}
+}
+
+func TestParseDescriptorErrors(t *testing.T) {
+	_, err := ParseDescriptor("ubunt:22-04", UbuntuDefault)
+	assert.ErrorContains(t, err, "did you mean: ubuntu?")
+
+	_, err = ParseDescriptor("ubuntu:22-04:arm46", UbuntuDefault)
+	assert.ErrorContains(t, err, "unknown architecture")
+
+	_, err = ParseDescriptor("ubuntu", UbuntuDefault)
+	assert.Error(t, err)
+
+	err = ValidateVersion(NewDescriptor(Ubuntu, "22-4"), []string{"20-04", "22-04", "24-04"})
+	assert.ErrorContains(t, err, "did you mean: 22-04")
+}
+
+func TestNewDescriptorKeepsVersion(t *testing.T) {
+	assert.Equal(t, "15-sp4", NewDescriptor(Suse, "15-sp4").Version)
+}
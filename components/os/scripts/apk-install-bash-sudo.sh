@@ -0,0 +1,7 @@
+#!/bin/sh
+
+set -e
+
+# Alpine images ship without bash, sudo and shadow (useradd), which are required to run commands on the host
+apk add --no-cache bash sudo shadow
+echo '%wheel ALL=(ALL) NOPASSWD: ALL' > /etc/sudoers.d/wheel
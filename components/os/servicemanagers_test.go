@@ -0,0 +1,102 @@
+package os
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/common/namer"
+	"github.com/DataDog/test-infra-definitions/components/command"
+)
+
+type fakeEnv struct {
+	config.Env
+}
+
+func (fakeEnv) CommonNamer() namer.Namer {
+	return namer.NewNamer(nil, "test")
+}
+
+// fakeRunner records the commands instead of creating them
+type fakeRunner struct {
+	command.Runner
+	names    []string
+	commands []*command.Args
+}
+
+func (r *fakeRunner) Command(name string, args command.RunnerCommandArgs, _ ...pulumi.ResourceOption) (command.Command, error) {
+	r.names = append(r.names, name)
+	r.commands = append(r.commands, args.Arguments())
+	return nil, nil
+}
+
+// createCommand returns the Create command of args, which are plain strings in service managers
+func createCommand(t *testing.T, args *command.Args) string {
+	create, ok := args.Create.(pulumi.String)
+	require.True(t, ok)
+	return string(create)
+}
+
+func TestServiceManagers(t *testing.T) {
+	tests := []struct {
+		name       string
+		newManager func(config.Env, command.Runner) ServiceManager
+		stop       string
+		enable     string
+		disable    string
+		active     string
+		logs       string
+	}{
+		{"systemd", newSystemdServiceManager, "systemctl stop datadog-agent", "systemctl enable datadog-agent", "systemctl disable datadog-agent", "systemctl is-active --quiet datadog-agent", "journalctl -u datadog-agent -n 10 --no-pager"},
+		{"sysvinit", newSysvinitServiceManager, "sudo stop datadog-agent || true", "chkconfig datadog-agent on", "chkconfig datadog-agent off", "status datadog-agent | grep -q running", "tail -n 10 /var/log/upstart/datadog-agent.log"},
+		{"openrc", newOpenRCServiceManager, "rc-service datadog-agent stop", "rc-update add datadog-agent default", "rc-update del datadog-agent default", "rc-service datadog-agent status", `grep "datadog-agent" /var/log/messages | tail -n 10`},
+		{"macos", newMacOSServiceManager, "launchctl kill SIGTERM datadog-agent", "launchctl enable datadog-agent", "launchctl disable datadog-agent", `launchctl print datadog-agent | grep -q "state = running"`, "| tail -n 10"},
+		{"windows", newWindowsServiceManager, "Stop-Service -Force -Name datadog-agent", "Set-Service -Name datadog-agent -StartupType Automatic", "Set-Service -Name datadog-agent -StartupType Disabled", "WaitForStatus('Running', [TimeSpan]::FromSeconds(30))", "Select-Object -First 10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeRunner{}
+			manager := tt.newManager(fakeEnv{}, runner)
+
+			_, err := manager.EnsureStopped("datadog-agent", nil)
+			require.NoError(t, err)
+			_, err = manager.EnsureEnabled("datadog-agent", nil)
+			require.NoError(t, err)
+			_, err = manager.EnsureDisabled("datadog-agent", nil)
+			require.NoError(t, err)
+			_, err = manager.WaitForActive("datadog-agent", 30*time.Second, nil)
+			require.NoError(t, err)
+			_, err = manager.CollectLogs("datadog-agent", 10, nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{
+				"test-stopped-datadog-agent",
+				"test-enabled-datadog-agent",
+				"test-disabled-datadog-agent",
+				"test-active-datadog-agent",
+				"test-logs-datadog-agent",
+			}, runner.names)
+			for i, expected := range []string{tt.stop, tt.enable, tt.disable, tt.active, tt.logs} {
+				assert.Contains(t, createCommand(t, runner.commands[i]), expected)
+			}
+			assert.NotNil(t, runner.commands[1].Delete, "enabling the service should be reverted on delete")
+			assert.NotNil(t, runner.commands[2].Delete, "disabling the service should be reverted on delete")
+		})
+	}
+
+	t.Run("the same operation can run twice with a name suffix", func(t *testing.T) {
+		runner := &fakeRunner{}
+		manager := newSystemdServiceManager(fakeEnv{}, runner)
+
+		_, err := manager.EnsureStopped("datadog-agent", nil)
+		require.NoError(t, err)
+		_, err = manager.EnsureStopped("datadog-agent", command.WithNameSuffix("again"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"test-stopped-datadog-agent", "test-stopped-datadog-agent-again"}, runner.names)
+	})
+}
@@ -0,0 +1,122 @@
+package os
+
+import (
+	stdos "os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/test-infra-definitions/components/command"
+)
+
+const testCAPEM = `-----BEGIN CERTIFICATE-----
+MIIBhTCCASugAwIBAgIQIRi6zePL6mKjOipn+dNuaTAKBggqhkjOPQQDAjASMRAw
+DgYDVQQKEwdBY21lIENvMB4XDTE3MTAyMDE5NDMwNloXDTE4MTAyMDE5NDMwNlow
+-----END CERTIFICATE-----`
+
+func resolveString(t *testing.T, input pulumi.StringInput) string {
+	values := make(chan string, 1)
+	input.ToStringOutput().ApplyT(func(v string) string {
+		values <- v
+		return v
+	})
+	return <-values
+}
+
+func TestLinuxTrustStore(t *testing.T) {
+	tests := []struct {
+		flavor    Flavor
+		certPath  string
+		updateCmd string
+	}{
+		{Ubuntu, "/usr/local/share/ca-certificates/proxy.crt", "update-ca-certificates"},
+		{Alpine, "/usr/local/share/ca-certificates/proxy.crt", "update-ca-certificates"},
+		{RedHat, "/etc/pki/ca-trust/source/anchors/proxy.crt", "update-ca-trust extract"},
+		{OpenSuseLeap, "/etc/pki/trust/anchors/proxy.crt", "update-ca-certificates"},
+		{ArchLinux, "/etc/ca-certificates/trust-source/anchors/proxy.crt", "update-ca-trust extract"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flavor.String(), func(t *testing.T) {
+			runner := &fakeRunner{}
+			_, err := newLinuxTrustStore(fakeEnv{}, NewDescriptor(tt.flavor, ""), runner).EnsureCA("proxy", pulumi.String(testCAPEM), nil)
+			require.NoError(t, err)
+
+			require.Len(t, runner.commands, 1)
+			args := runner.commands[0]
+			assert.Equal(t, []string{"test-ca-proxy"}, runner.names)
+			assert.Contains(t, createCommand(t, args), "sudo tee "+tt.certPath+" > /dev/null && sudo "+tt.updateCmd)
+			assert.Equal(t, pulumi.String("sudo rm -f "+tt.certPath+" && sudo "+tt.updateCmd), args.Delete)
+			assert.Equal(t, pulumi.String(testCAPEM), args.Environment[caCertificateEnvVar])
+		})
+	}
+
+	t.Run("the multi-line certificate should be written as is through the command environment", func(t *testing.T) {
+		runner := &fakeRunner{}
+		_, err := newLinuxTrustStore(fakeEnv{}, NewDescriptor(Ubuntu, ""), runner).EnsureCA("proxy", pulumi.String(testCAPEM), nil)
+		require.NoError(t, err)
+		args := runner.commands[0]
+
+		// Run the scripts locally, without sudo, in a temporary anchor directory
+		anchorDir := t.TempDir()
+		localScript := func(script pulumi.StringInput) string {
+			s := string(script.(pulumi.String))
+			s = strings.ReplaceAll(s, "sudo ", "")
+			s = strings.ReplaceAll(s, "/usr/local/share/ca-certificates", anchorDir)
+			return strings.ReplaceAll(s, "update-ca-certificates", "true")
+		}
+		run := func(script string) {
+			// The remote runner passes the environment by exporting it in the command
+			fullScript := resolveString(t, command.NewUnixOSCommand().BuildCommandString(pulumi.String(script), args.Environment, false, false, ""))
+			assert.Contains(t, fullScript, `export CA_CERTIFICATE_PEM="-----BEGIN CERTIFICATE-----`)
+			output, err := exec.Command("bash", "-c", fullScript).CombinedOutput()
+			require.NoError(t, err, string(output))
+		}
+
+		run(localScript(args.Create))
+		content, err := stdos.ReadFile(filepath.Join(anchorDir, "proxy.crt"))
+		require.NoError(t, err)
+		assert.Equal(t, testCAPEM+"\n", string(content))
+
+		run(localScript(args.Delete))
+		assert.NoFileExists(t, filepath.Join(anchorDir, "proxy.crt"))
+	})
+}
+
+func TestMacOSTrustStore(t *testing.T) {
+	runner := &fakeRunner{}
+	_, err := newMacOSTrustStore(fakeEnv{}, runner).EnsureCA("proxy", pulumi.String(testCAPEM), nil)
+	require.NoError(t, err)
+
+	require.Len(t, runner.commands, 1)
+	args := runner.commands[0]
+	assert.Equal(t, `sudo mkdir -p /usr/local/share/test-infra-ca && printf '%s\n' "$CA_CERTIFICATE_PEM" | sudo tee /usr/local/share/test-infra-ca/proxy.pem > /dev/null && `+
+		`sudo security add-trusted-cert -d -r trustRoot -k /Library/Keychains/System.keychain /usr/local/share/test-infra-ca/proxy.pem`, createCommand(t, args))
+	// The certificate is found by its fingerprint on destroy
+	assert.Equal(t, pulumi.String(`sudo security delete-certificate -Z "$(openssl x509 -noout -fingerprint -sha1 -in /usr/local/share/test-infra-ca/proxy.pem | cut -d= -f2 | tr -d :)" -t /Library/Keychains/System.keychain; `+
+		`sudo rm -f /usr/local/share/test-infra-ca/proxy.pem`), args.Delete)
+	assert.Equal(t, pulumi.String(testCAPEM), args.Environment[caCertificateEnvVar])
+}
+
+func TestWindowsTrustStore(t *testing.T) {
+	runner := &fakeRunner{}
+	_, err := newWindowsTrustStore(fakeEnv{}, runner).EnsureCA("proxy", pulumi.String(testCAPEM), nil)
+	require.NoError(t, err)
+
+	require.Len(t, runner.commands, 1)
+	args := runner.commands[0]
+	create := createCommand(t, args)
+	assert.Contains(t, create, "[System.Text.Encoding]::ASCII.GetBytes($env:CA_CERTIFICATE_PEM)")
+	assert.Contains(t, create, `$cert.FriendlyName = "test-infra-proxy"`)
+	assert.Contains(t, create, `[System.Security.Cryptography.X509Certificates.X509Store]::new("Root", "LocalMachine")`)
+	assert.Equal(t, pulumi.String(`Get-ChildItem -Path Cert:\LocalMachine\Root | Where-Object { $_.FriendlyName -eq "test-infra-proxy" } | Remove-Item`), args.Delete)
+
+	// PowerShell keeps the line breaks of single-quoted strings
+	fullScript := resolveString(t, command.NewWindowsOSCommand().BuildCommandString(args.Create, args.Environment, false, false, ""))
+	assert.True(t, strings.HasPrefix(fullScript, "$env:CA_CERTIFICATE_PEM = '"+testCAPEM+"';"), fullScript)
+}
@@ -0,0 +1,41 @@
+package os
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/components/command"
+)
+
+func TestUserManagersPassword(t *testing.T) {
+	tests := []struct {
+		name       string
+		newManager func(config.Env, command.Runner) UserManager
+	}{
+		{"linux", newLinuxUserManager},
+		{"macos", newMacOSUserManager},
+		{"windows", newWindowsUserManager},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeRunner{}
+			manager := tt.newManager(fakeEnv{}, runner)
+
+			_, err := manager.EnsureUser(User{Name: "ddtest", Password: pulumi.String("s3cr3t")}, nil)
+			require.NoError(t, err)
+			_, err = manager.EnsureUser(User{Name: "nopassword"}, nil)
+			require.NoError(t, err)
+
+			require.Len(t, runner.commands, 2)
+			assert.NotContains(t, createCommand(t, runner.commands[0]), "s3cr3t")
+			assert.Empty(t, runner.commands[0].Environment)
+			assert.NotNil(t, runner.commands[0].Stdin)
+			assert.Nil(t, runner.commands[1].Stdin)
+		})
+	}
+}
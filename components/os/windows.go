@@ -11,6 +11,10 @@ func newWindowsOS(e config.Env, desc Descriptor, runner command.Runner) OS {
 		runner:         runner,
 		fileManager:    command.NewFileManager(runner),
 		serviceManager: newWindowsServiceManager(e, runner),
+		userManager:    newWindowsUserManager(e, runner),
+		firewall:       newWindowsFirewall(e, runner),
+		diskManager:    newWindowsDiskManager(e, runner),
+		trustStore:     newWindowsTrustStore(e, runner),
 	}
 
 	return os
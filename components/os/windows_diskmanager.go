@@ -0,0 +1,58 @@
+package os
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/components/command"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+var windowsDriveLetterRegexp = regexp.MustCompile(`^[a-zA-Z]:\\?$`)
+
+type windowsDiskManager struct {
+	e      config.Env
+	runner command.Runner
+}
+
+func newWindowsDiskManager(e config.Env, runner command.Runner) DiskManager {
+	return &windowsDiskManager{e: e, runner: runner}
+}
+
+func (m *windowsDiskManager) EnsureMounted(disk Disk, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	disk, err := disk.withDefaults(NTFSFileSystem, NTFSFileSystem)
+	if err != nil {
+		return nil, err
+	}
+
+	// Access paths of a partition always end with a backslash
+	accessPath := strings.TrimSuffix(disk.MountPoint, `\`) + `\`
+	script := []string{
+		`$ErrorActionPreference = "Stop"`,
+		// The disk is only formatted if no volume has the label, the first raw disk of the expected size is used
+		fmt.Sprintf(`if (-not (Get-Volume -FileSystemLabel "%s" -ErrorAction SilentlyContinue)) {`, disk.Label),
+		fmt.Sprintf(`  $disk = Get-Disk | Where-Object { $_.PartitionStyle -eq "RAW" -and $_.Size -eq %dGB } | Select-Object -First 1`, disk.SizeGiB),
+		fmt.Sprintf(`  if (-not $disk) { throw "no unused disk of %dGiB found" }`, disk.SizeGiB),
+		`  if ($disk.IsOffline) { Set-Disk -Number $disk.Number -IsOffline $false }`,
+		fmt.Sprintf(`  $disk | Initialize-Disk -PartitionStyle GPT -PassThru | New-Partition -UseMaximumSize | Format-Volume -FileSystem NTFS -NewFileSystemLabel "%s" -Confirm:$false | Out-Null`, disk.Label),
+		`}`,
+		fmt.Sprintf(`$partition = Get-Volume -FileSystemLabel "%s" | Get-Partition`, disk.Label),
+	}
+	if windowsDriveLetterRegexp.MatchString(disk.MountPoint) {
+		script = append(script, fmt.Sprintf(`if ($partition.DriveLetter -ne '%[1]c') { Set-Partition -InputObject $partition -NewDriveLetter %[1]c }`, disk.MountPoint[0]))
+	} else {
+		script = append(script,
+			fmt.Sprintf(`New-Item -ItemType Directory -Force -Path "%s" | Out-Null`, disk.MountPoint),
+			fmt.Sprintf(`if ($partition.AccessPaths -notcontains "%[1]s") { Add-PartitionAccessPath -InputObject $partition -AccessPath "%[1]s" }`, accessPath),
+		)
+	}
+
+	// The data is kept on destroy, only the access path is removed
+	return runNamedCommand(m.e, m.runner, "disk", disk.Label, &command.Args{
+		Create: pulumi.String(strings.Join(script, "\n")),
+		Delete: pulumi.String(fmt.Sprintf(`Get-Volume -FileSystemLabel "%s" -ErrorAction SilentlyContinue | Get-Partition | Remove-PartitionAccessPath -AccessPath "%s" -ErrorAction SilentlyContinue`, disk.Label, accessPath)),
+	}, transform, opts...)
+}
@@ -0,0 +1,57 @@
+package os
+
+import (
+	"fmt"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/components/command"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// windowsFirewallGroup groups all the Windows Defender Firewall rules created by a Firewall
+const windowsFirewallGroup = "test-infra"
+
+type windowsFirewall struct {
+	e      config.Env
+	runner command.Runner
+}
+
+func newWindowsFirewall(e config.Env, runner command.Runner) Firewall {
+	return &windowsFirewall{e: e, runner: runner}
+}
+
+func (f *windowsFirewall) AllowPort(port int, protocol FirewallProtocol, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	if err := validateFirewallProtocol(protocol); err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%s-%d", protocol, port)
+	id := firewallRuleID("allow", name)
+	return runNamedCommand(f.e, f.runner, "firewall-allow", name, &command.Args{
+		Create: pulumi.String(fmt.Sprintf(`New-NetFirewallRule -Name "%[1]s" -DisplayName "%[1]s" -Group "%[2]s" -Direction Inbound -Protocol %[3]s -LocalPort %[4]d -Action Allow | Out-Null`,
+			id, windowsFirewallGroup, protocol, port)),
+		Delete: pulumi.String(fmt.Sprintf(`Remove-NetFirewallRule -Name "%s" -ErrorAction SilentlyContinue`, id)),
+	}, transform, opts...)
+}
+
+func (f *windowsFirewall) BlockEgress(destination string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	id := firewallRuleID("egress", destination)
+
+	addresses := fmt.Sprintf(`"%s"`, destination)
+	if !isIPOrCIDR(destination) {
+		addresses = fmt.Sprintf(`([System.Net.Dns]::GetHostAddresses("%s") | Where-Object AddressFamily -eq InterNetwork | ForEach-Object { $_.IPAddressToString })`, destination)
+	}
+
+	return runNamedCommand(f.e, f.runner, "firewall-block-egress", destination, &command.Args{
+		Create: pulumi.String(fmt.Sprintf(`New-NetFirewallRule -Name "%[1]s" -DisplayName "%[1]s" -Group "%[2]s" -Direction Outbound -RemoteAddress %[3]s -Action Block | Out-Null`,
+			id, windowsFirewallGroup, addresses)),
+		Delete: pulumi.String(fmt.Sprintf(`Remove-NetFirewallRule -Name "%s" -ErrorAction SilentlyContinue`, id)),
+	}, transform, opts...)
+}
+
+func (f *windowsFirewall) Reset(transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(f.e, f.runner, "firewall", "reset", &command.Args{
+		Create: pulumi.String(fmt.Sprintf(`Remove-NetFirewallRule -Group "%s" -ErrorAction SilentlyContinue`, windowsFirewallGroup)),
+	}, transform, opts...)
+}
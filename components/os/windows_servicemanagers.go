@@ -1,6 +1,9 @@
 package os
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/DataDog/test-infra-definitions/common/config"
 	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
@@ -16,15 +19,51 @@ func newWindowsServiceManager(e config.Env, runner command.Runner) ServiceManage
 }
 
 func (s *windowsServiceManager) EnsureRestarted(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
-	cmdName := s.e.CommonNamer().ResourceName("running", serviceName)
-	var cmdArgs command.RunnerCommandArgs = &command.Args{
+	return runNamedCommand(s.e, s.runner, "running", serviceName, &command.Args{
 		Create: pulumi.String("Restart-Service -Name " + serviceName),
-	}
+	}, transform, opts...)
+}
+
+func (s *windowsServiceManager) EnsureStopped(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "stopped", serviceName, &command.Args{
+		Create: pulumi.String("Stop-Service -Force -Name " + serviceName),
+	}, transform, opts...)
+}
+
+func (s *windowsServiceManager) EnsureEnabled(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "enabled", serviceName, &command.Args{
+		Create: pulumi.String(fmt.Sprintf("Set-Service -Name %s -StartupType Automatic", serviceName)),
+		Delete: pulumi.String(fmt.Sprintf("Set-Service -Name %s -StartupType Manual", serviceName)),
+	}, transform, opts...)
+}
 
-	// If a transform is provided, use it to modify the command name and args
-	if transform != nil {
-		cmdName, cmdArgs = transform(cmdName, cmdArgs)
-	}
+func (s *windowsServiceManager) EnsureDisabled(serviceName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "disabled", serviceName, &command.Args{
+		Create: pulumi.String(fmt.Sprintf("Set-Service -Name %s -StartupType Disabled", serviceName)),
+		Delete: pulumi.String(fmt.Sprintf("Set-Service -Name %s -StartupType Automatic", serviceName)),
+	}, transform, opts...)
+}
+
+func (s *windowsServiceManager) WaitForActive(serviceName string, timeout time.Duration, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "active", serviceName, &command.Args{
+		Create: pulumi.String(fmt.Sprintf(`
+try {
+	(Get-Service -Name %[1]s).WaitForStatus('Running', [TimeSpan]::FromSeconds(%[2]d))
+} catch {
+	Get-Service -Name %[1]s | Format-List *
+	%[3]s
+	Exit 1
+}`, serviceName, int(timeout.Seconds()), windowsLogsCommand(serviceName, serviceLogsLinesOnFailure))),
+	}, transform, opts...)
+}
+
+func (s *windowsServiceManager) CollectLogs(serviceName string, lines int, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(s.e, s.runner, "logs", serviceName, &command.Args{
+		Create: pulumi.String(windowsLogsCommand(serviceName, lines)),
+	}, transform, opts...)
+}
 
-	return s.runner.Command(cmdName, cmdArgs, opts...)
+// windowsLogsCommand reads the event log entries emitted by the service or by the Service Control Manager about the service
+func windowsLogsCommand(serviceName string, lines int) string {
+	return fmt.Sprintf(`Get-WinEvent -LogName Application,System -MaxEvents 2000 -ErrorAction SilentlyContinue | Where-Object { $_.ProviderName -like '*%[1]s*' -or ($_.ProviderName -eq 'Service Control Manager' -and $_.Message -like '*%[1]s*') } | Select-Object -First %[2]d | Format-List TimeCreated,ProviderName,Id,LevelDisplayName,Message`, serviceName, lines)
 }
@@ -0,0 +1,40 @@
+package os
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/components/command"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+type windowsTrustStore struct {
+	e      config.Env
+	runner command.Runner
+}
+
+func newWindowsTrustStore(e config.Env, runner command.Runner) TrustStore {
+	return &windowsTrustStore{e: e, runner: runner}
+}
+
+func (s *windowsTrustStore) EnsureCA(name string, pem pulumi.StringInput, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	// The friendly name identifies the certificate on destroy
+	friendlyName := "test-infra-" + name
+	script := []string{
+		`$ErrorActionPreference = "Stop"`,
+		fmt.Sprintf(`$cert = [System.Security.Cryptography.X509Certificates.X509Certificate2]::new([System.Text.Encoding]::ASCII.GetBytes($env:%s))`, caCertificateEnvVar),
+		fmt.Sprintf(`$cert.FriendlyName = "%s"`, friendlyName),
+		`$store = [System.Security.Cryptography.X509Certificates.X509Store]::new("Root", "LocalMachine")`,
+		`$store.Open("ReadWrite")`,
+		`$store.Add($cert)`,
+		`$store.Close()`,
+	}
+
+	return runNamedCommand(s.e, s.runner, "ca", name, &command.Args{
+		Create:      pulumi.String(strings.Join(script, "\n")),
+		Delete:      pulumi.String(fmt.Sprintf(`Get-ChildItem -Path Cert:\LocalMachine\Root | Where-Object { $_.FriendlyName -eq "%s" } | Remove-Item`, friendlyName)),
+		Environment: pulumi.StringMap{caCertificateEnvVar: pem},
+	}, transform, opts...)
+}
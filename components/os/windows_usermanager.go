@@ -0,0 +1,73 @@
+package os
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/components/command"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+type windowsUserManager struct {
+	e      config.Env
+	runner command.Runner
+}
+
+func newWindowsUserManager(e config.Env, runner command.Runner) UserManager {
+	return &windowsUserManager{e: e, runner: runner}
+}
+
+func (m *windowsUserManager) EnsureUser(user User, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	if user.Shell != "" || user.Home != "" {
+		return nil, errors.New("setting the shell or the home directory of a user is not supported on Windows")
+	}
+
+	newUserParams := "-NoPassword"
+	if user.Password != nil {
+		newUserParams = "-Password $userPassword"
+	}
+
+	script := []string{
+		`$ErrorActionPreference = "Stop"`,
+	}
+	if user.Password != nil {
+		script = append(script, `$userPassword = ConvertTo-SecureString ([Console]::In.ReadLine()) -AsPlainText -Force`)
+	}
+	script = append(script,
+		fmt.Sprintf(`if (-not (Get-LocalUser -Name "%[1]s" -ErrorAction SilentlyContinue)) { New-LocalUser -Name "%[1]s" %[2]s | Out-Null }`, user.Name, newUserParams),
+	)
+	if user.Password != nil {
+		script = append(script, fmt.Sprintf(`Set-LocalUser -Name "%s" -Password $userPassword`, user.Name))
+	}
+	for _, group := range user.Groups {
+		script = append(script, fmt.Sprintf(`if (-not (Get-LocalGroupMember -Group "%[2]s" -Member "%[1]s" -ErrorAction SilentlyContinue)) { Add-LocalGroupMember -Group "%[2]s" -Member "%[1]s" }`, user.Name, group))
+	}
+	if len(user.AuthorizedKeys) > 0 {
+		// The profile of the user is only created at first logon, the default profile path is used
+		quotedKeys := make([]string, 0, len(user.AuthorizedKeys))
+		for _, key := range user.AuthorizedKeys {
+			quotedKeys = append(quotedKeys, fmt.Sprintf("'%s'", strings.ReplaceAll(key, "'", "''")))
+		}
+		script = append(script,
+			fmt.Sprintf(`$sshDir = Join-Path (Split-Path $env:USERPROFILE) "%s\.ssh"`, user.Name),
+			`New-Item -ItemType Directory -Force -Path $sshDir | Out-Null`,
+			fmt.Sprintf(`Set-Content -Path (Join-Path $sshDir "authorized_keys") -Value @(%s)`, strings.Join(quotedKeys, ", ")),
+		)
+	}
+
+	return runNamedCommand(m.e, m.runner, "user", user.Name, &command.Args{
+		Create: pulumi.String(strings.Join(script, "\n")),
+		Delete: pulumi.String(fmt.Sprintf(`Remove-LocalUser -Name "%s"`, user.Name)),
+		Stdin:  user.passwordStdin("%[2]s\n"),
+	}, transform, opts...)
+}
+
+func (m *windowsUserManager) EnsureGroup(groupName string, transform command.Transformer, opts ...pulumi.ResourceOption) (command.Command, error) {
+	return runNamedCommand(m.e, m.runner, "group", groupName, &command.Args{
+		Create: pulumi.String(fmt.Sprintf(`if (-not (Get-LocalGroup -Name "%[1]s" -ErrorAction SilentlyContinue)) { New-LocalGroup -Name "%[1]s" | Out-Null }`, groupName)),
+		Delete: pulumi.String(fmt.Sprintf(`Remove-LocalGroup -Name "%s"`, groupName)),
+	}, transform, opts...)
+}
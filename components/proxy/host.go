@@ -0,0 +1,198 @@
+package proxy
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/DataDog/test-infra-definitions/common"
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/common/namer"
+	"github.com/DataDog/test-infra-definitions/components"
+	"github.com/DataDog/test-infra-definitions/components/command"
+	"github.com/DataDog/test-infra-definitions/components/os"
+	remoteComp "github.com/DataDog/test-infra-definitions/components/remote"
+
+	"github.com/alessio/shellescape"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+const (
+	aptProxyConfigPath     = "/etc/apt/apt.conf.d/95test-infra-proxy"
+	dnfConfigPath          = "/etc/dnf/dnf.conf"
+	yumConfigPath          = "/etc/yum.conf"
+	dockerProxyConfigDir   = "/etc/systemd/system/docker.service.d"
+	dockerProxyConfigPath  = dockerProxyConfigDir + "/http-proxy.conf"
+	environmentFilePath    = "/etc/environment"
+	zypperProxyConfigPath  = "/etc/sysconfig/proxy"
+	removeProxyEnvironment = `sed -i -E "/^(http_proxy|https_proxy|no_proxy|HTTP_PROXY|HTTPS_PROXY|NO_PROXY)=/d" ` + environmentFilePath
+)
+
+type HostProxyOutput struct {
+	components.JSONImporter
+
+	URL string `json:"url"`
+}
+
+// HostProxy configures a host to send its HTTP traffic through a proxy: environment, package manager, docker daemon and WinHTTP on Windows.
+// The configuration is reverted on destroy.
+type HostProxy struct {
+	pulumi.ResourceState
+	components.Component
+
+	namer namer.Namer
+	host  *remoteComp.Host
+
+	URL pulumi.StringOutput `pulumi:"url"`
+}
+
+func (p *HostProxy) Export(ctx *pulumi.Context, out *HostProxyOutput) error {
+	return components.Export(ctx, p, out)
+}
+
+// NewHostProxy configures the host to use the proxy at proxyURL, for instance `http://10.1.2.3:3128`
+func NewHostProxy(e config.Env, host *remoteComp.Host, proxyURL pulumi.StringInput, options ...Option) (*HostProxy, error) {
+	params, err := common.ApplyOption(&Params{}, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return components.NewComponent(e, host.Name(), func(comp *HostProxy) error {
+		comp.namer = e.CommonNamer().WithPrefix("proxy")
+		comp.host = host
+		comp.URL = proxyURL.ToStringOutput()
+
+		opts := append([]pulumi.ResourceOption{pulumi.Parent(comp)}, params.ResourceOptions...)
+		switch host.OS.Descriptor().Family() {
+		case os.LinuxFamily:
+			return comp.configureLinux(params, opts...)
+		case os.WindowsFamily:
+			if params.DockerDaemon {
+				return fmt.Errorf("docker daemon proxy configuration is not supported on Windows")
+			}
+			return comp.configureWindows(params, opts...)
+		case os.MacOSFamily, os.UnknownFamily:
+			fallthrough
+		default:
+			return fmt.Errorf("proxy configuration is not supported on OS family %v", host.OS.Descriptor().Family())
+		}
+	})
+}
+
+func (p *HostProxy) configureLinux(params *Params, opts ...pulumi.ResourceOption) error {
+	noProxy := strings.Join(params.NoProxy, ",")
+
+	_, err := p.runBashCommand("environment", func(proxyURL string) (string, string) {
+		return fmt.Sprintf(`%[1]s && printf "%%s\n" "http_proxy=%[2]s" "https_proxy=%[2]s" "no_proxy=%[3]s" "HTTP_PROXY=%[2]s" "HTTPS_PROXY=%[2]s" "NO_PROXY=%[3]s" >> %[4]s`,
+			removeProxyEnvironment, proxyURL, noProxy, environmentFilePath), removeProxyEnvironment
+	}, opts...)
+	if err != nil {
+		return err
+	}
+
+	if packageManagerProxy := linuxPackageManagerProxy(p.host.OS.Descriptor().Flavor, noProxy); packageManagerProxy != nil {
+		if _, err := p.runBashCommand("package-manager", packageManagerProxy, opts...); err != nil {
+			return err
+		}
+	}
+
+	if params.DockerDaemon {
+		_, err := p.runBashCommand("docker-daemon", func(proxyURL string) (string, string) {
+			return fmt.Sprintf(`mkdir -p %[1]s && printf "[Service]\nEnvironment=\"HTTP_PROXY=%[3]s\" \"HTTPS_PROXY=%[3]s\" \"NO_PROXY=%[4]s\"\n" > %[2]s && systemctl daemon-reload && systemctl restart docker`,
+					dockerProxyConfigDir, dockerProxyConfigPath, proxyURL, noProxy),
+				fmt.Sprintf("rm -f %s && systemctl daemon-reload && (systemctl restart docker || true)", dockerProxyConfigPath)
+		}, opts...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// linuxPackageManagerProxy returns the commands configuring the package manager of the flavor, nil if it only relies on the environment
+func linuxPackageManagerProxy(flavor os.Flavor, noProxy string) func(proxyURL string) (string, string) {
+	// /etc/yum.conf is a symlink to /etc/dnf/dnf.conf on dnf based distributions, dnf.conf is edited when it exists
+	// and sed follows symlinks not to replace the link by a regular file
+	yumLikeProxy := func(proxyURL string) (string, string) {
+		configPath := fmt.Sprintf(`"$(test -f %s && echo %[1]s || echo %s)"`, dnfConfigPath, yumConfigPath)
+		removeProxy := fmt.Sprintf(`sed -i --follow-symlinks "/^proxy=/d" %s`, configPath)
+		return fmt.Sprintf(`%[1]s && sed -i --follow-symlinks "/^\[main\]/a proxy=%[2]s" %[3]s`, removeProxy, proxyURL, configPath), removeProxy
+	}
+
+	switch flavor { // nolint:exhaustive
+	case os.Debian, os.Ubuntu:
+		return func(proxyURL string) (string, string) {
+			return fmt.Sprintf(`printf "Acquire::http::Proxy \"%[1]s\";\nAcquire::https::Proxy \"%[1]s\";\n" > %[2]s`, proxyURL, aptProxyConfigPath),
+				fmt.Sprintf("rm -f %s", aptProxyConfigPath)
+		}
+	case os.AmazonLinux, os.AmazonLinuxECS, os.CentOS, os.Fedora, os.RedHat, os.RockyLinux, os.AlmaLinux, os.OracleLinux:
+		return yumLikeProxy
+	case os.Suse, os.OpenSuseLeap:
+		return func(proxyURL string) (string, string) {
+			setProxy := func(enabled, proxyURL, noProxy string) string {
+				return fmt.Sprintf(`sed -i -E -e "s|^PROXY_ENABLED=.*|PROXY_ENABLED=\"%[1]s\"|" -e "s|^HTTP_PROXY=.*|HTTP_PROXY=\"%[2]s\"|" -e "s|^HTTPS_PROXY=.*|HTTPS_PROXY=\"%[2]s\"|" -e "s|^NO_PROXY=.*|NO_PROXY=\"%[3]s\"|" %[4]s`,
+					enabled, proxyURL, noProxy, zypperProxyConfigPath)
+			}
+			return setProxy("yes", proxyURL, noProxy), setProxy("no", "", "localhost, 127.0.0.1")
+		}
+	default:
+		// apk and pacman read the proxy from the environment
+		return nil
+	}
+}
+
+func (p *HostProxy) configureWindows(params *Params, opts ...pulumi.ResourceOption) error {
+	noProxy := strings.Join(params.NoProxy, ",")
+
+	_, err := p.runCommand("environment", func(proxyURL string) (string, string) {
+		setVariables := func(proxyURL, noProxy string) string {
+			return fmt.Sprintf(`foreach ($name in "HTTP_PROXY", "HTTPS_PROXY") { [Environment]::SetEnvironmentVariable($name, %[1]s, "Machine") }
+[Environment]::SetEnvironmentVariable("NO_PROXY", %[2]s, "Machine")`, proxyURL, noProxy)
+		}
+		return setVariables(fmt.Sprintf(`"%s"`, proxyURL), fmt.Sprintf(`"%s"`, noProxy)), setVariables("$null", "$null")
+	}, false, opts...)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.runCommand("winhttp", func(proxyURL string) (string, string) {
+		// WinHTTP expects host:port without scheme
+		proxyServer := proxyURL
+		if parsedURL, err := url.Parse(proxyURL); err == nil && parsedURL.Host != "" {
+			proxyServer = parsedURL.Host
+		}
+		createCmd := fmt.Sprintf(`netsh winhttp set proxy proxy-server="%s"`, proxyServer)
+		if len(params.NoProxy) > 0 {
+			createCmd += fmt.Sprintf(` bypass-list="%s"`, strings.Join(params.NoProxy, ";"))
+		}
+		return createCmd, "netsh winhttp reset proxy"
+	}, false, opts...)
+
+	return err
+}
+
+// runBashCommand runs the commands in a bash shell with sudo
+func (p *HostProxy) runBashCommand(name string, commands func(proxyURL string) (string, string), opts ...pulumi.ResourceOption) (command.Command, error) {
+	return p.runCommand(name, func(proxyURL string) (string, string) {
+		createCmd, deleteCmd := commands(proxyURL)
+		return "bash -c " + shellescape.Quote(createCmd), "bash -c " + shellescape.Quote(deleteCmd)
+	}, true, opts...)
+}
+
+func (p *HostProxy) runCommand(name string, commands func(proxyURL string) (string, string), sudo bool, opts ...pulumi.ResourceOption) (command.Command, error) {
+	createCmd := p.URL.ApplyT(func(proxyURL string) string {
+		createCmd, _ := commands(proxyURL)
+		return createCmd
+	}).(pulumi.StringOutput)
+	deleteCmd := p.URL.ApplyT(func(proxyURL string) string {
+		_, deleteCmd := commands(proxyURL)
+		return deleteCmd
+	}).(pulumi.StringOutput)
+
+	return p.host.OS.Runner().Command(p.namer.ResourceName(name), &command.Args{
+		Create: createCmd,
+		Delete: deleteCmd,
+		Sudo:   sudo,
+	}, opts...)
+}
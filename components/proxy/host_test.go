@@ -0,0 +1,98 @@
+package proxy
+
+import (
+	stdos "os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/test-infra-definitions/components/os"
+)
+
+const testProxyURL = "http://10.0.0.1:3128"
+
+// runScript runs the script with bash, the paths of the system files are replaced by paths in root
+func runScript(t *testing.T, root string, script string) {
+	for _, path := range []string{dnfConfigPath, yumConfigPath, aptProxyConfigPath, zypperProxyConfigPath} {
+		script = strings.ReplaceAll(script, path, filepath.Join(root, path))
+	}
+	output, err := exec.Command("bash", "-c", script).CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+func writeFile(t *testing.T, root string, path string, content string) {
+	path = filepath.Join(root, path)
+	require.NoError(t, stdos.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, stdos.WriteFile(path, []byte(content), 0o644))
+}
+
+func readFile(t *testing.T, root string, path string) string {
+	content, err := stdos.ReadFile(filepath.Join(root, path))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestYumLikePackageManagerProxy(t *testing.T) {
+	t.Run("dnf.conf is edited through the yum.conf symlink", func(t *testing.T) {
+		root := t.TempDir()
+		writeFile(t, root, dnfConfigPath, "[main]\ngpgcheck=1\n")
+		require.NoError(t, stdos.Symlink(filepath.Join(root, dnfConfigPath), filepath.Join(root, yumConfigPath)))
+
+		createCmd, deleteCmd := linuxPackageManagerProxy(os.RedHat, "")(testProxyURL)
+		// Running the command twice must not duplicate the proxy
+		runScript(t, root, createCmd)
+		runScript(t, root, createCmd)
+		assert.Equal(t, "[main]\nproxy=http://10.0.0.1:3128\ngpgcheck=1\n", readFile(t, root, dnfConfigPath))
+
+		info, err := stdos.Lstat(filepath.Join(root, yumConfigPath))
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode()&stdos.ModeSymlink, "yum.conf should still be a symlink")
+
+		runScript(t, root, deleteCmd)
+		assert.Equal(t, "[main]\ngpgcheck=1\n", readFile(t, root, dnfConfigPath))
+	})
+
+	t.Run("yum.conf is edited without dnf", func(t *testing.T) {
+		root := t.TempDir()
+		writeFile(t, root, yumConfigPath, "[main]\ngpgcheck=1\n")
+
+		createCmd, deleteCmd := linuxPackageManagerProxy(os.CentOS, "")(testProxyURL)
+		runScript(t, root, createCmd)
+		assert.Equal(t, "[main]\nproxy=http://10.0.0.1:3128\ngpgcheck=1\n", readFile(t, root, yumConfigPath))
+
+		runScript(t, root, deleteCmd)
+		assert.Equal(t, "[main]\ngpgcheck=1\n", readFile(t, root, yumConfigPath))
+	})
+}
+
+func TestZypperPackageManagerProxy(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, zypperProxyConfigPath, "PROXY_ENABLED=\"no\"\nHTTP_PROXY=\"\"\nHTTPS_PROXY=\"\"\nNO_PROXY=\"localhost, 127.0.0.1\"\n")
+
+	createCmd, deleteCmd := linuxPackageManagerProxy(os.OpenSuseLeap, "localhost,169.254.169.254")(testProxyURL)
+	runScript(t, root, createCmd)
+	assert.Equal(t, "PROXY_ENABLED=\"yes\"\nHTTP_PROXY=\"http://10.0.0.1:3128\"\nHTTPS_PROXY=\"http://10.0.0.1:3128\"\nNO_PROXY=\"localhost,169.254.169.254\"\n", readFile(t, root, zypperProxyConfigPath))
+
+	runScript(t, root, deleteCmd)
+	assert.Equal(t, "PROXY_ENABLED=\"no\"\nHTTP_PROXY=\"\"\nHTTPS_PROXY=\"\"\nNO_PROXY=\"localhost, 127.0.0.1\"\n", readFile(t, root, zypperProxyConfigPath))
+}
+
+func TestAptPackageManagerProxy(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, aptProxyConfigPath, "")
+
+	createCmd, deleteCmd := linuxPackageManagerProxy(os.Ubuntu, "")(testProxyURL)
+	runScript(t, root, createCmd)
+	assert.Equal(t, "Acquire::http::Proxy \"http://10.0.0.1:3128\";\nAcquire::https::Proxy \"http://10.0.0.1:3128\";\n", readFile(t, root, aptProxyConfigPath))
+
+	runScript(t, root, deleteCmd)
+	assert.NoFileExists(t, filepath.Join(root, aptProxyConfigPath))
+}
+
+func TestPackageManagerProxyFromEnvironment(t *testing.T) {
+	assert.Nil(t, linuxPackageManagerProxy(os.Alpine, ""))
+}
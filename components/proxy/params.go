@@ -0,0 +1,45 @@
+package proxy
+
+import (
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// Params defines the parameters of the host proxy configuration.
+// The Params configuration uses the [Functional options pattern].
+//
+// The available options are:
+//   - [WithNoProxy]
+//   - [WithDockerDaemon]
+//   - [WithPulumiResourceOptions]
+//
+// [Functional options pattern]: https://dave.cheney.net/2014/10/17/functional-options-for-friendly-apis
+type Params struct {
+	NoProxy         []string
+	DockerDaemon    bool
+	ResourceOptions []pulumi.ResourceOption
+}
+
+type Option = func(*Params) error
+
+// WithNoProxy sets the hosts, domains or CIDRs that are reached without the proxy
+func WithNoProxy(noProxy ...string) Option {
+	return func(p *Params) error {
+		p.NoProxy = append(p.NoProxy, noProxy...)
+		return nil
+	}
+}
+
+// WithDockerDaemon also configures the docker daemon to pull images through the proxy, docker must already be installed on the host
+func WithDockerDaemon() Option {
+	return func(p *Params) error {
+		p.DockerDaemon = true
+		return nil
+	}
+}
+
+func WithPulumiResourceOptions(resources ...pulumi.ResourceOption) Option {
+	return func(p *Params) error {
+		p.ResourceOptions = append(p.ResourceOptions, resources...)
+		return nil
+	}
+}
@@ -2,8 +2,10 @@ package remote
 
 import (
 	"github.com/DataDog/test-infra-definitions/components"
+	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/DataDog/test-infra-definitions/components/os"
 
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
@@ -21,6 +23,8 @@ type HostOutput struct {
 	OSFlavor     os.Flavor       `json:"osFlavor"`
 	OSVersion    string          `json:"osVersion"`
 	Architecture os.Architecture `json:"architecture"`
+	// Facts are only gathered when the host facts check is enabled
+	Facts map[string]string `json:"facts,omitempty"`
 }
 
 // Host represents a remote host (for instance, a VM)
@@ -39,6 +43,13 @@ type Host struct {
 	OSFlavor      pulumi.IntOutput    `pulumi:"osFlavor"`
 	OSVersion     pulumi.StringOutput `pulumi:"osVersion"`
 	CloudProvider pulumi.StringOutput `pulumi:"cloudProvider"`
+	// Facts is nil unless the host facts check is enabled, see config.DDInfraHostFactsCheck
+	Facts pulumi.StringMapInput `pulumi:"facts"`
+
+	// readyFunc is kept to wait for the host to be ready again after a reboot
+	readyFunc command.ReadyFunc
+	// connection is kept to collect diagnostics, see NewDiagnosticsCollector
+	connection remote.ConnectionOutput
 }
 
 func (h *Host) Export(ctx *pulumi.Context, out *HostOutput) error {
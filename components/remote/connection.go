@@ -1,6 +1,12 @@
 package remote
 
 import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
 	"github.com/DataDog/test-infra-definitions/common/utils"
 
 	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
@@ -32,13 +38,126 @@ func NewConnection(host pulumi.StringInput, user string, options ...ConnectionOp
 		conn.PrivateKey = privateKey
 	}
 
-	if args.privateKeyPassword != "" {
-		conn.PrivateKeyPassword = pulumi.StringPtr(args.privateKeyPassword)
+	if args.privateKey != nil {
+		conn.PrivateKey = args.privateKey
+	}
+
+	if args.privateKeyPassword != nil {
+		conn.PrivateKeyPassword = pulumi.ToSecret(args.privateKeyPassword).(pulumi.StringOutput)
+	}
+
+	if args.password != nil {
+		conn.Password = pulumi.ToSecret(args.password).(pulumi.StringOutput)
 	}
 
 	if args.sshAgentPath != "" {
 		conn.AgentSocketPath = pulumi.StringPtr(args.sshAgentPath)
 	}
 
+	if len(args.proxyJumps) > 0 {
+		conn.Proxy = proxyFromConnections(args.proxyJumps)
+	}
+
 	return conn, nil
 }
+
+// proxyFromConnections converts the connections to the bastions into a proxy connection, reusing their key and agent settings.
+// Bastions reached through a proxy themselves are preceded by their proxy in the chain.
+func proxyFromConnections(connections []remote.ConnectionInput) remote.ProxyConnectionPtrOutput {
+	outputs := make([]interface{}, 0, len(connections))
+	for _, conn := range connections {
+		outputs = append(outputs, conn.ToConnectionOutput())
+	}
+
+	return pulumi.All(outputs...).ApplyT(func(args []interface{}) (*remote.ProxyConnection, error) {
+		chain := make([]remote.ProxyConnection, 0, len(args))
+		for _, arg := range args {
+			c := arg.(remote.Connection)
+			if c.Proxy != nil {
+				chain = append(chain, *c.Proxy)
+			}
+			chain = append(chain, remote.ProxyConnection{
+				AgentSocketPath:    c.AgentSocketPath,
+				DialErrorLimit:     c.DialErrorLimit,
+				Host:               c.Host,
+				Password:           c.Password,
+				PerDialTimeout:     c.PerDialTimeout,
+				Port:               c.Port,
+				PrivateKey:         c.PrivateKey,
+				PrivateKeyPassword: c.PrivateKeyPassword,
+				User:               c.User,
+			})
+		}
+		return proxyFromChain(chain)
+	}).(remote.ProxyConnectionPtrOutput)
+}
+
+// withStackBastion routes the connection through the bastion set in the stack configuration, unless it already has a proxy.
+// The bastion uses the user, key and agent settings of the connection unless they are set in the configuration.
+func withStackBastion(e config.Env, conn remote.ConnectionOutput) (remote.ConnectionOutput, error) {
+	if e.InfraBastion() == "" {
+		return conn, nil
+	}
+
+	user, host, port, err := parseBastion(e.InfraBastion())
+	if err != nil {
+		return remote.ConnectionOutput{}, err
+	}
+
+	privateKey := pulumi.String("").ToStringOutput()
+	if path := e.InfraBastionPrivateKeyPath(); path != "" {
+		privateKey, err = utils.ReadSecretFile(path)
+		if err != nil {
+			return remote.ConnectionOutput{}, err
+		}
+	}
+
+	return pulumi.All(conn, privateKey).ApplyT(func(args []interface{}) remote.Connection {
+		conn := args[0].(remote.Connection)
+		bastionKey := args[1].(string)
+		if conn.Proxy != nil {
+			return conn
+		}
+
+		proxy := &remote.ProxyConnection{
+			Host:               host,
+			Port:               utils.Pointer(float64(port)),
+			User:               conn.User,
+			AgentSocketPath:    conn.AgentSocketPath,
+			DialErrorLimit:     conn.DialErrorLimit,
+			PerDialTimeout:     conn.PerDialTimeout,
+			PrivateKey:         conn.PrivateKey,
+			PrivateKeyPassword: conn.PrivateKeyPassword,
+		}
+		if user != "" {
+			proxy.User = &user
+		}
+		if bastionKey != "" {
+			proxy.PrivateKey = &bastionKey
+			proxy.PrivateKeyPassword = nil
+		}
+		conn.Proxy = proxy
+
+		return conn
+	}).(remote.ConnectionOutput), nil
+}
+
+// parseBastion parses a bastion in the `[user@]host[:port]` format, the port defaults to 22
+func parseBastion(bastion string) (user string, host string, port int, err error) {
+	if at := strings.LastIndex(bastion, "@"); at >= 0 {
+		user, bastion = bastion[:at], bastion[at+1:]
+	}
+
+	host, port = bastion, 22
+	if h, p, splitErr := net.SplitHostPort(bastion); splitErr == nil {
+		host = h
+		if port, err = strconv.Atoi(p); err != nil || port <= 0 || port > 65535 {
+			return "", "", 0, fmt.Errorf("invalid port in bastion %q", bastion)
+		}
+	}
+	if host == "" {
+		return "", "", 0, fmt.Errorf("invalid bastion %q, expected [user@]host[:port]", bastion)
+	}
+
+	return user, host, port, nil
+}
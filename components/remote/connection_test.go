@@ -0,0 +1,98 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/test-infra-definitions/common/utils"
+)
+
+func TestParseBastion(t *testing.T) {
+	tests := []struct {
+		bastion string
+		user    string
+		host    string
+		port    int
+	}{
+		{"bastion.example.com", "", "bastion.example.com", 22},
+		{"ec2-user@10.0.0.1", "ec2-user", "10.0.0.1", 22},
+		{"ubuntu@bastion:2222", "ubuntu", "bastion", 2222},
+		{"[fd00::1]:2222", "", "fd00::1", 2222},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.bastion, func(t *testing.T) {
+			user, host, port, err := parseBastion(tt.bastion)
+			require.NoError(t, err)
+			assert.Equal(t, tt.user, user)
+			assert.Equal(t, tt.host, host)
+			assert.Equal(t, tt.port, port)
+		})
+	}
+
+	for _, bastion := range []string{"ubuntu@", "bastion:ssh", "bastion:0"} {
+		_, _, _, err := parseBastion(bastion)
+		assert.Error(t, err, bastion)
+	}
+}
+
+func TestProxyJumpChain(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	var calls [][]string
+	runSSH = func(args ...string) error {
+		calls = append(calls, args)
+		if args[len(args)-1] == "tunnel" {
+			return errors.New("no tunnel")
+		}
+		return nil
+	}
+	t.Cleanup(func() { runSSH = defaultRunSSH })
+
+	first := remote.ConnectionArgs{Host: pulumi.String("bastion.example.com"), User: pulumi.String("ec2-user"), PrivateKey: pulumi.String("first-key")}
+	second := remote.ConnectionArgs{Host: pulumi.String("10.0.0.2"), User: pulumi.String("ubuntu"), Port: pulumi.Float64(2222), PrivateKey: pulumi.String("second-key")}
+	conn, err := NewConnection(pulumi.String("10.0.1.1"), "root", WithProxyJump(first), WithProxyJump(second))
+	require.NoError(t, err)
+
+	proxies := make(chan *remote.ProxyConnection, 1)
+	conn.Proxy.ToProxyConnectionPtrOutput().ApplyT(func(proxy *remote.ProxyConnection) *remote.ProxyConnection {
+		proxies <- proxy
+		return proxy
+	})
+	proxy := <-proxies
+
+	// The command provider connects to the second hop through a local tunnel crossing the first one
+	assert.Equal(t, "127.0.0.1", proxy.Host)
+	require.NotNil(t, proxy.Port)
+	port := int(*proxy.Port)
+	assert.GreaterOrEqual(t, port, tunnelPortBase)
+	assert.Equal(t, "ubuntu", *proxy.User)
+	assert.Equal(t, "second-key", *proxy.PrivateKey)
+
+	require.Len(t, calls, 2)
+	tunnel := strings.Join(calls[1], " ")
+	assert.Contains(t, tunnel, fmt.Sprintf("-L 127.0.0.1:%d:10.0.0.2:2222", port))
+	assert.True(t, strings.HasSuffix(tunnel, "ec2-user@bastion.example.com"), tunnel)
+	assert.NotContains(t, tunnel, "ProxyCommand")
+}
+
+func TestProxyCommandNesting(t *testing.T) {
+	hops := []remote.ProxyConnection{
+		{Host: "10.0.0.1", User: utils.Pointer("first")},
+		{Host: "10.0.0.2", User: utils.Pointer("second")},
+		{Host: "10.0.0.3", User: utils.Pointer("third")},
+	}
+	args := hopArgs(hops, make([]string, len(hops)))
+
+	assert.Equal(t, "third@10.0.0.3", args[len(args)-1])
+	// The ProxyCommand of the second hop is expanded by the client of the third one, the nested one by the client of the second one
+	assert.Contains(t, args, "ProxyCommand=ssh -p 22 -o BatchMode=yes -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null "+
+		"-o 'ProxyCommand=ssh -p 22 -o BatchMode=yes -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null first@10.0.0.1 -W %%h:%%p' second@10.0.0.2 -W %h:%p")
+}
@@ -1,6 +1,7 @@
 package remote
 
 import (
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 
 	"github.com/DataDog/test-infra-definitions/common"
@@ -12,11 +13,14 @@ type connectionArgs struct {
 
 	// ==== Optional ====
 	privateKeyPath        string
-	privateKeyPassword    string
+	privateKey            pulumi.StringInput
+	privateKeyPassword    pulumi.StringInput
+	password              pulumi.StringInput
 	sshAgentPath          string
 	port                  int
 	dialErrorLimit        int
 	perDialTimeoutSeconds int
+	proxyJumps            []remote.ConnectionInput
 }
 
 type ConnectionOption = func(*connectionArgs) error
@@ -40,14 +44,40 @@ func WithPrivateKeyPath(path string) ConnectionOption {
 	}
 }
 
+// WithPrivateKey [optional] sets the content of the private key to use for the connection, it takes precedence over WithPrivateKeyPath
+func WithPrivateKey(privateKey pulumi.StringInput) ConnectionOption {
+	return func(args *connectionArgs) error {
+		args.privateKey = privateKey
+		return nil
+	}
+}
+
 // WithPrivateKeyPassword [optional] sets the password to use in case the private key is encrypted
 func WithPrivateKeyPassword(password string) ConnectionOption {
+	return func(args *connectionArgs) error {
+		if password != "" {
+			args.privateKeyPassword = pulumi.String(password)
+		}
+		return nil
+	}
+}
+
+// WithPrivateKeyPasswordSecret [optional] is WithPrivateKeyPassword for passwords read from secrets
+func WithPrivateKeyPasswordSecret(password pulumi.StringInput) ConnectionOption {
 	return func(args *connectionArgs) error {
 		args.privateKeyPassword = password
 		return nil
 	}
 }
 
+// WithPassword [optional] sets the password of the user, for hosts without key authentication
+func WithPassword(password pulumi.StringInput) ConnectionOption {
+	return func(args *connectionArgs) error {
+		args.password = password
+		return nil
+	}
+}
+
 // WithSSHAgentPath [optional] sets the path to the SSH Agent socket. Default to environment variable SSH_AUTH_SOCK if present.
 func WithSSHAgentPath(path string) ConnectionOption {
 	return func(args *connectionArgs) error {
@@ -83,3 +113,14 @@ func WithPerDialTimeoutSeconds(seconds int) ConnectionOption {
 		return nil
 	}
 }
+
+// WithProxyJump [optional] connects to the host through another host (bastion), like `ssh -J`.
+// The bastion connection is used as is, with its own user, key and agent settings. It can be set several times to chain
+// bastions, in the order they are crossed, and bastions can have a proxy themselves.
+// Chains of more than one hop go through a local tunnel started with the OpenSSH client, see proxyFromChain.
+func WithProxyJump(connection remote.ConnectionInput) ConnectionOption {
+	return func(args *connectionArgs) error {
+		args.proxyJumps = append(args.proxyJumps, connection)
+		return nil
+	}
+}
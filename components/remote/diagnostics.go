@@ -0,0 +1,34 @@
+package remote
+
+import (
+	"fmt"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/common/utils"
+	"github.com/DataDog/test-infra-definitions/components/command"
+	"github.com/DataDog/test-infra-definitions/components/diagnostics"
+	"github.com/DataDog/test-infra-definitions/components/os"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// NewDiagnosticsCollector fetches the bundle from the host before it is deleted or when `pulumi up` fails, see the diagnostics package.
+// It returns nil if diagnostics are disabled or if the host uses the WinRM transport.
+// Make it depend on the resources whose logs are collected, so that it runs before they are deleted.
+func (h *Host) NewDiagnosticsCollector(e config.Env, bundle diagnostics.Bundle, opts ...pulumi.ResourceOption) (pulumi.Resource, error) {
+	if enabled, err := diagnostics.Enabled(e); !enabled || err != nil {
+		return nil, err
+	}
+	// Bundles are fetched over SSH
+	if _, ok := h.OS.Runner().(*command.WinRMRunner); ok {
+		e.Ctx().Log.Warn(fmt.Sprintf("diagnostics are not collected from %s, they are not supported with the WinRM transport", h.Name()), nil)
+		return nil, nil
+	}
+
+	// The collector is not deleted with the host, it must run before the host is deleted
+	return diagnostics.NewCollector(e, h.Name()+"-"+string(bundle), diagnostics.Args{
+		Connection: h.connection,
+		Windows:    h.OS.Descriptor().Family() == os.WindowsFamily,
+		Bundle:     bundle,
+	}, utils.MergeOptions([]pulumi.ResourceOption{pulumi.Parent(h)}, opts...)...)
+}
@@ -0,0 +1,192 @@
+package remote
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/components/command"
+	"github.com/DataDog/test-infra-definitions/components/os"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// Modes of the host facts check, see config.DDInfraHostFactsCheck
+const (
+	HostFactsCheckDisabled = ""
+	HostFactsCheckWarn     = "warn"
+	HostFactsCheckFail     = "fail"
+)
+
+// Keys of the facts gathered on the host
+const (
+	FactOSID        = "os_id"
+	FactOSVersionID = "os_version_id"
+	FactOSName      = "os_name"
+	FactArch        = "arch"
+	FactKernel      = "kernel"
+	FactCPUs        = "cpus"
+	FactMemoryKB    = "memory_kb"
+	FactInitSystem  = "init_system"
+)
+
+const linuxFactsScript = `. /etc/os-release; ` +
+	`echo "os_id=$ID"; echo "os_version_id=$VERSION_ID"; echo "os_name=$PRETTY_NAME"; ` +
+	`echo "arch=$(uname -m)"; echo "kernel=$(uname -r)"; echo "cpus=$(getconf _NPROCESSORS_ONLN)"; ` +
+	`echo "memory_kb=$(awk '/^MemTotal:/ {print $2}' /proc/meminfo)"; echo "init_system=$(cat /proc/1/comm)"`
+
+const macOSFactsScript = `echo "os_id=macos"; echo "os_version_id=$(sw_vers -productVersion)"; echo "os_name=$(sw_vers -productName) $(sw_vers -productVersion)"; ` +
+	`echo "arch=$(uname -m)"; echo "kernel=$(uname -r)"; echo "cpus=$(sysctl -n hw.ncpu)"; ` +
+	`echo "memory_kb=$(( $(sysctl -n hw.memsize) / 1024 ))"; echo "init_system=launchd"`
+
+const windowsFactsScript = `$info = Get-ComputerInfo -Property OsName,OsVersion,OsArchitecture,CsNumberOfLogicalProcessors,CsTotalPhysicalMemory
+"os_id=windows"
+"os_version_id=$($info.OsVersion)"
+"os_name=$($info.OsName)"
+"arch=$($info.OsArchitecture)"
+"kernel=$($info.OsVersion)"
+"cpus=$($info.CsNumberOfLogicalProcessors)"
+"memory_kb=$([math]::Round($info.CsTotalPhysicalMemory / 1KB))"
+"init_system=scm"`
+
+// osIDFlavors maps the os_id fact, the ID field of /etc/os-release on Linux, to the flavors it may describe
+var osIDFlavors = map[string][]os.Flavor{
+	"ubuntu":        {os.Ubuntu},
+	"debian":        {os.Debian},
+	"amzn":          {os.AmazonLinux, os.AmazonLinuxECS},
+	"rhel":          {os.RedHat},
+	"sles":          {os.Suse},
+	"fedora":        {os.Fedora},
+	"centos":        {os.CentOS},
+	"rocky":         {os.RockyLinux},
+	"alpine":        {os.Alpine},
+	"almalinux":     {os.AlmaLinux},
+	"ol":            {os.OracleLinux},
+	"opensuse-leap": {os.OpenSuseLeap},
+	"arch":          {os.ArchLinux},
+	"macos":         {os.MacosOS},
+	"windows":       {os.WindowsServer, os.WindowsClient},
+}
+
+// gatherFacts runs a command collecting facts on the host and checks them against the descriptor according to mode
+func gatherFacts(e config.Env, runner command.Runner, osDesc os.Descriptor, mode string) (pulumi.StringMapOutput, error) {
+	if mode != HostFactsCheckWarn && mode != HostFactsCheckFail {
+		return pulumi.StringMapOutput{}, fmt.Errorf("invalid host facts check mode %q, expected %q or %q", mode, HostFactsCheckWarn, HostFactsCheckFail)
+	}
+
+	var script string
+	switch osDesc.Family() {
+	case os.LinuxFamily:
+		script = linuxFactsScript
+	case os.MacOSFamily:
+		script = macOSFactsScript
+	case os.WindowsFamily:
+		script = windowsFactsScript
+	case os.UnknownFamily:
+		fallthrough
+	default:
+		return pulumi.StringMapOutput{}, fmt.Errorf("cannot gather facts on OS family %v", osDesc.Family())
+	}
+
+	cmd, err := runner.Command("gather-facts", &command.Args{
+		Create: pulumi.String(script),
+	})
+	if err != nil {
+		return pulumi.StringMapOutput{}, err
+	}
+
+	return cmd.StdoutOutput().ApplyT(func(stdout string) (map[string]string, error) {
+		facts := parseFacts(stdout)
+		if err := checkFacts(osDesc, facts); err != nil {
+			if mode == HostFactsCheckFail {
+				return nil, err
+			}
+			e.Ctx().Log.Warn(err.Error(), nil)
+		}
+		return facts, nil
+	}).(pulumi.StringMapOutput), nil
+}
+
+// parseFacts parses the `key=value` lines printed by the facts scripts
+func parseFacts(stdout string) map[string]string {
+	facts := make(map[string]string)
+	for _, line := range strings.Split(stdout, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if !found {
+			continue
+		}
+		facts[key] = strings.TrimSpace(value)
+	}
+	return facts
+}
+
+// checkFacts returns an error listing the facts contradicting the descriptor flavor, version or architecture
+func checkFacts(osDesc os.Descriptor, facts map[string]string) error {
+	var mismatches []string
+
+	flavors, knownID := osIDFlavors[facts[FactOSID]]
+	if !knownID {
+		mismatches = append(mismatches, fmt.Sprintf("unknown OS %q", facts[FactOSID]))
+	} else if !flavorMatches(osDesc, flavors, facts[FactOSName]) {
+		mismatches = append(mismatches, fmt.Sprintf("flavor %s does not match OS %q", osDesc.Flavor, facts[FactOSName]))
+	}
+
+	if !versionMatches(osDesc, facts) {
+		mismatches = append(mismatches, fmt.Sprintf("version %s does not match OS %q (version %s)", osDesc.Version, facts[FactOSName], facts[FactOSVersionID]))
+	}
+
+	if arch := normalizeArch(facts[FactArch]); arch != osDesc.Architecture {
+		mismatches = append(mismatches, fmt.Sprintf("architecture %s does not match host architecture %q", osDesc.Architecture, facts[FactArch]))
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("host facts contradict OS descriptor %s: %s", osDesc, strings.Join(mismatches, ", "))
+	}
+	return nil
+}
+
+func flavorMatches(osDesc os.Descriptor, flavors []os.Flavor, osName string) bool {
+	switch osDesc.Flavor { // nolint:exhaustive
+	case os.WindowsServer:
+		return strings.Contains(osName, "Server")
+	case os.WindowsClient:
+		return strings.HasPrefix(osName, "Microsoft Windows") && !strings.Contains(osName, "Server")
+	}
+
+	for _, flavor := range flavors {
+		if flavor == osDesc.Flavor {
+			return true
+		}
+	}
+	return false
+}
+
+// versionMatches compares versions leniently as descriptors use various formats, for instance `22-04` for `22.04` or `79` for `7`.
+// Versions that cannot be compared are considered matching.
+func versionMatches(osDesc os.Descriptor, facts map[string]string) bool {
+	switch osDesc.Flavor { // nolint:exhaustive
+	case os.WindowsServer:
+		return strings.Contains(facts[FactOSName], osDesc.Version)
+	case os.WindowsClient, os.MacosOS:
+		return true
+	}
+
+	removeSeparators := strings.NewReplacer(".", "", "-", "")
+	descVersion := removeSeparators.Replace(osDesc.Version)
+	hostVersion := removeSeparators.Replace(facts[FactOSVersionID])
+	if descVersion == "" || hostVersion == "" {
+		return true
+	}
+	return strings.HasPrefix(descVersion, hostVersion) || strings.HasPrefix(hostVersion, descVersion)
+}
+
+func normalizeArch(arch string) os.Architecture {
+	switch {
+	case arch == "aarch64" || arch == "arm64" || strings.HasPrefix(arch, "ARM"):
+		return os.ARM64Arch
+	case arch == "x86_64" || arch == "amd64" || arch == "64-bit":
+		return os.AMD64Arch
+	default:
+		return os.Architecture(arch)
+	}
+}
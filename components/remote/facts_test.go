@@ -0,0 +1,63 @@
+package remote
+
+import (
+	"testing"
+
+	"github.com/DataDog/test-infra-definitions/components/os"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckFacts(t *testing.T) {
+	tests := []struct {
+		name    string
+		desc    os.Descriptor
+		stdout  string
+		wantErr bool
+	}{
+		{
+			name:   "ubuntu matches",
+			desc:   os.Ubuntu2204,
+			stdout: "os_id=ubuntu\nos_version_id=22.04\nos_name=Ubuntu 22.04.4 LTS\narch=x86_64\n",
+		},
+		{
+			name:   "centos short version matches",
+			desc:   os.CentOS7,
+			stdout: "os_id=centos\nos_version_id=7\narch=x86_64\n",
+		},
+		{
+			name:   "windows server matches",
+			desc:   os.WindowsServer2022,
+			stdout: "os_id=windows\r\nos_version_id=10.0.20348\r\nos_name=Microsoft Windows Server 2022 Datacenter\r\narch=64-bit\r\n",
+		},
+		{
+			name:    "wrong flavor",
+			desc:    os.Debian12,
+			stdout:  "os_id=ubuntu\nos_version_id=22.04\narch=x86_64\n",
+			wantErr: true,
+		},
+		{
+			name:    "wrong version",
+			desc:    os.Ubuntu2204,
+			stdout:  "os_id=ubuntu\nos_version_id=20.04\narch=x86_64\n",
+			wantErr: true,
+		},
+		{
+			name:    "wrong architecture",
+			desc:    os.AmazonLinux2023,
+			stdout:  "os_id=amzn\nos_version_id=2023\narch=aarch64\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkFacts(tt.desc, parseFacts(tt.stdout))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package remote
 
 import (
+	"fmt"
+
 	"github.com/DataDog/test-infra-definitions/common/config"
 	"github.com/DataDog/test-infra-definitions/components/command"
+	"github.com/DataDog/test-infra-definitions/components/diagnostics"
 	"github.com/DataDog/test-infra-definitions/components/os"
 
 	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
@@ -11,6 +14,11 @@ import (
 
 // InitHost initializes all fields of a Host component with the given connection and OS descriptor.
 func InitHost(e config.Env, conn remote.ConnectionOutput, osDesc os.Descriptor, osUser string, password pulumi.StringOutput, readyFunc command.ReadyFunc, host *Host) error {
+	conn, err := withStackBastion(e, conn)
+	if err != nil {
+		return err
+	}
+
 	// Determine OSCommand implementation
 	var osCommand command.OSCommand
 	if osDesc.Family() == os.WindowsFamily {
@@ -20,13 +28,22 @@ func InitHost(e config.Env, conn remote.ConnectionOutput, osDesc os.Descriptor,
 	}
 
 	// Now we can create the runner
-	runner, err := command.NewRemoteRunner(e, command.RemoteRunnerArgs{
-		ParentResource: host,
-		ConnectionName: host.Name(),
-		Connection:     conn,
-		ReadyFunc:      readyFunc,
-		OSCommand:      osCommand,
-	})
+	var runner command.Runner
+	if transport := e.InfraWindowsTransport(); osDesc.Family() == os.WindowsFamily && transport != WindowsTransportSSH {
+		// WinRM commands connect to the host directly
+		if e.InfraBastion() != "" {
+			return fmt.Errorf("the bastion %s cannot be used with the %s Windows transport", e.InfraBastion(), transport)
+		}
+		runner, err = newWinRMRunner(e, transport, conn, osUser, password, readyFunc, host)
+	} else {
+		runner, err = command.NewRemoteRunner(e, command.RemoteRunnerArgs{
+			ParentResource: host,
+			ConnectionName: host.Name(),
+			Connection:     conn,
+			ReadyFunc:      readyFunc,
+			OSCommand:      osCommand,
+		})
+	}
 	if err != nil {
 		return err
 	}
@@ -49,6 +66,57 @@ func InitHost(e config.Env, conn remote.ConnectionOutput, osDesc os.Descriptor,
 
 	// Set the OS for internal usage
 	host.OS = os.NewOS(e, osDesc, runner)
+	host.readyFunc = readyFunc
+	host.connection = conn
+
+	// Optionally verify that the host matches the descriptor
+	if mode := e.InfraHostFactsCheck(); mode != HostFactsCheckDisabled {
+		facts, err := gatherFacts(e, runner, osDesc, mode)
+		if err != nil {
+			return err
+		}
+		host.Facts = facts
+	}
+
+	_, err = host.NewDiagnosticsCollector(e, diagnostics.HostBundle)
+	return err
+}
+
+// Transports available to run commands on Windows hosts.
+// The WinRM transport requires the WinRM HTTPS listener to be enabled in the image and its port 5986 to be reachable,
+// they are not set up by the scenarios. Commands run the prebuilt client set with `winrmClientPath`.
+// WinRM over plain HTTP is not supported as the client does not implement NTLM message sealing.
+const (
+	WindowsTransportSSH        = "ssh"
+	WindowsTransportWinRMHTTPS = "winrm-https"
+)
+
+// newWinRMRunner creates a runner using WinRM instead of SSH, it authenticates with the password of the OS user.
+// The HTTPS listener certificate is verified unless `winrmInsecure` is set, test hosts usually use a self-signed one.
+func newWinRMRunner(e config.Env, transport string, conn remote.ConnectionOutput, osUser string, password pulumi.StringOutput, readyFunc command.ReadyFunc, host *Host) (command.Runner, error) {
+	if transport != WindowsTransportWinRMHTTPS {
+		return nil, fmt.Errorf("unsupported Windows transport %q, expected %s or %s", transport, WindowsTransportSSH, WindowsTransportWinRMHTTPS)
+	}
 
-	return nil
+	return command.NewWinRMRunner(e, command.WinRMRunnerArgs{
+		ParentResource: host,
+		ConnectionName: host.Name(),
+		Host:           winrmHost(conn),
+		HTTPS:          true,
+		Insecure:       e.InfraWinRMInsecure(),
+		ClientPath:     e.InfraWinRMClientPath(),
+		User:           osUser,
+		Password:       password,
+		ReadyFunc:      readyFunc,
+	})
+}
+
+// winrmHost returns the host of the connection, it fails if the connection has a proxy as WinRM commands connect to the host directly
+func winrmHost(conn remote.ConnectionOutput) pulumi.StringOutput {
+	return conn.ApplyT(func(c remote.Connection) (string, error) {
+		if c.Proxy != nil {
+			return "", fmt.Errorf("the proxy %s of %s cannot be used with WinRM", c.Proxy.Host, c.Host)
+		}
+		return c.Host, nil
+	}).(pulumi.StringOutput)
 }
@@ -0,0 +1,177 @@
+package remote
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/alessio/shellescape"
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+
+	"github.com/DataDog/test-infra-definitions/common/utils"
+)
+
+// Local ports of the tunnels reaching the last hop of proxy jump chains, derived from the chain
+const (
+	tunnelPortBase  = 20000
+	tunnelPortRange = 10000
+)
+
+// runSSH runs the OpenSSH client, it is replaced in tests
+var runSSH = defaultRunSSH
+
+func defaultRunSSH(args ...string) error {
+	output, err := exec.Command("ssh", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ssh %s failed: %w, output: %s", strings.Join(args, " "), err, output)
+	}
+	return nil
+}
+
+// proxyFromChain returns the proxy connection reaching the target through the chain of hops.
+// The command provider dials through a single proxy, longer chains go through a local OpenSSH tunnel forwarding
+// a local port to the last hop across the previous ones with nested ProxyCommands, like `ssh -J`.
+// The tunnel is started when the connection is resolved and is shared by the stacks using the same chain, it keeps
+// running in the background until it is stopped with `ssh -S <control socket> -O exit`.
+func proxyFromChain(chain []remote.ProxyConnection) (*remote.ProxyConnection, error) {
+	if len(chain) == 1 {
+		return &chain[0], nil
+	}
+
+	for _, hop := range chain[:len(chain)-1] {
+		if hop.Password != nil && hop.PrivateKey == nil {
+			return nil, fmt.Errorf("the proxy jump %s uses password authentication, which is not supported for chained proxy jumps", hop.Host)
+		}
+		if hop.PrivateKeyPassword != nil {
+			return nil, fmt.Errorf("the proxy jump %s uses an encrypted private key, which is not supported for chained proxy jumps", hop.Host)
+		}
+	}
+
+	last := chain[len(chain)-1]
+	jumps := chain[:len(chain)-1]
+	id := chainID(chain)
+	port := tunnelPortBase + int(binary.BigEndian.Uint16(id))%tunnelPortRange
+
+	dir := filepath.Join(os.TempDir(), "test-infra-ssh-"+hex.EncodeToString(id[:6]))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	controlSocket := filepath.Join(dir, "control")
+
+	// The tunnel of a previous run is reused
+	if runSSH("-S", controlSocket, "-O", "check", "tunnel") != nil {
+		keyFiles, err := writeHopKeys(dir, jumps)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			for _, keyFile := range keyFiles {
+				os.Remove(keyFile)
+			}
+		}()
+
+		args := []string{
+			"-f", "-N", "-M", "-S", controlSocket,
+			"-o", "ControlPersist=yes",
+			"-o", "ExitOnForwardFailure=yes",
+			"-o", "ServerAliveInterval=30",
+			"-L", fmt.Sprintf("127.0.0.1:%d:%s:%d", port, forwardHost(last.Host), hopPort(last)),
+		}
+		args = append(args, hopArgs(jumps, keyFiles)...)
+		if err := runSSH(args...); err != nil {
+			return nil, err
+		}
+	}
+
+	last.Host = "127.0.0.1"
+	last.Port = utils.Pointer(float64(port))
+	return &last, nil
+}
+
+// hopArgs returns the arguments of the ssh command connecting to the last hop, through the previous ones
+func hopArgs(hops []remote.ProxyConnection, keyFiles []string) []string {
+	hop := hops[len(hops)-1]
+	args := []string{
+		"-p", strconv.Itoa(hopPort(hop)),
+		"-o", "BatchMode=yes",
+		"-o", "StrictHostKeyChecking=no",
+		"-o", "UserKnownHostsFile=/dev/null",
+	}
+	if keyFiles[len(hops)-1] != "" {
+		args = append(args, "-i", keyFiles[len(hops)-1], "-o", "IdentitiesOnly=yes")
+	}
+	if hop.AgentSocketPath != nil {
+		args = append(args, "-o", "IdentityAgent="+*hop.AgentSocketPath)
+	}
+	if len(hops) > 1 {
+		args = append(args, "-o", "ProxyCommand="+proxyCommand(hops[:len(hops)-1], keyFiles))
+	}
+
+	user := "root"
+	if hop.User != nil {
+		user = *hop.User
+	}
+	return append(args, user+"@"+hop.Host)
+}
+
+// proxyCommand returns the ProxyCommand connecting to the host through the hops.
+// ProxyCommands are expanded by each ssh client, the `%` of the nested ones are escaped to be expanded by the right one.
+func proxyCommand(hops []remote.ProxyConnection, keyFiles []string) string {
+	args := hopArgs(hops, keyFiles)
+	quoted := make([]string, 0, len(args)+3)
+	quoted = append(quoted, "ssh")
+	for _, arg := range args {
+		quoted = append(quoted, shellescape.Quote(strings.ReplaceAll(arg, "%", "%%")))
+	}
+	return strings.Join(append(quoted, "-W", "%h:%p"), " ")
+}
+
+// writeHopKeys writes the private keys of the hops in the directory, the key file of hops without key is empty
+func writeHopKeys(dir string, hops []remote.ProxyConnection) ([]string, error) {
+	keyFiles := make([]string, len(hops))
+	for i, hop := range hops {
+		if hop.PrivateKey == nil {
+			continue
+		}
+		keyFiles[i] = filepath.Join(dir, fmt.Sprintf("hop-%d.key", i))
+		if err := os.WriteFile(keyFiles[i], []byte(*hop.PrivateKey), 0o600); err != nil {
+			return nil, err
+		}
+	}
+	return keyFiles, nil
+}
+
+// chainID identifies the chain by its hops
+func chainID(chain []remote.ProxyConnection) []byte {
+	hash := sha256.New()
+	for _, hop := range chain {
+		user := ""
+		if hop.User != nil {
+			user = *hop.User
+		}
+		fmt.Fprintf(hash, "%s@%s:%d\n", user, hop.Host, hopPort(hop))
+	}
+	return hash.Sum(nil)
+}
+
+func hopPort(hop remote.ProxyConnection) int {
+	if hop.Port == nil {
+		return 22
+	}
+	return int(*hop.Port)
+}
+
+// forwardHost encloses IPv6 addresses in brackets for the forwarding specification
+func forwardHost(host string) string {
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		return "[" + host + "]"
+	}
+	return host
+}
@@ -0,0 +1,132 @@
+package remote
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DataDog/test-infra-definitions/common/namer"
+	"github.com/DataDog/test-infra-definitions/common/utils"
+	"github.com/DataDog/test-infra-definitions/components/command"
+	"github.com/DataDog/test-infra-definitions/components/os"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	pulumitime "github.com/pulumiverse/pulumi-time/sdk/go/time"
+)
+
+// rebootTimeout is the time waited for the host to boot again once it went down
+const rebootTimeout = 10 * time.Minute
+
+// rebootShutdownDelay is waited after the reboot is scheduled, so that the host is going down before its boot identifier is polled.
+// It covers the delay of the scheduled reboot and the time for the host to stop accepting connections.
+const rebootShutdownDelay = 30 * time.Second
+
+// rebootPollInterval is the interval between two checks of the host state during a reboot
+const rebootPollInterval = 5 * time.Second
+
+type rebootCommands struct {
+	// bootID prints a value that changes at every boot
+	bootID string
+	// reboot schedules a reboot and returns immediately, so that the command does not fail when the connection is lost
+	reboot string
+	// waitRebooted polls until the boot identifier differs from the given one, it fails after the given number of attempts
+	waitRebooted string
+}
+
+var (
+	linuxRebootCommands = rebootCommands{
+		bootID:       "cat /proc/sys/kernel/random/boot_id",
+		reboot:       `nohup bash -c "sleep 2 && reboot" > /dev/null 2>&1 &`,
+		waitRebooted: unixWaitRebooted("cat /proc/sys/kernel/random/boot_id"),
+	}
+	macOSRebootCommands = rebootCommands{
+		bootID:       "sysctl -n kern.boottime",
+		reboot:       `nohup bash -c "sleep 2 && shutdown -r now" > /dev/null 2>&1 &`,
+		waitRebooted: unixWaitRebooted("sysctl -n kern.boottime"),
+	}
+	windowsRebootCommands = rebootCommands{
+		bootID: "(Get-CimInstance -ClassName Win32_OperatingSystem).LastBootUpTime.ToString('o')",
+		reboot: "shutdown.exe /r /f /t 5",
+		waitRebooted: fmt.Sprintf("$attempts = 0; while ((Get-CimInstance -ClassName Win32_OperatingSystem).LastBootUpTime.ToString('o') -eq '%%s') "+
+			"{ $attempts++; if ($attempts -ge %%d) { Write-Error 'the host did not reboot'; Exit 1 }; Start-Sleep -Seconds %d }", int(rebootPollInterval.Seconds())),
+	}
+)
+
+func unixWaitRebooted(bootID string) string {
+	return fmt.Sprintf(`attempts=0; while [ "$(%s)" = "%%s" ]; do attempts=$((attempts+1)); if [ $attempts -ge %%d ]; then echo "the host did not reboot" >&2; exit 1; fi; sleep %d; done`,
+		bootID, int(rebootPollInterval.Seconds()))
+}
+
+// Reboot reboots the host and waits for it to be ready again, running its ReadyFunc once it is back.
+// Once the host went down, the boot identifier is polled until it changes.
+// The returned command completes once the host is ready, commands that must run after the reboot should depend on it.
+func (h *Host) Reboot(name string, opts ...pulumi.ResourceOption) (command.Command, error) {
+	runner := h.OS.Runner()
+
+	var cmds rebootCommands
+	switch h.OS.Descriptor().Family() {
+	case os.LinuxFamily:
+		cmds = linuxRebootCommands
+	case os.MacOSFamily:
+		cmds = macOSRebootCommands
+	case os.WindowsFamily:
+		cmds = windowsRebootCommands
+	case os.UnknownFamily:
+		fallthrough
+	default:
+		return nil, fmt.Errorf("reboot is not supported on OS family %v", h.OS.Descriptor().Family())
+	}
+
+	e := runner.Environment()
+	// Command names are already prefixed by the runner
+	cmdNamer := namer.NewNamer(e.Ctx(), name)
+	resourceNamer := runner.Namer().WithPrefix(name)
+	unix := h.OS.Descriptor().Family() != os.WindowsFamily
+
+	bootID, err := runner.Command(cmdNamer.ResourceName("boot-id"), &command.Args{
+		Create: pulumi.String(cmds.bootID),
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	reboot, err := runner.Command(cmdNamer.ResourceName("reboot"), &command.Args{
+		Create: pulumi.String(cmds.reboot),
+		Sudo:   unix,
+	}, utils.MergeOptions(opts, utils.PulumiDependsOn(bootID))...)
+	if err != nil {
+		return nil, err
+	}
+
+	timeProvider, err := pulumitime.NewProvider(e.Ctx(), resourceNamer.ResourceName("time-provider"), &pulumitime.ProviderArgs{}, pulumi.Parent(h), pulumi.DeletedWith(h))
+	if err != nil {
+		return nil, err
+	}
+
+	waitDown, err := pulumitime.NewSleep(e.Ctx(), resourceNamer.ResourceName("wait-for-host-to-go-down"), &pulumitime.SleepArgs{
+		CreateDuration: pulumi.String(rebootShutdownDelay.String()),
+	}, pulumi.Provider(timeProvider), pulumi.Parent(h), pulumi.DeletedWith(h), utils.PulumiDependsOn(reboot))
+	if err != nil {
+		return nil, err
+	}
+
+	// Connection attempts are retried by the remote provider until the host is back
+	rebooted, err := runner.Command(cmdNamer.ResourceName("wait-rebooted"), &command.Args{
+		Create: bootID.StdoutOutput().ApplyT(func(id string) string {
+			return fmt.Sprintf(cmds.waitRebooted, strings.TrimSpace(id), int(rebootTimeout/rebootPollInterval))
+		}).(pulumi.StringOutput),
+	}, utils.MergeOptions(opts, utils.PulumiDependsOn(waitDown))...)
+	if err != nil {
+		return nil, err
+	}
+
+	_, readyCommand, err := command.WithReadyFunc(runner, name, h.readyFunc, rebooted)
+	if err != nil {
+		return nil, err
+	}
+	if readyCommand == nil {
+		return rebooted, nil
+	}
+
+	return readyCommand, nil
+}
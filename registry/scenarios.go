@@ -14,6 +14,7 @@ import (
 	"github.com/DataDog/test-infra-definitions/scenarios/aws/microVMs/microvms"
 	"github.com/DataDog/test-infra-definitions/scenarios/azure/aks"
 	computerun "github.com/DataDog/test-infra-definitions/scenarios/azure/compute/run"
+	byorun "github.com/DataDog/test-infra-definitions/scenarios/byo/run"
 	gcpcompute "github.com/DataDog/test-infra-definitions/scenarios/gcp/compute/run"
 	localpodmanrun "github.com/DataDog/test-infra-definitions/scenarios/local/podman/run"
 
@@ -37,6 +38,8 @@ func Scenarios() ScenarioRegistry {
 		"gcp/gke":         gke.Run,
 		"gcp/openshiftvm": openshiftvm.Run,
 		"localpodman/vm":  localpodmanrun.VMRun,
+		"byo/vm":          byorun.VMRun,
+		"byo/dockervm":    byorun.VMRunWithDocker,
 	}
 }
 
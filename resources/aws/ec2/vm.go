@@ -1,6 +1,8 @@
 package ec2
 
 import (
+	"fmt"
+
 	"github.com/DataDog/test-infra-definitions/common/config"
 	"github.com/DataDog/test-infra-definitions/common/utils"
 	"github.com/DataDog/test-infra-definitions/resources/aws"
@@ -9,6 +11,9 @@ import (
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
+// maxDataDisks is the number of device names recommended for EBS volumes, /dev/sd[f-p]
+const maxDataDisks = 11
+
 type InstanceArgs struct {
 	// Mandatory
 	AMI string
@@ -24,10 +29,14 @@ type InstanceArgs struct {
 	UserData           string
 	HTTPTokensRequired bool
 	HostID             pulumi.StringInput // For dedicated host tenancy
+	DataDiskSizes      []int              // Size in GiB of additional EBS volumes, deleted with the instance
 }
 
 func NewInstance(e aws.Environment, name string, args InstanceArgs, opts ...pulumi.ResourceOption) (*ec2.Instance, error) {
 	defaultInstanceArgs(e, &args)
+	if len(args.DataDiskSizes) > maxDataDisks {
+		return nil, fmt.Errorf("at most %d data disks can be attached, got %d", maxDataDisks, len(args.DataDiskSizes))
+	}
 
 	instanceArgs := &ec2.InstanceArgs{
 		Ami:                     pulumi.StringPtr(args.AMI),
@@ -49,6 +58,19 @@ func NewInstance(e aws.Environment, name string, args InstanceArgs, opts ...pulu
 		HostId:                            args.HostID,
 	}
 
+	if len(args.DataDiskSizes) > 0 {
+		ebsBlockDevices := make(ec2.InstanceEbsBlockDeviceArray, 0, len(args.DataDiskSizes))
+		for i, size := range args.DataDiskSizes {
+			ebsBlockDevices = append(ebsBlockDevices, ec2.InstanceEbsBlockDeviceArgs{
+				DeviceName:          pulumi.String(fmt.Sprintf("/dev/sd%c", 'f'+i)),
+				VolumeSize:          pulumi.Int(size),
+				VolumeType:          pulumi.String("gp3"),
+				DeleteOnTermination: pulumi.Bool(true),
+			})
+		}
+		instanceArgs.EbsBlockDevices = ebsBlockDevices
+	}
+
 	if args.HTTPTokensRequired {
 		instanceArgs.MetadataOptions = &ec2.InstanceMetadataOptionsArgs{
 			HttpTokens: pulumi.String("required"),
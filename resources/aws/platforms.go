@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	e2eos "github.com/DataDog/test-infra-definitions/components/os"
+
+	"github.com/samber/lo"
 )
 
 // Handles AMIs for all OSes
@@ -137,15 +139,33 @@ var platforms = PlatformsType{
 	},
 }
 
+// HasPinnedAMIs returns true if the platforms table contains AMIs for the given flavor
+func HasPinnedAMIs(flavor e2eos.Flavor) bool {
+	_, ok := platforms[flavor.String()]
+	return ok
+}
+
+// ValidateDescriptor returns an error suggesting close matches if the platforms table has no AMI for the descriptor.
+// Descriptors without version and flavors without pinned AMIs are not validated, they may use the latest AMI.
+func ValidateDescriptor(descriptor e2eos.Descriptor) error {
+	archs, ok := platforms[descriptor.Flavor.String()]
+	if !ok || descriptor.Version == "" {
+		return nil
+	}
+	versions, ok := archs[string(descriptor.Architecture)]
+	if !ok {
+		return fmt.Errorf("arch '%s' not found in platforms map for %s", descriptor.Architecture, descriptor.Flavor)
+	}
+
+	return e2eos.ValidateVersion(descriptor, lo.Keys(versions))
+}
+
 func GetAMI(descriptor *e2eos.Descriptor) (string, error) {
 	if _, ok := platforms[descriptor.Flavor.String()]; !ok {
-		return "", fmt.Errorf("os '%s' not found in platforms map", descriptor.Flavor.String())
-	}
-	if _, ok := platforms[descriptor.Flavor.String()][string(descriptor.Architecture)]; !ok {
-		return "", fmt.Errorf("arch '%s' not found in platforms map", descriptor.Architecture)
+		return "", fmt.Errorf("os '%s' not found in platforms map, pin its AMIs or explicitly use its latest AMI with ec2.WithLatestAMI or `ddinfra:osImageIDUseLatest`", descriptor.Flavor.String())
 	}
-	if _, ok := platforms[descriptor.Flavor.String()][string(descriptor.Architecture)][descriptor.Version]; !ok {
-		return "", fmt.Errorf("version '%s' not found in platforms map", descriptor.Version)
+	if err := ValidateDescriptor(*descriptor); err != nil {
+		return "", fmt.Errorf("platforms map: %w", err)
 	}
 
 	return platforms[descriptor.Flavor.String()][string(descriptor.Architecture)][descriptor.Version], nil
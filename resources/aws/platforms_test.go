@@ -0,0 +1,20 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	e2eos "github.com/DataDog/test-infra-definitions/components/os"
+)
+
+func TestValidateDescriptor(t *testing.T) {
+	assert.NoError(t, ValidateDescriptor(e2eos.Ubuntu2204))
+	assert.NoError(t, ValidateDescriptor(e2eos.NewDescriptor(e2eos.Ubuntu, "")))
+	assert.NoError(t, ValidateDescriptor(e2eos.AlmaLinux9))
+	redhat86, err := e2eos.ParseDescriptor("redhat:8.6", e2eos.UbuntuDefault)
+	assert.NoError(t, err)
+	assert.NoError(t, ValidateDescriptor(redhat86))
+	assert.ErrorContains(t, ValidateDescriptor(e2eos.NewDescriptor(e2eos.Ubuntu, "22-4")), "did you mean: 22-04")
+	assert.ErrorContains(t, ValidateDescriptor(e2eos.NewDescriptorWithArch(e2eos.RockyLinux, "92", e2eos.ARM64Arch)), "arch 'arm64' not found")
+}
@@ -24,7 +24,7 @@ const (
 	AdminUsername     = "azureuser"
 )
 
-func NewLinuxInstance(e azure.Environment, name, imageUrn, instanceType string, userData pulumi.StringPtrInput, opts ...pulumi.ResourceOption) (vm *compute.VirtualMachine, privateIP pulumi.StringOutput, err error) {
+func NewLinuxInstance(e azure.Environment, name, imageUrn, instanceType string, dataDiskSizes []int, userData pulumi.StringPtrInput, opts ...pulumi.ResourceOption) (vm *compute.VirtualMachine, privateIP pulumi.StringOutput, err error) {
 	sshPublicKey, err := utils.GetSSHPublicKey(e.DefaultPublicKeyPath())
 	if err != nil {
 		return nil, pulumi.StringOutput{}, err
@@ -47,7 +47,7 @@ func NewLinuxInstance(e azure.Environment, name, imageUrn, instanceType string,
 		CustomData: userData,
 	}
 
-	vm, networkInterface, err := newVMInstance(e, name, imageUrn, instanceType, linuxOsProfile, opts...)
+	vm, networkInterface, err := newVMInstance(e, name, imageUrn, instanceType, dataDiskSizes, linuxOsProfile, opts...)
 	if err != nil {
 		return nil, pulumi.StringOutput{}, err
 	}
@@ -57,7 +57,7 @@ func NewLinuxInstance(e azure.Environment, name, imageUrn, instanceType string,
 //go:embed setup-ssh-param.ps1
 var setupSSHParamScriptContent string
 
-func NewWindowsInstance(e azure.Environment, name, imageUrn, instanceType string, userData, firstLogonCommand pulumi.StringPtrInput, opts ...pulumi.ResourceOption) (vm *compute.VirtualMachine, privateIP pulumi.StringOutput, password pulumi.StringOutput, err error) {
+func NewWindowsInstance(e azure.Environment, name, imageUrn, instanceType string, dataDiskSizes []int, userData, firstLogonCommand pulumi.StringPtrInput, opts ...pulumi.ResourceOption) (vm *compute.VirtualMachine, privateIP pulumi.StringOutput, password pulumi.StringOutput, err error) {
 	pwdOpts := make([]pulumi.ResourceOption, 0, len(opts)+1)
 	copy(pwdOpts, opts)
 	pwdOpts = append(pwdOpts, e.WithProviders(config.ProviderRandom))
@@ -91,7 +91,7 @@ func NewWindowsInstance(e azure.Environment, name, imageUrn, instanceType string
 		}
 	}
 
-	vm, nw, err := newVMInstance(e, name, imageUrn, instanceType, windowsOsProfile, opts...)
+	vm, nw, err := newVMInstance(e, name, imageUrn, instanceType, dataDiskSizes, windowsOsProfile, opts...)
 	if err != nil {
 		return nil, pulumi.StringOutput{}, pulumi.StringOutput{}, err
 	}
@@ -130,7 +130,7 @@ func NewWindowsInstance(e azure.Environment, name, imageUrn, instanceType string
 	return vm, privateIP, windowsAdminPassword.Result, nil
 }
 
-func newVMInstance(e azure.Environment, name, imageUrn, instanceType string, osProfile compute.OSProfilePtrInput, opts ...pulumi.ResourceOption) (*compute.VirtualMachine, *network.NetworkInterface, error) {
+func newVMInstance(e azure.Environment, name, imageUrn, instanceType string, dataDiskSizes []int, osProfile compute.OSProfilePtrInput, opts ...pulumi.ResourceOption) (*compute.VirtualMachine, *network.NetworkInterface, error) {
 	vmImageRef, err := parseImageReferenceURN(imageUrn)
 	if err != nil {
 		return nil, nil, err
@@ -160,6 +160,20 @@ func newVMInstance(e azure.Environment, name, imageUrn, instanceType string, osP
 		return nil, nil, err
 	}
 
+	dataDisks := make(compute.DataDiskArray, 0, len(dataDiskSizes))
+	for i, size := range dataDiskSizes {
+		dataDisks = append(dataDisks, compute.DataDiskArgs{
+			Name:         e.Namer.DisplayName(math.MaxInt, pulumi.String(name), pulumi.Sprintf("data-disk-%d", i)),
+			Lun:          pulumi.Int(i),
+			CreateOption: pulumi.String(compute.DiskCreateOptionTypesEmpty),
+			DiskSizeGB:   pulumi.IntPtr(size),
+			ManagedDisk: compute.ManagedDiskParametersArgs{
+				StorageAccountType: pulumi.String("StandardSSD_LRS"),
+			},
+			DeleteOption: pulumi.String(compute.DiskDeleteOptionTypesDelete),
+		})
+	}
+
 	vmOpts := make([]pulumi.ResourceOption, 0, len(opts)+1)
 	copy(vmOpts, opts)
 	vmOpts = append(vmOpts, e.WithProviders(config.ProviderAzure))
@@ -180,6 +194,7 @@ func newVMInstance(e azure.Environment, name, imageUrn, instanceType string, osP
 				DiskSizeGB:   pulumi.IntPtr(200), // Windows requires at least 127GB
 			},
 			ImageReference: vmImageRef,
+			DataDisks:      dataDisks,
 		},
 		NetworkProfile: compute.NetworkProfileArgs{
 			NetworkInterfaces: compute.NetworkInterfaceReferenceArray{
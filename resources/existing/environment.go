@@ -0,0 +1,120 @@
+package existing
+
+import (
+	"errors"
+
+	config "github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/common/namer"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+const (
+	existingNamerNamespace = "byo"
+
+	// Existing host (bring your own)
+	DDInfraHostAddress            = "byo/host"
+	DDInfraHostPort               = "byo/port"
+	DDInfraHostUser               = "byo/user"
+	DDInfraHostPassword           = "byo/password"
+	DDInfraHostPrivateKeyPath     = "byo/privateKeyPath"
+	DDInfraHostPrivateKeyPassword = "byo/privateKeyPassword"
+)
+
+// Environment describes a host which already exists, for instance a lab machine, it is not provisioned nor destroyed.
+// The OS of the host is set with the common `osDescriptor` configuration.
+type Environment struct {
+	*config.CommonEnvironment
+
+	Namer namer.Namer
+}
+
+var _ config.Env = (*Environment)(nil)
+
+func NewEnvironment(ctx *pulumi.Context) (Environment, error) {
+	env := Environment{
+		Namer: namer.NewNamer(ctx, existingNamerNamespace),
+	}
+
+	commonEnv, err := config.NewCommonEnvironment(ctx)
+	if err != nil {
+		return Environment{}, err
+	}
+
+	env.CommonEnvironment = &commonEnv
+
+	if env.HostAddress() == "" {
+		return Environment{}, errors.New("the address of the host must be set with " + DDInfraHostAddress)
+	}
+	if env.HostUser() == "" {
+		return Environment{}, errors.New("the user of the host must be set with " + DDInfraHostUser)
+	}
+
+	return env, nil
+}
+
+// Cross Cloud Provider config
+
+// InternalRegistry returns the internal registry.
+func (e *Environment) InternalRegistry() string {
+	return "none"
+}
+
+// InternalDockerhubMirror returns the internal Dockerhub mirror.
+func (e *Environment) InternalDockerhubMirror() string {
+	return "registry-1.docker.io"
+}
+
+// InternalRegistryImageTagExists returns true if the image tag exists in the internal registry.
+func (e *Environment) InternalRegistryImageTagExists(_, _ string) (bool, error) {
+	return true, nil
+}
+
+// InternalRegistryFullImagePathExists returns true if the image and tag exists in the internal registry.
+func (e *Environment) InternalRegistryFullImagePathExists(_ string) (bool, error) {
+	return true, nil
+}
+
+// Host
+
+// HostAddress returns the IP address or DNS name of the host
+func (e *Environment) HostAddress() string {
+	return e.InfraConfig.Get(DDInfraHostAddress)
+}
+
+// HostPort returns the SSH port of the host, 22 by default
+func (e *Environment) HostPort() int {
+	return e.GetIntWithDefault(e.InfraConfig, DDInfraHostPort, 22)
+}
+
+// HostUser returns the user to connect with, it defaults to the common `sshUser` configuration
+func (e *Environment) HostUser() string {
+	return e.GetStringWithDefault(e.InfraConfig, DDInfraHostUser, e.InfraSSHUser())
+}
+
+// HostPassword returns the password of the user, it is mandatory for Windows hosts
+func (e *Environment) HostPassword() pulumi.StringOutput {
+	return e.InfraConfig.GetSecret(DDInfraHostPassword)
+}
+
+// HasHostPassword returns true if the password of the user is set, without reading it
+func (e *Environment) HasHostPassword() bool {
+	_, err := e.InfraConfig.TrySecret(DDInfraHostPassword)
+	return err == nil
+}
+
+// HostPrivateKeyPath returns the path to the private key to connect with, the SSH agent is used if empty
+func (e *Environment) HostPrivateKeyPath() string {
+	return e.InfraConfig.Get(DDInfraHostPrivateKeyPath)
+}
+
+// HostPrivateKeyPassword returns the password of the private key, if encrypted
+func (e *Environment) HostPrivateKeyPassword() pulumi.StringOutput {
+	return e.InfraConfig.GetSecret(DDInfraHostPrivateKeyPassword)
+}
+
+// HasHostPrivateKeyPassword returns true if the password of the private key is set, without reading it
+func (e *Environment) HasHostPrivateKeyPassword() bool {
+	_, err := e.InfraConfig.TrySecret(DDInfraHostPrivateKeyPassword)
+	return err == nil
+}
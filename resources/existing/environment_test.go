@@ -0,0 +1,83 @@
+package existing
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mocks struct{}
+
+func (mocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	return args.Name + "-id", args.Inputs, nil
+}
+
+func (mocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	return args.Args, nil
+}
+
+func TestNewEnvironment(t *testing.T) {
+	t.Run("the host should be read from the configuration", func(t *testing.T) {
+		t.Setenv("PULUMI_CONFIG", `{"ddinfra:byo/host": "10.0.0.1", "ddinfra:byo/port": "2222", "ddinfra:byo/user": "admin", "ddinfra:byo/password": "secret"}`)
+		t.Setenv("PULUMI_CONFIG_SECRET_KEYS", `["ddinfra:byo/password"]`)
+
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			env, err := NewEnvironment(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "10.0.0.1", env.HostAddress())
+			assert.Equal(t, 2222, env.HostPort())
+			assert.Equal(t, "admin", env.HostUser())
+			assert.True(t, env.HasHostPassword())
+			assert.Empty(t, env.HostPrivateKeyPath())
+			return nil
+		}, pulumi.WithMocks("project", "stack", mocks{}))
+		require.NoError(t, err)
+	})
+	t.Run("the password should be optional", func(t *testing.T) {
+		t.Setenv("PULUMI_CONFIG", `{"ddinfra:byo/host": "10.0.0.1", "ddinfra:byo/user": "admin", "ddinfra:byo/privateKeyPath": "/tmp/id_ed25519"}`)
+
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			env, err := NewEnvironment(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 22, env.HostPort())
+			assert.False(t, env.HasHostPassword())
+			assert.Equal(t, "/tmp/id_ed25519", env.HostPrivateKeyPath())
+			assert.False(t, env.HasHostPrivateKeyPassword())
+			return nil
+		}, pulumi.WithMocks("project", "stack", mocks{}))
+		require.NoError(t, err)
+	})
+	t.Run("the private key password should be a secret", func(t *testing.T) {
+		t.Setenv("PULUMI_CONFIG", `{"ddinfra:byo/host": "10.0.0.1", "ddinfra:byo/user": "admin", "ddinfra:byo/privateKeyPath": "/tmp/id_ed25519", "ddinfra:byo/privateKeyPassword": "passphrase"}`)
+		t.Setenv("PULUMI_CONFIG_SECRET_KEYS", `["ddinfra:byo/privateKeyPassword"]`)
+
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			env, err := NewEnvironment(ctx)
+			require.NoError(t, err)
+			assert.True(t, env.HasHostPrivateKeyPassword())
+
+			password := make(chan string, 1)
+			env.HostPrivateKeyPassword().ApplyT(func(p string) string {
+				password <- p
+				return p
+			})
+			assert.Equal(t, "passphrase", <-password)
+			assert.True(t, pulumi.IsSecret(env.HostPrivateKeyPassword()))
+			return nil
+		}, pulumi.WithMocks("project", "stack", mocks{}))
+		require.NoError(t, err)
+	})
+	t.Run("the host address should be mandatory", func(t *testing.T) {
+		t.Setenv("PULUMI_CONFIG", `{"ddinfra:byo/user": "admin"}`)
+
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			_, err := NewEnvironment(ctx)
+			assert.ErrorContains(t, err, DDInfraHostAddress)
+			return nil
+		}, pulumi.WithMocks("project", "stack", mocks{}))
+		require.NoError(t, err)
+	})
+}
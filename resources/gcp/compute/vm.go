@@ -1,6 +1,8 @@
 package compute
 
 import (
+	"fmt"
+
 	"github.com/DataDog/test-infra-definitions/common/config"
 	"github.com/DataDog/test-infra-definitions/common/utils"
 	"github.com/DataDog/test-infra-definitions/resources/gcp"
@@ -8,12 +10,28 @@ import (
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
-func NewLinuxInstance(e gcp.Environment, name string, imageName string, instanceType string, nestedVirt bool, opts ...pulumi.ResourceOption) (*compute.Instance, error) {
+func NewLinuxInstance(e gcp.Environment, name string, imageName string, instanceType string, nestedVirt bool, dataDiskSizes []int, opts ...pulumi.ResourceOption) (*compute.Instance, error) {
 
 	sshPublicKey, err := utils.GetSSHPublicKey(e.DefaultPublicKeyPath())
 	if err != nil {
 		return nil, err
 	}
+	attachedDisks := make(compute.InstanceAttachedDiskArray, 0, len(dataDiskSizes))
+	for i, size := range dataDiskSizes {
+		diskName := fmt.Sprintf("%s-data-%d", name, i)
+		disk, err := compute.NewDisk(e.Ctx(), e.Namer.ResourceName(diskName), &compute.DiskArgs{
+			Name: e.Namer.DisplayName(63, pulumi.String(diskName)),
+			Size: pulumi.Int(size),
+			Type: pulumi.String("pd-balanced"),
+		}, utils.MergeOptions(opts, e.WithProviders(config.ProviderGCP))...)
+		if err != nil {
+			return nil, err
+		}
+		attachedDisks = append(attachedDisks, &compute.InstanceAttachedDiskArgs{
+			Source: disk.SelfLink,
+		})
+	}
+
 	instance, err := compute.NewInstance(e.Ctx(), e.Namer.ResourceName(name), &compute.InstanceArgs{
 		NetworkInterfaces: compute.InstanceNetworkInterfaceArray{
 			&compute.InstanceNetworkInterfaceArgs{
@@ -44,6 +62,7 @@ func NewLinuxInstance(e gcp.Environment, name string, imageName string, instance
 				Size: pulumi.Int(100),
 			},
 		},
+		AttachedDisks: attachedDisks,
 		Metadata: pulumi.StringMap{
 			"enable-oslogin": pulumi.String("false"),
 			"ssh-keys":       pulumi.Sprintf("gce:%s", sshPublicKey),
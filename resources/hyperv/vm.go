@@ -12,6 +12,8 @@ import (
 
 type VMArgs struct {
 	Name string
+	// ReadyFuncs are run after the connection is established and before any other command
+	ReadyFuncs []command.ReadyFunc
 	// Attributes you need when you will actually create the VM
 }
 
@@ -44,6 +46,6 @@ func NewVM(e Environment, args VMArgs, opts ...pulumi.ResourceOption) (*remote.H
 			return err
 		}
 
-		return remote.InitHost(&e, conn.ToConnectionOutput(), os.WindowsServer2022, "<SSH_USER_NAME>", pulumi.String("").ToStringOutput(), command.WaitForSuccessfulConnection, comp)
+		return remote.InitHost(&e, conn.ToConnectionOutput(), os.WindowsServer2022, "<SSH_USER_NAME>", pulumi.String("").ToStringOutput(), command.ExtendReadyFunc(command.WaitForSuccessfulConnection, args.ReadyFuncs...), comp)
 	})
 }
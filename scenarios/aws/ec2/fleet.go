@@ -0,0 +1,49 @@
+package ec2
+
+import (
+	"fmt"
+
+	"github.com/DataDog/test-infra-definitions/components/datadog/agent"
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams"
+	fakeintakeComp "github.com/DataDog/test-infra-definitions/components/datadog/fakeintake"
+	"github.com/DataDog/test-infra-definitions/resources/aws"
+	"github.com/DataDog/test-infra-definitions/scenarios/aws/fakeintake"
+)
+
+// NewHostAgentFleet creates a fleet of VMs named `<name>-<index>` running the Agent, all sending to the same fakeintake.
+// The fakeintake is nil when the fleet is created WithoutFleetFakeintake.
+func NewHostAgentFleet(e aws.Environment, name string, options ...FleetOption) (*agent.HostAgentFleet, *fakeintakeComp.Fakeintake, error) {
+	args, err := buildFleetArgs(options...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	agentOptions := args.agentOptions
+	var fleetFakeintake *fakeintakeComp.Fakeintake
+	if !args.withoutFakeintake {
+		fleetFakeintake, err = fakeintake.NewECSFargateInstance(e, name, args.fakeintakeOptions...)
+		if err != nil {
+			return nil, nil, err
+		}
+		agentOptions = append([]agentparams.Option{agentparams.WithFakeintake(fleetFakeintake)}, agentOptions...)
+	}
+
+	hosts := make([]agent.FleetHost, 0, args.count)
+	for i := 0; i < args.count; i++ {
+		vmOptions := append([]VMOption{}, args.vmOptions...)
+		if args.osDescriptors != nil {
+			vmOptions = append(vmOptions, WithOS(args.osDescriptors[i]))
+		}
+		vm, err := NewVM(e, fmt.Sprintf("%s-%d", name, i), vmOptions...)
+		if err != nil {
+			return nil, nil, err
+		}
+		hosts = append(hosts, agent.FleetHost{Host: vm, Options: args.hostAgentOptions[i]})
+	}
+
+	fleet, err := agent.NewHostAgentFleet(&e, name, hosts, agentOptions...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fleet, fleetFakeintake, nil
+}
@@ -0,0 +1,115 @@
+package ec2
+
+import (
+	"fmt"
+
+	"github.com/DataDog/test-infra-definitions/common"
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams"
+	"github.com/DataDog/test-infra-definitions/components/os"
+	"github.com/DataDog/test-infra-definitions/scenarios/aws/fakeintake"
+)
+
+// FleetParams defines the parameters for a fleet of virtual machines running the Agent.
+// The FleetParams configuration uses the [Functional options pattern].
+//
+// The available options are:
+//   - [WithFleetOSes]
+//   - [WithFleetCount]
+//   - [WithFleetVMOptions]
+//   - [WithFleetAgentOptions]
+//   - [WithFleetHostAgentOptions]
+//   - [WithFleetFakeintakeOptions]
+//   - [WithoutFleetFakeintake]
+//
+// [Functional options pattern]: https://dave.cheney.net/2014/10/17/functional-options-for-friendly-apis
+
+type fleetArgs struct {
+	osDescriptors     []os.Descriptor
+	count             int
+	vmOptions         []VMOption
+	agentOptions      []agentparams.Option
+	hostAgentOptions  map[int][]agentparams.Option
+	fakeintakeOptions []fakeintake.Option
+	withoutFakeintake bool
+}
+
+type FleetOption = func(*fleetArgs) error
+
+func buildFleetArgs(options ...FleetOption) (*fleetArgs, error) {
+	args, err := common.ApplyOption(&fleetArgs{hostAgentOptions: map[int][]agentparams.Option{}}, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if args.osDescriptors != nil && args.count != 0 && args.count != len(args.osDescriptors) {
+		return nil, fmt.Errorf("the fleet has %d OS descriptors but a count of %d", len(args.osDescriptors), args.count)
+	}
+	if args.count == 0 {
+		args.count = len(args.osDescriptors)
+	}
+	if args.count == 0 {
+		return nil, fmt.Errorf("the fleet needs OS descriptors or a count")
+	}
+	for index := range args.hostAgentOptions {
+		if index < 0 || index >= args.count {
+			return nil, fmt.Errorf("agent options are set for host %d but the fleet has %d hosts", index, args.count)
+		}
+	}
+	return args, nil
+}
+
+// WithFleetOSes creates one VM for each OS descriptor
+func WithFleetOSes(descriptors ...os.Descriptor) FleetOption {
+	return func(p *fleetArgs) error {
+		p.osDescriptors = descriptors
+		return nil
+	}
+}
+
+// WithFleetCount creates count VMs with the OS of the VM options, the default one if not set
+func WithFleetCount(count int) FleetOption {
+	return func(p *fleetArgs) error {
+		p.count = count
+		return nil
+	}
+}
+
+// WithFleetVMOptions sets the options shared by all the VMs, the OS set by WithFleetOSes overrides them
+func WithFleetVMOptions(options ...VMOption) FleetOption {
+	return func(p *fleetArgs) error {
+		p.vmOptions = append(p.vmOptions, options...)
+		return nil
+	}
+}
+
+// WithFleetAgentOptions sets the options shared by all the Agents
+func WithFleetAgentOptions(options ...agentparams.Option) FleetOption {
+	return func(p *fleetArgs) error {
+		p.agentOptions = append(p.agentOptions, options...)
+		return nil
+	}
+}
+
+// WithFleetHostAgentOptions sets the options of the Agent of the host at index, applied after the shared ones, for instance to set tags or the hostname
+func WithFleetHostAgentOptions(index int, options ...agentparams.Option) FleetOption {
+	return func(p *fleetArgs) error {
+		p.hostAgentOptions[index] = append(p.hostAgentOptions[index], options...)
+		return nil
+	}
+}
+
+// WithFleetFakeintakeOptions sets the options of the fakeintake shared by the Agents
+func WithFleetFakeintakeOptions(options ...fakeintake.Option) FleetOption {
+	return func(p *fleetArgs) error {
+		p.fakeintakeOptions = append(p.fakeintakeOptions, options...)
+		return nil
+	}
+}
+
+// WithoutFleetFakeintake does not create a fakeintake, the Agents use the intake set by their options
+func WithoutFleetFakeintake() FleetOption {
+	return func(p *fleetArgs) error {
+		p.withoutFakeintake = true
+		return nil
+	}
+}
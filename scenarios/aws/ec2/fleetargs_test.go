@@ -0,0 +1,29 @@
+package ec2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams"
+	"github.com/DataDog/test-infra-definitions/components/os"
+)
+
+func TestBuildFleetArgs(t *testing.T) {
+	args, err := buildFleetArgs(WithFleetOSes(os.UbuntuDefault, os.AmazonLinux2), WithFleetHostAgentOptions(1, agentparams.WithHostname("collision")))
+	require.NoError(t, err)
+	assert.Equal(t, 2, args.count)
+	assert.Len(t, args.hostAgentOptions[1], 1)
+
+	args, err = buildFleetArgs(WithFleetCount(3))
+	require.NoError(t, err)
+	assert.Equal(t, 3, args.count)
+
+	_, err = buildFleetArgs()
+	assert.Error(t, err)
+	_, err = buildFleetArgs(WithFleetOSes(os.UbuntuDefault), WithFleetCount(2))
+	assert.Error(t, err)
+	_, err = buildFleetArgs(WithFleetCount(2), WithFleetHostAgentOptions(2, agentparams.WithHostname("out-of-range")))
+	assert.Error(t, err)
+}
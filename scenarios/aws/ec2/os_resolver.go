@@ -3,6 +3,7 @@ package ec2
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/DataDog/test-infra-definitions/components/os"
@@ -28,6 +29,11 @@ var defaultUsers = map[os.Flavor]string{
 	os.CentOS:         "centos",
 	os.RockyLinux:     "cloud-user",
 	os.MacosOS:        "ec2-user",
+	os.Alpine:         "alpine",
+	os.AlmaLinux:      "ec2-user",
+	os.OracleLinux:    "ec2-user",
+	os.OpenSuseLeap:   "ec2-user",
+	os.ArchLinux:      "arch",
 }
 
 type amiResolverFunc func(aws.Environment, *os.Descriptor) (string, error)
@@ -44,6 +50,11 @@ var amiResolvers = map[os.Flavor]amiResolverFunc{
 	os.CentOS:         resolveCentOSAMI,
 	os.RockyLinux:     resolveRockyLinuxAMI,
 	os.MacosOS:        resolveMacosAMI,
+	os.Alpine:         resolveAlpineAMI,
+	os.AlmaLinux:      resolveAlmaLinuxAMI,
+	os.OracleLinux:    resolveOracleLinuxAMI,
+	os.OpenSuseLeap:   resolveOpenSuseLeapAMI,
+	os.ArchLinux:      resolveArchLinuxAMI,
 }
 
 // Returns the default version for the given flavor
@@ -61,6 +72,20 @@ func getDefaultVersion(flavor os.Flavor) (string, error) {
 	return "", fmt.Errorf("no default version found for flavor %s, this flavor should be added to the default descriptors", flavor)
 }
 
+// ParseOSDescriptor parses the OS descriptor of the stack configuration, defaultDescriptor is used if it is not set.
+// Unless an AMI is set in the configuration, the version is validated against the pinned AMIs of the flavor.
+func ParseOSDescriptor(e aws.Environment, defaultDescriptor os.Descriptor) (os.Descriptor, error) {
+	osDesc, err := os.ParseDescriptor(e.InfraOSDescriptor(), defaultDescriptor)
+	if err != nil {
+		return os.Descriptor{}, err
+	}
+	if e.InfraOSImageID() != "" {
+		return osDesc, nil
+	}
+
+	return osDesc, aws.ValidateDescriptor(osDesc)
+}
+
 // resolveOS returns the AMI ID for the given OS.
 // Note that you may get this error in some cases:
 // OptInRequired: In order to use this AWS Marketplace product you need to accept terms and subscribe
@@ -70,7 +95,7 @@ func resolveOS(e aws.Environment, vmArgs *vmArgs) (*amiInformation, error) {
 		var err error
 
 		// If no AMI set and latest AMI is requested, resolve the AMI
-		if vmArgs.osInfo.Version == "" && vmArgs.useLatestAMI {
+		if resolvesLatestAMI(vmArgs) {
 			vmArgs.ami, err = amiResolvers[vmArgs.osInfo.Flavor](e, vmArgs.osInfo)
 			if err != nil {
 				return nil, err
@@ -112,6 +137,12 @@ func resolveOS(e aws.Environment, vmArgs *vmArgs) (*amiInformation, error) {
 	return amiInfo, nil
 }
 
+// resolvesLatestAMI returns whether the latest AMI is used instead of the one pinned in the platforms table.
+// Flavors without pinned AMIs are only resolved to their latest AMI when it is explicitly requested, they fail otherwise.
+func resolvesLatestAMI(vmArgs *vmArgs) bool {
+	return vmArgs.useLatestAMI && (vmArgs.osInfo.Version == "" || !aws.HasPinnedAMIs(vmArgs.osInfo.Flavor))
+}
+
 func warnOSNotUsingLatestAMI(e aws.Environment, osInfo *os.Descriptor) {
 	e.Ctx().Log.Warn(fmt.Sprintf("%s is not using the latest AMI but a hardcoded one", osInfo.Flavor.String()), nil)
 }
@@ -179,7 +210,9 @@ func resolveUbuntuAMI(e aws.Environment, osInfo *os.Descriptor) (string, error)
 		volumeType = "ebs-gp3"
 	}
 
-	return ec2.GetAMIFromSSM(e, fmt.Sprintf("/aws/service/canonical/ubuntu/server/%s/stable/current/%s/hvm/%s/ami-id", osInfo.Version, paramArch, volumeType))
+	// Canonical parameters use dots instead of the dashes of parsed descriptors, for instance 22.04
+	ssmVersion := strings.ReplaceAll(osInfo.Version, "-", ".")
+	return ec2.GetAMIFromSSM(e, fmt.Sprintf("/aws/service/canonical/ubuntu/server/%s/stable/current/%s/hvm/%s/ami-id", ssmVersion, paramArch, volumeType))
 }
 
 func resolveDebianAMI(e aws.Environment, osInfo *os.Descriptor) (string, error) {
@@ -211,7 +244,9 @@ func resolveSuseAMI(e aws.Environment, osInfo *os.Descriptor) (string, error) {
 		osInfo.Version = os.SuseDefault.Version
 	}
 
-	if osInfo.Version == "15-4" {
+	// Descriptors built in code may spell the service pack, only parsed ones are normalized
+	version := os.NormalizeVersion(osInfo.Flavor, osInfo.Version)
+	if version == "15-4" {
 		warnOSNotUsingLatestAMI(e, osInfo)
 		if osInfo.Architecture == os.AMD64Arch {
 			return "ami-067dfda331f8296b0", nil // Private copy of the AMI dd-agent-sles-15-x86_64
@@ -221,7 +256,9 @@ func resolveSuseAMI(e aws.Environment, osInfo *os.Descriptor) (string, error) {
 		return "", fmt.Errorf("architecture %s is not supported for SUSE %s", osInfo.Architecture, osInfo.Version)
 	}
 
-	return ec2.GetAMIFromSSM(e, fmt.Sprintf("/aws/service/suse/sles/%s/%s/latest", osInfo.Version, osInfo.Architecture))
+	// SUSE parameters spell the service pack, for instance 15-sp5
+	ssmVersion := strings.Replace(version, "-", "-sp", 1)
+	return ec2.GetAMIFromSSM(e, fmt.Sprintf("/aws/service/suse/sles/%s/%s/latest", ssmVersion, osInfo.Architecture))
 }
 
 func resolveFedoraAMI(e aws.Environment, osInfo *os.Descriptor) (string, error) {
@@ -284,3 +321,45 @@ func resolveMacosAMI(e aws.Environment, osInfo *os.Descriptor) (string, error) {
 
 	return ec2.GetAMIFromSSM(e, fmt.Sprintf("/aws/service/ec2-macos/%s/%s_mac/latest/image_id", osInfo.Version, osInfo.Architecture))
 }
+
+func resolveAlpineAMI(e aws.Environment, osInfo *os.Descriptor) (string, error) {
+	if osInfo.Version == "" {
+		osInfo.Version = os.AlpineDefault.Version
+	}
+
+	// Official Alpine Linux cloud images, see https://alpinelinux.org/cloud/
+	return ec2.SearchAMI(e, "538276064493", fmt.Sprintf("alpine-%s.*-*-uefi-cloudinit-*", strings.ReplaceAll(osInfo.Version, "-", ".")), string(osInfo.Architecture))
+}
+
+func resolveAlmaLinuxAMI(e aws.Environment, osInfo *os.Descriptor) (string, error) {
+	if osInfo.Version == "" {
+		osInfo.Version = os.AlmaLinuxDefault.Version
+	}
+
+	return ec2.SearchAMI(e, "764336703387", fmt.Sprintf("AlmaLinux OS %s*", osInfo.Version), string(osInfo.Architecture))
+}
+
+func resolveOracleLinuxAMI(e aws.Environment, osInfo *os.Descriptor) (string, error) {
+	if osInfo.Version == "" {
+		osInfo.Version = os.OracleLinuxDefault.Version
+	}
+
+	return ec2.SearchAMI(e, "131827586825", fmt.Sprintf("OL%s*-HVM-*", osInfo.Version), string(osInfo.Architecture))
+}
+
+func resolveOpenSuseLeapAMI(e aws.Environment, osInfo *os.Descriptor) (string, error) {
+	if osInfo.Version == "" {
+		osInfo.Version = os.OpenSuseLeapDefault.Version
+	}
+
+	return ec2.SearchAMI(e, "679593333241", fmt.Sprintf("openSUSE-Leap-%s-*-hvm-ssd-*", osInfo.Version), string(osInfo.Architecture))
+}
+
+func resolveArchLinuxAMI(e aws.Environment, osInfo *os.Descriptor) (string, error) {
+	if osInfo.Architecture == os.ARM64Arch {
+		return "", errors.New("ARM64 is not supported for Arch Linux")
+	}
+
+	// Arch Linux is a rolling release, only the latest image is available
+	return ec2.SearchAMI(e, "647457786197", "arch-linux-std-hvm-*", string(osInfo.Architecture))
+}
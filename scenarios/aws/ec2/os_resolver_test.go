@@ -0,0 +1,31 @@
+package ec2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/test-infra-definitions/common/utils"
+	"github.com/DataDog/test-infra-definitions/components/os"
+)
+
+func TestResolvesLatestAMI(t *testing.T) {
+	tests := []struct {
+		name         string
+		osInfo       os.Descriptor
+		useLatestAMI bool
+		want         bool
+	}{
+		{"pinned flavor uses the pinned AMI", os.Ubuntu2204, false, false},
+		{"pinned flavor without version uses the latest AMI on request", os.NewDescriptor(os.Ubuntu, ""), true, true},
+		{"pinned flavor with version keeps the pinned AMI", os.Ubuntu2204, true, false},
+		{"flavor without pins does not fall back to the latest AMI", os.AlmaLinux9, false, false},
+		{"flavor without pins uses the latest AMI on request", os.AlmaLinux9, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resolvesLatestAMI(&vmArgs{osInfo: utils.Pointer(tt.osInfo), useLatestAMI: tt.useLatestAMI}))
+		})
+	}
+}
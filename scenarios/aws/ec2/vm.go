@@ -53,6 +53,7 @@ func NewVM(e aws.Environment, name string, params ...VMOption) (*remote.Host, er
 			HTTPTokensRequired: vmArgs.httpTokensRequired,
 			Tenancy:            vmArgs.tenancy,
 			HostID:             pulumi.String(vmArgs.hostID),
+			DataDiskSizes:      os.DiskSizes(vmArgs.dataDisks),
 		}
 
 		if vmArgs.osInfo.Family() == os.MacOSFamily && vmArgs.hostID == "" {
@@ -87,12 +88,16 @@ func NewVM(e aws.Environment, name string, params ...VMOption) (*remote.Host, er
 			return err
 		}
 
-		err = remote.InitHost(&e, conn.ToConnectionOutput(), *vmArgs.osInfo, sshUser, pulumi.String("").ToStringOutput(), amiInfo.readyFunc, c)
+		err = remote.InitHost(&e, conn.ToConnectionOutput(), *vmArgs.osInfo, sshUser, pulumi.String("").ToStringOutput(), command.ExtendReadyFunc(amiInfo.readyFunc, vmArgs.readyFuncs...), c)
 
 		if err != nil {
 			return err
 		}
 
+		if _, err = os.MountDisks(c.OS, vmArgs.dataDisks, opts...); err != nil {
+			return err
+		}
+
 		// reset the windows password on Windows
 		if vmArgs.osInfo.Family() == os.WindowsFamily {
 			// The password contains characters from three of the following categories:
@@ -191,6 +196,8 @@ func defaultVMArgs(e aws.Environment, vmArgs *vmArgs) error {
 		defaultUserData = os.APTDisableUnattendedUpgradesScriptContent
 	} else if vmArgs.osInfo.Flavor == os.Suse {
 		defaultUserData = os.ZypperDisableUnattendedUpgradesScriptContent
+	} else if vmArgs.osInfo.Flavor == os.Alpine {
+		defaultUserData = os.APKInstallBashSudoScriptContent
 	}
 	userDataParts := make([]string, 0, 3)
 	if vmArgs.userData != "" {
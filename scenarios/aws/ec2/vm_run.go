@@ -23,7 +23,10 @@ func VMRun(ctx *pulumi.Context) error {
 		return err
 	}
 
-	osDesc := os.DescriptorFromString(env.InfraOSDescriptor(), os.AmazonLinuxECSDefault)
+	osDesc, err := ParseOSDescriptor(env, os.AmazonLinuxECSDefault)
+	if err != nil {
+		return err
+	}
 	args := []VMOption{WithAMI(env.InfraOSImageID(), osDesc, osDesc.Architecture)}
 	if env.InfraOSImageIDUseLatest() {
 		args = append(args, WithLatestAMI())
@@ -99,7 +102,10 @@ func VMRunWithDocker(ctx *pulumi.Context) error {
 	}
 
 	// If no OS is provided, we default to AmazonLinuxECS as it ships with Docker pre-installed
-	osDesc := os.DescriptorFromString(env.InfraOSDescriptor(), os.AmazonLinuxECSDefault)
+	osDesc, err := ParseOSDescriptor(env, os.AmazonLinuxECSDefault)
+	if err != nil {
+		return err
+	}
 	vm, err := NewVM(env, "vm", WithAMI(env.InfraOSImageID(), osDesc, osDesc.Architecture))
 	if err != nil {
 		return err
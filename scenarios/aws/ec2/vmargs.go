@@ -3,6 +3,7 @@ package ec2
 import (
 	"github.com/DataDog/test-infra-definitions/common"
 	"github.com/DataDog/test-infra-definitions/common/utils"
+	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/DataDog/test-infra-definitions/components/os"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
@@ -20,6 +21,8 @@ import (
 //   - [WithName]
 //   - [WithHostID]
 //   - [WithTenancy]
+//   - [WithDataDisk]
+//   - [WithReadyFunc]
 //   - [WithPulumiResourceOptions]
 //
 // [Functional options pattern]: https://dave.cheney.net/2014/10/17/functional-options-for-friendly-apis
@@ -33,6 +36,8 @@ type vmArgs struct {
 	instanceProfile string
 	tenancy         string
 	hostID          string
+	readyFuncs      []command.ReadyFunc
+	dataDisks       []os.Disk
 
 	httpTokensRequired    bool
 	pulumiResourceOptions []pulumi.ResourceOption
@@ -124,6 +129,24 @@ func WithTenancy(tenancy string) VMOption {
 	}
 }
 
+// WithDataDisk attaches an additional EBS volume of disk.SizeGiB, it is formatted and mounted if disk.MountPoint is set.
+// Can be called multiple times to attach several disks.
+func WithDataDisk(disk os.Disk) VMOption {
+	return func(p *vmArgs) error {
+		p.dataDisks = append(p.dataDisks, disk)
+		return nil
+	}
+}
+
+// WithReadyFunc adds a check waiting for the host to be ready, run after the default one and before any other command.
+// Can be called multiple times, see [command.WaitFor] to build one from composable ready checks.
+func WithReadyFunc(readyFunc command.ReadyFunc) VMOption {
+	return func(p *vmArgs) error {
+		p.readyFuncs = append(p.readyFuncs, readyFunc)
+		return nil
+	}
+}
+
 func WithPulumiResourceOptions(options ...pulumi.ResourceOption) VMOption {
 	return func(p *vmArgs) error {
 		p.pulumiResourceOptions = options
@@ -0,0 +1,184 @@
+package installer
+
+import (
+	"fmt"
+	stdos "os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/DataDog/test-infra-definitions/common/config"
+	"github.com/DataDog/test-infra-definitions/components/os"
+	"github.com/DataDog/test-infra-definitions/resources/aws"
+
+	sdkconfig "github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
+)
+
+const (
+	labConfigNamespace = "ddinstallerlab"
+	// labConfigFileParamName is the path of a YAML or JSON file containing a LabConfig
+	labConfigFileParamName = "configFile"
+	// labConfigParamName is a LabConfig object in the stack configuration, used without config file
+	labConfigParamName = "lab"
+
+	defaultInstallScriptURL        = "https://s3.amazonaws.com/dd-agent/scripts/install_script_agent7.sh"
+	defaultWindowsInstallScriptURL = "https://install.datadoghq.com/Install-Datadog.ps1"
+)
+
+// LabConfig describes the VMs of the installer lab and how the Agent is installed on them, unset fields use the default lab
+type LabConfig struct {
+	// InstallScriptURL is the install script run on the Linux VMs
+	InstallScriptURL string `json:"installScriptURL" yaml:"installScriptURL"`
+	// WindowsInstallScriptURL is the install script run on the Windows VMs
+	WindowsInstallScriptURL string `json:"windowsInstallScriptURL" yaml:"windowsInstallScriptURL"`
+	// InstallEnv are the environment variables of the install scripts, for instance DD_REMOTE_UPDATES
+	InstallEnv map[string]string `json:"installEnv" yaml:"installEnv"`
+	VMs        []LabVMConfig     `json:"vms" yaml:"vms"`
+}
+
+// LabVMConfig describes a VM of the installer lab
+type LabVMConfig struct {
+	Name string `json:"name" yaml:"name"`
+	// OS is a descriptor in the format <flavor>:<version>(:<arch>), for instance `ubuntu:22.04:arm64` or `windows-server:2022`
+	OS string `json:"os" yaml:"os"`
+	// InstanceType is the EC2 instance type, the default one of the OS if empty
+	InstanceType string `json:"instanceType" yaml:"instanceType"`
+	// ExtraPackages are installed before the Agent, on Linux only
+	ExtraPackages []string `json:"extraPackages" yaml:"extraPackages"`
+}
+
+type installerLabVMArgs struct {
+	name              string
+	descriptor        os.Descriptor
+	instanceType      string
+	extraPackageNames []string
+}
+
+var installerLabVMs = []installerLabVMArgs{
+	{
+		name:              "ubuntu-22",
+		descriptor:        os.NewDescriptorWithArch(os.Ubuntu, "22-04", os.ARM64Arch),
+		instanceType:      "t4g.medium",
+		extraPackageNames: []string{},
+	},
+	{
+		name:              "ubuntu-20",
+		descriptor:        os.NewDescriptorWithArch(os.Ubuntu, "20-04", os.ARM64Arch),
+		instanceType:      "t4g.medium",
+		extraPackageNames: []string{},
+	},
+	{
+		name:              "debian-12",
+		descriptor:        os.NewDescriptorWithArch(os.Debian, "12", os.ARM64Arch),
+		instanceType:      "t4g.medium",
+		extraPackageNames: []string{},
+	},
+	{
+		name:              "debian-12-small",
+		descriptor:        os.NewDescriptorWithArch(os.Debian, "12", os.ARM64Arch),
+		instanceType:      "t4g.small",
+		extraPackageNames: []string{},
+	},
+	{
+		name:              "suse-15",
+		descriptor:        os.NewDescriptorWithArch(os.Suse, "15-4", os.ARM64Arch),
+		instanceType:      "t4g.medium",
+		extraPackageNames: []string{},
+	},
+}
+
+// labArgs is the resolved LabConfig
+type labArgs struct {
+	installScriptURL        string
+	windowsInstallScriptURL string
+	installEnv              map[string]string
+	vms                     []installerLabVMArgs
+}
+
+// loadLabConfig reads the LabConfig from the config file if any, from the stack configuration otherwise
+func loadLabConfig(e config.Env) (LabConfig, error) {
+	labConfig := sdkconfig.New(e.Ctx(), labConfigNamespace)
+	var cfg LabConfig
+
+	if configFile := labConfig.Get(labConfigFileParamName); configFile != "" {
+		content, err := stdos.ReadFile(configFile)
+		if err != nil {
+			return LabConfig{}, fmt.Errorf("failed to read the installer lab config file: %w", err)
+		}
+		if err := yaml.Unmarshal(content, &cfg); err != nil {
+			return LabConfig{}, fmt.Errorf("failed to parse the installer lab config file %s: %w", configFile, err)
+		}
+		return cfg, nil
+	}
+
+	e.GetObjectWithDefault(labConfig, labConfigParamName, &cfg, LabConfig{})
+	return cfg, nil
+}
+
+// buildLabArgs resolves the LabConfig, using the default lab for unset fields
+// envVarNameRegexp matches the names of the environment variables which can be set in both shell and PowerShell scripts
+var envVarNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func buildLabArgs(cfg LabConfig) (*labArgs, error) {
+	args := &labArgs{
+		installScriptURL:        cfg.InstallScriptURL,
+		windowsInstallScriptURL: cfg.WindowsInstallScriptURL,
+		installEnv:              cfg.InstallEnv,
+		vms:                     installerLabVMs,
+	}
+	if args.installScriptURL == "" {
+		args.installScriptURL = defaultInstallScriptURL
+	}
+	if args.windowsInstallScriptURL == "" {
+		args.windowsInstallScriptURL = defaultWindowsInstallScriptURL
+	}
+	if args.installEnv == nil {
+		args.installEnv = map[string]string{"DD_REMOTE_UPDATES": "true"}
+	}
+	// Names are written as is in the install scripts, only values are quoted
+	for name := range args.installEnv {
+		if !envVarNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid name %q of the install environment variable, it must match %s", name, envVarNameRegexp)
+		}
+	}
+	if len(cfg.VMs) == 0 {
+		return args, nil
+	}
+
+	args.vms = make([]installerLabVMArgs, 0, len(cfg.VMs))
+	names := make(map[string]bool, len(cfg.VMs))
+	for _, vm := range cfg.VMs {
+		if vm.Name == "" {
+			return nil, fmt.Errorf("the installer lab VM with OS %q has no name", vm.OS)
+		}
+		if names[vm.Name] {
+			return nil, fmt.Errorf("the installer lab VM %s is defined twice", vm.Name)
+		}
+		names[vm.Name] = true
+
+		descriptor, err := os.ParseDescriptor(vm.OS, os.Descriptor{})
+		if err == nil {
+			err = aws.ValidateDescriptor(descriptor)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid OS of the installer lab VM %s: %w", vm.Name, err)
+		}
+		switch descriptor.Family() {
+		case os.LinuxFamily:
+		case os.WindowsFamily:
+			if len(vm.ExtraPackages) > 0 {
+				return nil, fmt.Errorf("extra packages are not supported on the Windows installer lab VM %s", vm.Name)
+			}
+		default:
+			return nil, fmt.Errorf("the installer lab VM %s must run Linux or Windows, got %q", vm.Name, vm.OS)
+		}
+
+		args.vms = append(args.vms, installerLabVMArgs{
+			name:              vm.Name,
+			descriptor:        descriptor,
+			instanceType:      vm.InstanceType,
+			extraPackageNames: vm.ExtraPackages,
+		})
+	}
+	return args, nil
+}
@@ -0,0 +1,96 @@
+package installer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/DataDog/test-infra-definitions/components/os"
+)
+
+func TestBuildLabArgs(t *testing.T) {
+	t.Run("the default lab should be used without config", func(t *testing.T) {
+		args, err := buildLabArgs(LabConfig{})
+		require.NoError(t, err)
+		assert.Equal(t, installerLabVMs, args.vms)
+		assert.Equal(t, defaultInstallScriptURL, args.installScriptURL)
+		assert.Equal(t, map[string]string{"DD_REMOTE_UPDATES": "true"}, args.installEnv)
+	})
+	t.Run("the lab should be read from the config", func(t *testing.T) {
+		var cfg LabConfig
+		require.NoError(t, yaml.Unmarshal([]byte(`
+installScriptURL: https://example.com/install.sh
+installEnv:
+  DD_REMOTE_UPDATES: "false"
+vms:
+  - name: ubuntu-22-x86
+    os: ubuntu:22.04:x86_64
+    instanceType: t3.medium
+    extraPackages: [curl]
+  - name: windows-2022
+    os: windows-server:2022
+`), &cfg))
+
+		args, err := buildLabArgs(cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/install.sh", args.installScriptURL)
+		assert.Equal(t, defaultWindowsInstallScriptURL, args.windowsInstallScriptURL)
+		assert.Equal(t, map[string]string{"DD_REMOTE_UPDATES": "false"}, args.installEnv)
+		require.Len(t, args.vms, 2)
+		assert.Equal(t, "ubuntu-22-x86", args.vms[0].name)
+		assert.Equal(t, os.Ubuntu, args.vms[0].descriptor.Flavor)
+		assert.Equal(t, os.AMD64Arch, args.vms[0].descriptor.Architecture)
+		assert.Equal(t, "t3.medium", args.vms[0].instanceType)
+		assert.Equal(t, []string{"curl"}, args.vms[0].extraPackageNames)
+		assert.Equal(t, os.WindowsFamily, args.vms[1].descriptor.Family())
+	})
+	t.Run("the install environment variables should have valid names", func(t *testing.T) {
+		for _, name := range []string{"", "1DD_ENV", "DD-ENV", "DD_ENV=x", "X;touch /tmp/pwned;Y", "DD ENV"} {
+			_, err := buildLabArgs(LabConfig{InstallEnv: map[string]string{name: "value"}})
+			assert.ErrorContains(t, err, "invalid name", name)
+		}
+		_, err := buildLabArgs(LabConfig{InstallEnv: map[string]string{"_DD_ENV1": "value"}})
+		assert.NoError(t, err)
+	})
+	t.Run("the VMs should have unique names", func(t *testing.T) {
+		_, err := buildLabArgs(LabConfig{VMs: []LabVMConfig{{Name: "debian", OS: "debian:12"}, {Name: "debian", OS: "debian:11"}}})
+		assert.Error(t, err)
+	})
+	t.Run("the VMs should have a valid OS", func(t *testing.T) {
+		_, err := buildLabArgs(LabConfig{VMs: []LabVMConfig{{Name: "unknown", OS: "plan9:4"}}})
+		assert.Error(t, err)
+		_, err = buildLabArgs(LabConfig{VMs: []LabVMConfig{{Name: "macos", OS: "macos:sonoma"}}})
+		assert.Error(t, err)
+	})
+	t.Run("the VMs should have a pinned AMI", func(t *testing.T) {
+		_, err := buildLabArgs(LabConfig{VMs: []LabVMConfig{{Name: "debian", OS: "debian:13"}}})
+		assert.ErrorContains(t, err, `version "13" is not supported for debian`)
+	})
+	t.Run("the Windows VMs should not have extra packages", func(t *testing.T) {
+		_, err := buildLabArgs(LabConfig{VMs: []LabVMConfig{{Name: "windows", OS: "windows-server:2022", ExtraPackages: []string{"curl"}}}})
+		assert.Error(t, err)
+	})
+}
+
+func TestInstallScriptFormat(t *testing.T) {
+	t.Run("the Linux install script should pass the environment variables", func(t *testing.T) {
+		format := linuxInstallScriptFormat(defaultInstallScriptURL, map[string]string{"DD_REMOTE_UPDATES": "true", "DD_ENV": "lab 100%"})
+		assert.Equal(t, `#!/bin/bash
+DD_API_KEY=key DD_HOSTNAME=installer-lab-debian DD_SITE=datadoghq.com DD_ENV='lab 100%' DD_REMOTE_UPDATES=true bash -c "$(curl -L https://s3.amazonaws.com/dd-agent/scripts/install_script_agent7.sh)"
+`, fmt.Sprintf(format, "key", "installer-lab-debian", "datadoghq.com"))
+	})
+	t.Run("the Windows install script should pass the environment variables", func(t *testing.T) {
+		format := windowsInstallScriptFormat(defaultWindowsInstallScriptURL, map[string]string{"DD_REMOTE_UPDATES": "true"})
+		assert.Equal(t, `$env:DD_API_KEY='key'
+$env:DD_HOSTNAME='installer-lab-windows'
+$env:DD_SITE='datadoghq.com'
+$env:DD_REMOTE_UPDATES='true'
+Set-ExecutionPolicy Bypass -Scope Process -Force
+[System.Net.ServicePointManager]::SecurityProtocol = [System.Net.ServicePointManager]::SecurityProtocol -bor 3072
+iex ((New-Object System.Net.WebClient).DownloadString('https://install.datadoghq.com/Install-Datadog.ps1'))
+`, fmt.Sprintf(format, "key", "installer-lab-windows", "datadoghq.com"))
+	})
+}
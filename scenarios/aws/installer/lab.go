@@ -2,8 +2,13 @@ package installer
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alessio/shellescape"
 
 	"github.com/DataDog/test-infra-definitions/common/namer"
+	"github.com/DataDog/test-infra-definitions/common/utils"
 	"github.com/DataDog/test-infra-definitions/components"
 	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/DataDog/test-infra-definitions/components/os"
@@ -13,50 +18,6 @@ import (
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
-type installerLabVMArgs struct {
-	name              string
-	descriptor        os.Descriptor
-	instanceType      string
-	extraPackageNames []string
-}
-
-var installerLabVMs = []installerLabVMArgs{
-	{
-		name:              "ubuntu-22",
-		descriptor:        os.NewDescriptorWithArch(os.Ubuntu, "22.04", os.ARM64Arch),
-		instanceType:      "t4g.medium",
-		extraPackageNames: []string{},
-	},
-	{
-		name:              "ubuntu-20",
-		descriptor:        os.NewDescriptorWithArch(os.Ubuntu, "20.04", os.ARM64Arch),
-		instanceType:      "t4g.medium",
-		extraPackageNames: []string{},
-	},
-	{
-		name:              "debian-12",
-		descriptor:        os.NewDescriptorWithArch(os.Debian, "12", os.ARM64Arch),
-		instanceType:      "t4g.medium",
-		extraPackageNames: []string{},
-	},
-	{
-		name:              "debian-12-small",
-		descriptor:        os.NewDescriptorWithArch(os.Debian, "12", os.ARM64Arch),
-		instanceType:      "t4g.small",
-		extraPackageNames: []string{},
-	},
-	{
-		name:              "suse-15",
-		descriptor:        os.NewDescriptorWithArch(os.Suse, "15-sp4", os.ARM64Arch),
-		instanceType:      "t4g.medium",
-		extraPackageNames: []string{},
-	},
-}
-
-const installScriptFormat = `#!/bin/bash
-DD_API_KEY=%s DD_HOSTNAME=%s DD_SITE=%s DD_REMOTE_UPDATES=true bash -c "$(curl -L https://s3.amazonaws.com/dd-agent/scripts/install_script_agent7.sh)"
-`
-
 const hostnamePrefix = "installer-lab-%s"
 
 type LabHost struct {
@@ -73,13 +34,22 @@ func Run(ctx *pulumi.Context) error {
 		return err
 	}
 
-	for _, vmArgs := range installerLabVMs {
-		vm, err := ec2.NewVM(
-			env,
-			vmArgs.name,
-			ec2.WithInstanceType(vmArgs.instanceType),
-			ec2.WithOSArch(vmArgs.descriptor, vmArgs.descriptor.Architecture),
-		)
+	cfg, err := loadLabConfig(&env)
+	if err != nil {
+		return err
+	}
+	lab, err := buildLabArgs(cfg)
+	if err != nil {
+		return err
+	}
+
+	hostnames := pulumi.StringMap{}
+	for _, vmArgs := range lab.vms {
+		vmOptions := []ec2.VMOption{ec2.WithOSArch(vmArgs.descriptor, vmArgs.descriptor.Architecture)}
+		if vmArgs.instanceType != "" {
+			vmOptions = append(vmOptions, ec2.WithInstanceType(vmArgs.instanceType))
+		}
+		vm, err := ec2.NewVM(env, vmArgs.name, vmOptions...)
 		if err != nil {
 			return err
 		}
@@ -87,33 +57,85 @@ func Run(ctx *pulumi.Context) error {
 			return err
 		}
 
+		hostname := fmt.Sprintf(hostnamePrefix, vmArgs.name)
 		_, err = components.NewComponent(&env, vm.Name(), func(comp *LabHost) error {
 			comp.namer = env.CommonNamer().WithPrefix(comp.Name())
 			comp.host = vm
 
-			err := comp.installManagedAgent(env.AgentAPIKey(), fmt.Sprintf(hostnamePrefix, vmArgs.name), env.Site())
-
-			return err
+			return comp.installManagedAgent(lab, vmArgs, env.AgentAPIKey(), hostname, env.Site())
 		})
 		if err != nil {
 			return err
 		}
+		hostnames[vmArgs.name] = pulumi.String(hostname)
 	}
+	ctx.Export("hostnames", hostnames)
 
 	return nil
 }
 
 func (h *LabHost) installManagedAgent(
-	apiKey pulumi.StringOutput, hostname string, site string,
+	lab *labArgs, vmArgs installerLabVMArgs, apiKey pulumi.StringOutput, hostname string, site string,
 ) error {
-	installScript := pulumi.Sprintf(installScriptFormat, apiKey, hostname, site)
+	var opts []pulumi.ResourceOption
+	for _, packageName := range vmArgs.extraPackageNames {
+		packageCmd, err := h.host.OS.PackageManager().Ensure(packageName, nil, "")
+		if err != nil {
+			return err
+		}
+		opts = append(opts, utils.PulumiDependsOn(packageCmd))
+	}
+
+	installScript := pulumi.Sprintf(linuxInstallScriptFormat(lab.installScriptURL, lab.installEnv), apiKey, hostname, site)
+	if vmArgs.descriptor.Family() == os.WindowsFamily {
+		installScript = pulumi.Sprintf(windowsInstallScriptFormat(lab.windowsInstallScriptURL, lab.installEnv), apiKey, hostname, site)
+	}
 
 	_, err := h.host.OS.Runner().Command(
 		h.namer.ResourceName("install-script"),
 		&command.Args{
 			Create: installScript,
 		},
+		opts...,
 	)
 
 	return err
 }
+
+// sortedEnv returns the environment variables sorted by name, so that the install scripts do not change between runs
+func sortedEnv(env map[string]string) []string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// linuxInstallScriptFormat returns the format of the Linux install script, taking the API key, the hostname and the site
+func linuxInstallScriptFormat(scriptURL string, env map[string]string) string {
+	vars := []string{"DD_API_KEY=%[1]s", "DD_HOSTNAME=%[2]s", "DD_SITE=%[3]s"}
+	for _, name := range sortedEnv(env) {
+		vars = append(vars, name+"="+strings.ReplaceAll(shellescape.Quote(env[name]), "%", "%%"))
+	}
+	return fmt.Sprintf(`#!/bin/bash
+%s bash -c "$(curl -L %s)"
+`, strings.Join(vars, " "), strings.ReplaceAll(shellescape.Quote(scriptURL), "%", "%%"))
+}
+
+// windowsInstallScriptFormat returns the format of the Windows install script, taking the API key, the hostname and the site
+func windowsInstallScriptFormat(scriptURL string, env map[string]string) string {
+	vars := []string{"$env:DD_API_KEY='%[1]s'", "$env:DD_HOSTNAME='%[2]s'", "$env:DD_SITE='%[3]s'"}
+	for _, name := range sortedEnv(env) {
+		vars = append(vars, fmt.Sprintf("$env:%s=%s", name, powershellQuote(env[name])))
+	}
+	return fmt.Sprintf(`%s
+Set-ExecutionPolicy Bypass -Scope Process -Force
+[System.Net.ServicePointManager]::SecurityProtocol = [System.Net.ServicePointManager]::SecurityProtocol -bor 3072
+iex ((New-Object System.Net.WebClient).DownloadString(%s))
+`, strings.Join(vars, "\n"), powershellQuote(scriptURL))
+}
+
+func powershellQuote(value string) string {
+	return strings.ReplaceAll("'"+strings.ReplaceAll(value, "'", "''")+"'", "%", "%%")
+}
@@ -36,7 +36,10 @@ func Run(ctx *pulumi.Context) error {
 		return err
 	}
 
-	osDesc := os.DescriptorFromString(awsEnv.InfraOSDescriptor(), os.AmazonLinuxECSDefault)
+	osDesc, err := ec2.ParseOSDescriptor(awsEnv, os.AmazonLinuxECSDefault)
+	if err != nil {
+		return err
+	}
 	vm, err := ec2.NewVM(awsEnv, "kind", ec2.WithOS(osDesc))
 	if err != nil {
 		return err
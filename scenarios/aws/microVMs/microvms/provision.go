@@ -240,12 +240,23 @@ func provisionRemoteMicroVMs(vmCollections []*VMCollection, instanceEnv *Instanc
 					return nil, err
 				}
 
-				pc := createProxyConnection(domain.ip, "root", microVMSSHKey, conn.ToConnectionOutput())
+				pc, err := remoteComp.NewConnection(
+					domain.ip,
+					"root",
+					remoteComp.WithPrivateKey(microVMSSHKey),
+					remoteComp.WithDialErrorLimit(60),
+					remoteComp.WithPerDialTimeoutSeconds(5),
+					remoteComp.WithProxyJump(conn),
+				)
+				if err != nil {
+					return nil, err
+				}
+
 				remoteRunner, err := command.NewRemoteRunner(
 					collection.instance.e,
 					command.RemoteRunnerArgs{
 						ParentResource: domain.lvDomain,
-						Connection:     pc,
+						Connection:     pc.ToConnectionOutput(),
 						ConnectionName: collection.instance.instanceNamer.ResourceName("conn", domain.domainID),
 						OSCommand:      command.NewUnixOSCommand(),
 					},
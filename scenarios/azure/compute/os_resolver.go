@@ -77,6 +77,6 @@ func resolveUbuntuURN(_ azure.Environment, osInfo os.Descriptor) (string, error)
 	case os.Ubuntu2204.Version:
 		return "canonical:0001-com-ubuntu-server-jammy:22_04-lts-gen2:latest", nil
 	default:
-		return "", fmt.Errorf("unsupported Ubuntu version %s", osInfo.Version)
+		return "", os.ValidateVersion(osInfo, []string{os.Ubuntu2204.Version})
 	}
 }
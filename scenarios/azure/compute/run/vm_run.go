@@ -17,7 +17,10 @@ func VMRun(ctx *pulumi.Context) error {
 		return err
 	}
 
-	osDesc := os.DescriptorFromString(env.InfraOSDescriptor(), os.UbuntuDefault)
+	osDesc, err := os.ParseDescriptor(env.InfraOSDescriptor(), os.UbuntuDefault)
+	if err != nil {
+		return err
+	}
 	vm, err := compute.NewVM(env, "vm", compute.WithImageURN(env.InfraOSImageID(), osDesc, osDesc.Architecture))
 	if err != nil {
 		return err
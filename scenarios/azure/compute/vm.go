@@ -41,13 +41,13 @@ func NewVM(e azure.Environment, name string, params ...VMOption) (*remote.Host,
 		var password pulumi.StringOutput
 
 		if vmArgs.osInfo.Family() == os.LinuxFamily {
-			_, privateIP, err = compute.NewLinuxInstance(e, c.Name(), imageInfo.urn, vmArgs.instanceType, pulumi.StringPtr(vmArgs.userData), pulumi.Parent(c))
+			_, privateIP, err = compute.NewLinuxInstance(e, c.Name(), imageInfo.urn, vmArgs.instanceType, os.DiskSizes(vmArgs.dataDisks), pulumi.StringPtr(vmArgs.userData), pulumi.Parent(c))
 			if err != nil {
 				return err
 			}
 			password = pulumi.String("").ToStringOutput()
 		} else if vmArgs.osInfo.Family() == os.WindowsFamily {
-			_, privateIP, password, err = compute.NewWindowsInstance(e, c.Name(), imageInfo.urn, vmArgs.instanceType, pulumi.StringPtr(vmArgs.userData), nil, pulumi.Parent(c))
+			_, privateIP, password, err = compute.NewWindowsInstance(e, c.Name(), imageInfo.urn, vmArgs.instanceType, os.DiskSizes(vmArgs.dataDisks), pulumi.StringPtr(vmArgs.userData), nil, pulumi.Parent(c))
 			if err != nil {
 				return err
 			}
@@ -71,7 +71,12 @@ func NewVM(e azure.Environment, name string, params ...VMOption) (*remote.Host,
 		}
 
 		// TODO: Check support of cloud-init on Azure
-		return remote.InitHost(&e, connection.ToConnectionOutput(), *vmArgs.osInfo, compute.AdminUsername, password, command.WaitForSuccessfulConnection, c)
+		if err = remote.InitHost(&e, connection.ToConnectionOutput(), *vmArgs.osInfo, compute.AdminUsername, password, command.ExtendReadyFunc(command.WaitForSuccessfulConnection, vmArgs.readyFuncs...), c); err != nil {
+			return err
+		}
+
+		_, err = os.MountDisks(c.OS, vmArgs.dataDisks, pulumi.Parent(c))
+		return err
 	}, vmArgs.pulumiResourceOptions...)
 }
 
@@ -3,6 +3,7 @@ package compute
 import (
 	"github.com/DataDog/test-infra-definitions/common"
 	"github.com/DataDog/test-infra-definitions/common/utils"
+	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/DataDog/test-infra-definitions/components/os"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
@@ -17,6 +18,8 @@ import (
 //   - [WithInstanceType]
 //   - [WithUserData]
 //   - [WithName]
+//   - [WithDataDisk]
+//   - [WithReadyFunc]
 //
 // [Functional options pattern]: https://dave.cheney.net/2014/10/17/functional-options-for-friendly-apis
 
@@ -25,6 +28,8 @@ type vmArgs struct {
 	imageURN              string
 	userData              string
 	instanceType          string
+	readyFuncs            []command.ReadyFunc
+	dataDisks             []os.Disk
 	pulumiResourceOptions []pulumi.ResourceOption
 }
 
@@ -75,6 +80,24 @@ func WithUserData(userData string) VMOption {
 	}
 }
 
+// WithDataDisk attaches an additional managed disk of disk.SizeGiB, it is formatted and mounted if disk.MountPoint is set.
+// Can be called multiple times to attach several disks.
+func WithDataDisk(disk os.Disk) VMOption {
+	return func(p *vmArgs) error {
+		p.dataDisks = append(p.dataDisks, disk)
+		return nil
+	}
+}
+
+// WithReadyFunc adds a check waiting for the host to be ready, run after the default one and before any other command.
+// Can be called multiple times, see [command.WaitFor] to build one from composable ready checks.
+func WithReadyFunc(readyFunc command.ReadyFunc) VMOption {
+	return func(p *vmArgs) error {
+		p.readyFuncs = append(p.readyFuncs, readyFunc)
+		return nil
+	}
+}
+
 // WithPulumiResourceOptions sets the pulumi.ResourceOptions for the VM
 func WithPulumiResourceOptions(opts ...pulumi.ResourceOption) VMOption {
 	return func(p *vmArgs) error {
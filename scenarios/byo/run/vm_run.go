@@ -0,0 +1,115 @@
+package byorun
+
+import (
+	"github.com/DataDog/test-infra-definitions/components/datadog/agent"
+	"github.com/DataDog/test-infra-definitions/components/datadog/agentparams"
+	"github.com/DataDog/test-infra-definitions/components/datadog/dockeragentparams"
+	"github.com/DataDog/test-infra-definitions/components/datadog/fakeintake"
+	"github.com/DataDog/test-infra-definitions/components/docker"
+	"github.com/DataDog/test-infra-definitions/resources/existing"
+	"github.com/DataDog/test-infra-definitions/scenarios/byo"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// VMRun installs the agent on an existing host, the fakeintake runs in the local Docker
+// and must be reachable from the host.
+func VMRun(ctx *pulumi.Context) error {
+	env, err := existing.NewEnvironment(ctx)
+	if err != nil {
+		return err
+	}
+
+	vm, err := byo.NewVM(env, "vm")
+	if err != nil {
+		return err
+	}
+	if err := vm.Export(ctx, nil); err != nil {
+		return err
+	}
+
+	if env.AgentDeploy() {
+		agentOptions := []agentparams.Option{}
+		if env.AgentUseFakeintake() {
+			fakeintake, err := fakeintake.NewLocalDockerFakeintake(&env, "fakeintake")
+			if err != nil {
+				return err
+			}
+			if err := fakeintake.Export(ctx, nil); err != nil {
+				return err
+			}
+			agentOptions = append(agentOptions, agentparams.WithFakeintake(fakeintake))
+		}
+		if env.AgentFlavor() != "" {
+			agentOptions = append(agentOptions, agentparams.WithFlavor(env.AgentFlavor()))
+		}
+		if env.AgentConfigPath() != "" {
+			configContent, err := env.CustomAgentConfig()
+			if err != nil {
+				return err
+			}
+			agentOptions = append(agentOptions, agentparams.WithAgentConfig(configContent))
+		}
+
+		agent, err := agent.NewHostAgent(&env, vm, agentOptions...)
+		if err != nil {
+			return err
+		}
+
+		return agent.Export(ctx, nil)
+	}
+
+	return nil
+}
+
+// VMRunWithDocker installs Docker and the containerized agent on an existing host
+func VMRunWithDocker(ctx *pulumi.Context) error {
+	env, err := existing.NewEnvironment(ctx)
+	if err != nil {
+		return err
+	}
+
+	vm, err := byo.NewVM(env, "vm")
+	if err != nil {
+		return err
+	}
+	if err := vm.Export(ctx, nil); err != nil {
+		return err
+	}
+
+	manager, err := docker.NewManager(&env, vm)
+	if err != nil {
+		return err
+	}
+	if err := manager.Export(ctx, nil); err != nil {
+		return err
+	}
+
+	if env.AgentDeploy() {
+		agentOptions := make([]dockeragentparams.Option, 0)
+		if env.AgentFullImagePath() != "" {
+			agentOptions = append(agentOptions, dockeragentparams.WithFullImagePath(env.AgentFullImagePath()))
+		} else if env.AgentVersion() != "" {
+			agentOptions = append(agentOptions, dockeragentparams.WithImageTag(env.AgentVersion()))
+		}
+
+		if env.AgentUseFakeintake() {
+			fakeintake, err := fakeintake.NewLocalDockerFakeintake(&env, "fakeintake")
+			if err != nil {
+				return err
+			}
+			if err := fakeintake.Export(ctx, nil); err != nil {
+				return err
+			}
+			agentOptions = append(agentOptions, dockeragentparams.WithFakeintake(fakeintake))
+		}
+
+		dockerAgent, err := agent.NewDockerAgent(&env, vm, manager, agentOptions...)
+		if err != nil {
+			return err
+		}
+		return dockerAgent.Export(ctx, nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package byo
+
+import (
+	"github.com/DataDog/test-infra-definitions/components"
+	"github.com/DataDog/test-infra-definitions/components/command"
+	"github.com/DataDog/test-infra-definitions/components/os"
+	"github.com/DataDog/test-infra-definitions/components/remote"
+	"github.com/DataDog/test-infra-definitions/resources/existing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// NewVM returns a Host component for the existing host described in the configuration, see [existing.Environment].
+// The host is not provisioned, only the components installed on it are created and deleted.
+func NewVM(e existing.Environment, name string, readyFuncs ...command.ReadyFunc) (*remote.Host, error) {
+	osDesc, err := os.ParseDescriptor(e.InfraOSDescriptor(), os.UbuntuDefault)
+	if err != nil {
+		return nil, err
+	}
+
+	return components.NewComponent(&e, e.Namer.ResourceName(name), func(c *remote.Host) error {
+		connectionOptions := []remote.ConnectionOption{
+			remote.WithPort(e.HostPort()),
+			remote.WithDialErrorLimit(e.InfraDialErrorLimit()),
+			remote.WithPerDialTimeoutSeconds(e.InfraPerDialTimeoutSeconds()),
+		}
+		if e.HostPrivateKeyPath() != "" {
+			connectionOptions = append(connectionOptions, remote.WithPrivateKeyPath(e.HostPrivateKeyPath()))
+		}
+		if e.HasHostPrivateKeyPassword() {
+			connectionOptions = append(connectionOptions, remote.WithPrivateKeyPasswordSecret(e.HostPrivateKeyPassword()))
+		}
+		password := e.HostPassword()
+		// Without key nor password, the SSH agent is used
+		if e.HasHostPassword() {
+			connectionOptions = append(connectionOptions, remote.WithPassword(password))
+		}
+
+		conn, err := remote.NewConnection(pulumi.String(e.HostAddress()), e.HostUser(), connectionOptions...)
+		if err != nil {
+			return err
+		}
+
+		return remote.InitHost(&e, conn.ToConnectionOutput(), osDesc, e.HostUser(), password, command.ExtendReadyFunc(command.WaitForSuccessfulConnection, readyFuncs...), c)
+	})
+}
@@ -21,7 +21,7 @@ func resolveUbuntuImage(_ gcp.Environment, osInfo os.Descriptor) (string, error)
 	case os.Ubuntu2204.Version:
 		return "ubuntu-2204-jammy-v20240904", nil
 	default:
-		return "", nil
+		return "", os.ValidateVersion(osInfo, []string{os.Ubuntu2204.Version})
 	}
 }
 func resolveRhelImage(_ gcp.Environment, osInfo os.Descriptor) (string, error) {
@@ -33,5 +33,5 @@ func resolveRhelImage(_ gcp.Environment, osInfo os.Descriptor) (string, error) {
 		return "rhel-9-v20250611", nil
 	}
 
-	return "", nil
+	return "", os.ValidateVersion(osInfo, []string{os.RedHat9.Version})
 }
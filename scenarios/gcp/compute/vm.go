@@ -31,7 +31,7 @@ func NewVM(e gcp.Environment, name string, option ...VMOption) (*remote.Host, er
 
 	return components.NewComponent(&e, name, func(h *remote.Host) error {
 		h.CloudProvider = pulumi.String(components.CloudProviderGCP).ToStringOutput()
-		vm, err := compute.NewLinuxInstance(e, e.Namer.ResourceName(name), imageInfo.name, params.instanceType, params.nestedVirt, pulumi.Parent(h))
+		vm, err := compute.NewLinuxInstance(e, e.Namer.ResourceName(name), imageInfo.name, params.instanceType, params.nestedVirt, os.DiskSizes(params.dataDisks), pulumi.Parent(h))
 		if err != nil {
 			return err
 		}
@@ -48,7 +48,12 @@ func NewVM(e gcp.Environment, name string, option ...VMOption) (*remote.Host, er
 			return err
 		}
 
-		return remote.InitHost(&e, conn.ToConnectionOutput(), *params.osInfo, "gce", pulumi.String("").ToStringOutput(), command.WaitForSuccessfulConnection, h)
+		if err = remote.InitHost(&e, conn.ToConnectionOutput(), *params.osInfo, "gce", pulumi.String("").ToStringOutput(), command.ExtendReadyFunc(command.WaitForSuccessfulConnection, params.readyFuncs...), h); err != nil {
+			return err
+		}
+
+		_, err = os.MountDisks(h.OS, params.dataDisks, pulumi.Parent(h))
+		return err
 	})
 }
 
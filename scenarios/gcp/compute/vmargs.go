@@ -3,6 +3,7 @@ package compute
 import (
 	"github.com/DataDog/test-infra-definitions/common"
 	"github.com/DataDog/test-infra-definitions/common/utils"
+	"github.com/DataDog/test-infra-definitions/components/command"
 	"github.com/DataDog/test-infra-definitions/components/os"
 )
 
@@ -11,6 +12,8 @@ type vmArgs struct {
 	instanceType string
 	imageName    string
 	nestedVirt   bool
+	readyFuncs   []command.ReadyFunc
+	dataDisks    []os.Disk
 }
 
 type VMOption = func(*vmArgs) error
@@ -55,3 +58,21 @@ func WithNestedVirt(enabled bool) VMOption {
 		return nil
 	}
 }
+
+// WithDataDisk attaches an additional persistent disk of disk.SizeGiB, it is formatted and mounted if disk.MountPoint is set.
+// Can be called multiple times to attach several disks.
+func WithDataDisk(disk os.Disk) VMOption {
+	return func(p *vmArgs) error {
+		p.dataDisks = append(p.dataDisks, disk)
+		return nil
+	}
+}
+
+// WithReadyFunc adds a check waiting for the host to be ready, run after the default one and before any other command.
+// Can be called multiple times, see [command.WaitFor] to build one from composable ready checks.
+func WithReadyFunc(readyFunc command.ReadyFunc) VMOption {
+	return func(p *vmArgs) error {
+		p.readyFuncs = append(p.readyFuncs, readyFunc)
+		return nil
+	}
+}
@@ -27,7 +27,7 @@ func Run(ctx *pulumi.Context) error {
 		return err
 	}
 
-	osDesc := os.DescriptorFromString("redhat:9", os.RedHat9)
+	osDesc := os.RedHat9
 	vm, err := compute.NewVM(gcpEnv, "openshift",
 		compute.WithOS(osDesc),
 		compute.WithInstancetype("n2-standard-8"),
@@ -12,7 +12,8 @@ import (
 )
 
 // NewVM creates an Ubuntu container instance on podman that emulates a VM and returns a Host component.
-func NewVM(e local.Environment, name string) (*remote.Host, error) {
+// readyFuncs are run after the connection is established and before any other command.
+func NewVM(e local.Environment, name string, readyFuncs ...command.ReadyFunc) (*remote.Host, error) {
 	// Create the EC2 instance
 	return components.NewComponent(&e, e.Namer.ResourceName(name), func(c *remote.Host) error {
 		vmArgs := &localpodman.VMArgs{
@@ -34,6 +35,6 @@ func NewVM(e local.Environment, name string) (*remote.Host, error) {
 		if err != nil {
 			return err
 		}
-		return remote.InitHost(&e, conn.ToConnectionOutput(), componentsos.Ubuntu2204, user, pulumi.String("").ToStringOutput(), command.WaitForSuccessfulConnection, c)
+		return remote.InitHost(&e, conn.ToConnectionOutput(), componentsos.Ubuntu2204, user, pulumi.String("").ToStringOutput(), command.ExtendReadyFunc(command.WaitForSuccessfulConnection, readyFuncs...), c)
 	})
 }
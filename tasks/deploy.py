@@ -1,9 +1,11 @@
+import json
 import os
+import shlex
 from typing import Any, Callable, Dict, List, Optional
 
 import boto3
 from invoke.context import Context
-from invoke.exceptions import Exit
+from invoke.exceptions import Exit, UnexpectedExit
 from invoke.tasks import task
 from pydantic import ValidationError
 
@@ -185,10 +187,38 @@ def _deploy(
     pty = True
     if tool.is_windows():
         pty = False
-    ctx.run(cmd, pty=pty)
+    try:
+        ctx.run(cmd, pty=pty)
+    except UnexpectedExit:
+        _collect_diagnostics(ctx, stack_name, global_flags)
+        raise
     return stack_name
 
 
+# diagnostics collectors fetch the bundles of the hosts when they are deleted, see the components/diagnostics package
+def _collect_diagnostics(ctx: Context, stack_name: str, global_flags: str):
+    result = ctx.run(f"pulumi {global_flags} stack export -s {stack_name}", hide=True, warn=True)
+    if not result or not result.ok:
+        return
+
+    resources = json.loads(result.stdout).get("deployment", {}).get("resources") or []
+    targets = [
+        f"--target {shlex.quote(resource['urn'])}"
+        for resource in resources
+        if resource.get("type") == "command:local:Command"
+        and resource["urn"].split("::")[-1].startswith("diagnostics-")
+    ]
+    if not targets:
+        return
+
+    print("pulumi up failed, collecting diagnostics")
+    ctx.run(
+        f"pulumi {global_flags} destroy --yes -s {stack_name} {' '.join(targets)}",
+        env={"DIAGNOSTICS_COLLECT": "true"},
+        warn=True,
+    )
+
+
 def _get_api_key(cfg: Optional[Config]) -> str:
     return _get_key("API KEY", cfg, lambda c: c.get_agent().apiKey, "E2E_API_KEY", 32)
 
@@ -14,7 +14,7 @@ from invoke.tasks import task
 
 from . import doc
 from .config import Config, get_full_profile_path, get_local_config
-from .tool import ask, ask_yesno, debug, error, get_aws_cmd, info, is_linux, is_windows, warn
+from .tool import _get_root_path, ask, ask_yesno, debug, error, get_aws_cmd, info, is_linux, is_windows, warn
 
 available_aws_accounts = ["agent-sandbox", "sandbox", "agent-qa", "tse-playground"]
 supported_key_types = ["rsa", "ed25519"]
@@ -275,6 +275,22 @@ def aws_sso(ctx: Context, config_path: Optional[str] = None):
     _setup_aws_sso_config(config)
 
 
+@task
+def winrm_client(ctx: Context):
+    """
+    Build and install the WinRM client used to run commands on Windows hosts when `ddinfra:windowsTransport` is `winrm-https`
+    """
+    if not shutil.which("go"):
+        error("Go not found, please install it: https://go.dev/doc/install")
+        raise Exit(code=1)
+
+    info("🤖 Install the WinRM client")
+    with ctx.cd(_get_root_path()):
+        ctx.run("go install ./components/command/winrm/cmd/winrm")
+    if not shutil.which("winrm"):
+        warn("winrm is not in the PATH, please add $(go env GOPATH)/bin to PATH or set ddinfra:winrmClientPath")
+
+
 def setup_azure_config(config: Config):
     if config.configParams is None:
         config.configParams = Config.Params(aws=None, agent=None, pulumi=None, azure=None, gcp=None)